@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mairinkdev/Hardshell/internal/mail"
+	"github.com/spf13/cobra"
+)
+
+// mailCmd representa o comando mail
+var mailCmd = &cobra.Command{
+	Use:   "mail",
+	Short: "Analisa a configuração dos servidores de email (Postfix e Exim)",
+	Long: `Quando o Postfix ou o Exim é iniciado no boot (systemd ou SysV/OpenRC), verifica
+relay aberto, interfaces de escuta, TLS e o banner SMTP.
+No Postfix, lê o /etc/postfix/main.cf: smtpd_relay_restrictions, mynetworks e
+mynetworks_style, inet_interfaces, smtpd_tls_security_level e os protocolos TLS,
+smtpd_tls_auth_only, smtpd_banner e disable_vrfy_command.
+No exim4 do Debian, lê o update-exim4.conf.conf (dc_relay_nets, dc_relay_domains,
+dc_local_interfaces) e as macros locais (MAIN_TLS_ENABLE, MAIN_SMTP_BANNER); nas demais
+distribuições, a seção principal da configuração do Exim (relay_from_hosts,
+relay_to_domains, local_interfaces, tls_advertise_hosts, openssl_options e smtp_banner).
+Com --apply, os parâmetros são corrigidos com backup .bak; no sistema em execução a
+configuração é validada e recarregada, e restaurada se a validação falhar. Interfaces de
+escuta e redes de relay amplas dependem do papel do servidor e não são alteradas.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println("Analisando configuração dos servidores de email...")
+
+		// Cria o analisador dos servidores de email
+		analyzer := mail.NewAnalyzer(mountPoint)
+
+		// Executa a análise
+		issues, err := analyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao analisar configuração dos servidores de email: %w", err)
+		}
+
+		// Exibe os resultados
+		fmt.Printf("Encontradas %d questões na configuração dos servidores de email\n", len(issues))
+		for _, issue := range issues {
+			fmt.Printf("[%s] %s\n", issue.Severity, issue.Description)
+		}
+
+		// Se --apply foi especificado, gerar e aplicar correções
+		if applyFixes {
+			fmt.Println("Aplicando correções...")
+			if err := analyzer.Fix(); err != nil {
+				return fmt.Errorf("erro ao aplicar correções: %w", err)
+			}
+			fmt.Println("Correções aplicadas com sucesso!")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mailCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mairinkdev/Hardshell/internal/network"
+	"github.com/spf13/cobra"
+)
+
+// networkCmd representa o comando network
+var networkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Analisa as portas em escuta",
+	Long: `Lista os sockets TCP e UDP em escuta a partir de /proc/net/tcp, tcp6, udp e udp6
+e identifica os processos donos de cada socket por meio de /proc/<pid>/fd.
+Sockets em endereços de loopback são ignorados. Protocolos em texto claro conhecidos
+(telnet, FTP, TFTP, rsh, rlogin, POP3, IMAP, SNMP, ...) recebem severidade maior;
+as demais portas expostas são informativas.
+Portas e processos esperados podem ser permitidos na seção network.allow do arquivo
+de regras. Sem privilégios de root, apenas os processos do próprio usuário são identificados.
+A análise depende do kernel em execução e é ignorada com --mount.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println("Analisando portas em escuta...")
+
+		// Cria o analisador de rede
+		analyzer := network.NewAnalyzer(mountPoint)
+		analyzer.SetRules(ruleSet.Network)
+
+		// Executa a análise
+		issues, err := analyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao analisar portas em escuta: %w", err)
+		}
+
+		// Exibe os resultados
+		fmt.Printf("Encontradas %d portas expostas\n", len(issues))
+		for _, issue := range issues {
+			fmt.Printf("[%s] %s: %s\n", issue.Severity, issue.Key, issue.Description)
+		}
+
+		// Se --apply foi especificado, gerar e aplicar correções
+		if applyFixes {
+			fmt.Println("Aplicando correções...")
+			if err := analyzer.Fix(); err != nil {
+				return fmt.Errorf("erro ao aplicar correções: %w", err)
+			}
+			fmt.Println("Correções aplicadas com sucesso!")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(networkCmd)
+}
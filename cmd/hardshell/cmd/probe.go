@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mairinkdev/Hardshell/internal/ssh"
+	"github.com/spf13/cobra"
+)
+
+// probeTimeout é o tempo máximo da conexão e do handshake
+var probeTimeout time.Duration
+
+// probeCmd representa o comando ssh probe
+var probeCmd = &cobra.Command{
+	Use:   "probe [host:porta]",
+	Short: "Verifica os algoritmos oferecidos por um servidor SSH em execução",
+	Long: `Conecta em um servidor SSH e realiza apenas a troca de identificação e de KEXINIT,
+sem autenticar, para mostrar a versão e os algoritmos de troca de chaves, chave de host,
+cifras e MACs realmente oferecidos. Sem argumentos, verifica o sshd local.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		address := ssh.DefaultProbeAddress(mountPoint)
+		if len(args) == 1 {
+			address = args[0]
+			if _, _, err := net.SplitHostPort(address); err != nil {
+				address = net.JoinHostPort(strings.Trim(address, "[]"), "22")
+			}
+		}
+
+		fmt.Printf("Verificando servidor SSH em %s...\n", address)
+
+		result, err := ssh.Probe(address, probeTimeout)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Identificação: %s\n", result.Banner)
+		fmt.Printf("Software: %s\n", result.Software)
+		fmt.Printf("Troca de chaves: %s\n", strings.Join(result.KexAlgorithms, ","))
+		fmt.Printf("Chaves de host: %s\n", strings.Join(result.HostKeyAlgorithms, ","))
+		fmt.Printf("Cifras: %s\n", strings.Join(result.Ciphers, ","))
+		fmt.Printf("MACs: %s\n", strings.Join(result.MACs, ","))
+		fmt.Printf("Compressão: %s\n", strings.Join(result.Compression, ","))
+
+		issues := result.Issues()
+		fmt.Printf("Encontradas %d questões nos algoritmos oferecidos\n", len(issues))
+		for _, issue := range issues {
+			fmt.Printf("[%s] %s\n", issue.Severity, issue.Description)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	probeCmd.Flags().DurationVar(&probeTimeout, "timeout", 5*time.Second, "tempo máximo para a conexão e o handshake")
+	sshCmd.AddCommand(probeCmd)
+}
@@ -1,14 +1,22 @@
 package cmd
 
 import (
+	"github.com/mairinkdev/Hardshell/internal/rules"
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfgFile     string
-	applyFixes  bool
-	mountPoint  string
-	outputFormat string
+	cfgFile        string
+	applyFixes     bool
+	forceFixes     bool
+	sshDropIn      bool
+	sshUserConfigs bool
+	mountPoint     string
+	outputFormat   string
+	servicePolicy  string
+
+	// ruleSet são as regras carregadas do arquivo de regras (--config ou $HOME/.hardshell.yaml)
+	ruleSet *rules.RuleSet
 )
 
 // rootCmd representa o comando base quando chamado sem subcomandos
@@ -25,6 +33,11 @@ Hardshell realiza:
   - Identificação de serviços perigosos ativos
   - Geração de relatórios detalhados
   - Sugestão de correções através de scripts`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		ruleSet, err = rules.Load(cfgFile)
+		return err
+	},
 }
 
 // Execute adiciona todos os comandos filhos ao comando root e configura flags apropriadamente.
@@ -35,6 +48,10 @@ func Execute() error {
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "arquivo de configuração (padrão: $HOME/.hardshell.yaml)")
 	rootCmd.PersistentFlags().BoolVar(&applyFixes, "apply", false, "aplicar correções automaticamente (com backup)")
+	rootCmd.PersistentFlags().BoolVar(&forceFixes, "force", false, "aplicar correções mesmo quando as verificações de segurança as recusarem")
+	rootCmd.PersistentFlags().BoolVar(&sshDropIn, "ssh-dropin", false, "gravar correções SSH em /etc/ssh/sshd_config.d/00-hardshell.conf")
+	rootCmd.PersistentFlags().BoolVar(&sshUserConfigs, "ssh-user-configs", false, "verificar também o ~/.ssh/config de cada conta")
 	rootCmd.PersistentFlags().StringVar(&mountPoint, "mount", "", "ponto de montagem para análise (ex: /mnt)")
 	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", "text", "formato de saída (text, json, html)")
+	rootCmd.PersistentFlags().StringVar(&servicePolicy, "service-policy", "disable", "correção das units do systemd (disable, mask)")
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mairinkdev/Hardshell/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// sandboxCmd representa o comando services sandbox
+var sandboxCmd = &cobra.Command{
+	Use:   "sandbox",
+	Short: "Avalia o isolamento dos serviços do systemd",
+	Long: `Calcula, como o systemd-analyze security, a exposição de cada serviço iniciado no boot,
+de 0.0 (isolado) a 10.0 (sem isolamento). Os arquivos das units e seus drop-ins são lidos
+do sistema de arquivos, no sistema em execução ou com --mount, e as diretivas User=,
+NoNewPrivileges=, ProtectSystem=, PrivateTmp=, CapabilityBoundingSet=,
+RestrictAddressFamilies= e SystemCallFilter= são pontuadas.
+Para cada serviço é sugerido um drop-in de endurecimento em
+/etc/systemd/system/<unit>.d/50-hardshell-sandbox.conf. Com --apply, os drop-ins são
+apenas exibidos: restrições de isolamento podem impedir o serviço de iniciar e precisam
+ser testadas antes de gravadas.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println("Avaliando o isolamento dos serviços...")
+
+		// Cria o analisador de isolamento
+		analyzer := services.NewSandboxAnalyzer(mountPoint)
+
+		// Executa a análise
+		issues, err := analyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao avaliar o isolamento dos serviços: %w", err)
+		}
+
+		// Exibe os resultados
+		fmt.Printf("Avaliados %d serviços\n", len(issues))
+		for _, issue := range issues {
+			fmt.Printf("[%s] %s\n", issue.Severity, issue.Description)
+		}
+
+		// Se --apply foi especificado, exibir os drop-ins sugeridos
+		if applyFixes {
+			fmt.Println("Gerando drop-ins de endurecimento...")
+			if err := analyzer.Fix(); err != nil {
+				return fmt.Errorf("erro ao gerar drop-ins: %w", err)
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	servicesCmd.AddCommand(sandboxCmd)
+}
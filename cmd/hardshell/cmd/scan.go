@@ -3,8 +3,11 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/mairinkdev/Hardshell/internal/mail"
+	"github.com/mairinkdev/Hardshell/internal/network"
 	"github.com/mairinkdev/Hardshell/internal/report"
 	"github.com/mairinkdev/Hardshell/internal/services"
+	"github.com/mairinkdev/Hardshell/internal/snmp"
 	"github.com/mairinkdev/Hardshell/internal/ssh"
 	"github.com/mairinkdev/Hardshell/internal/sysctl"
 	"github.com/spf13/cobra"
@@ -15,15 +18,32 @@ var scanCmd = &cobra.Command{
 	Use:   "scan",
 	Short: "Realiza um scan completo do sistema",
 	Long: `Executa uma verificação completa de segurança no sistema,
-analisando configurações SSH, sysctl e serviços ativos.
+analisando configurações SSH, sysctl, serviços ativos, o isolamento dos serviços
+do systemd, a configuração do snmpd e dos servidores de email e portas em escuta.
 Gera um relatório detalhado com as descobertas e recomendações.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println("Iniciando scan completo do sistema...")
 
 		// Cria os analisadores
 		sshAnalyzer := ssh.NewAnalyzer(mountPoint)
+		sshAnalyzer.SetForce(forceFixes)
+		sshAnalyzer.SetDropIn(sshDropIn)
+		sshAnalyzer.SetRules(ruleSet.SSH)
+		keysAnalyzer := ssh.NewAuthorizedKeysAnalyzer(mountPoint)
+		clientAnalyzer := ssh.NewClientAnalyzer(mountPoint)
+		clientAnalyzer.SetUserConfigs(sshUserConfigs)
 		sysctlAnalyzer := sysctl.NewAnalyzer(mountPoint)
+		sysctlAnalyzer.SetRules(ruleSet.Sysctl)
 		servicesAnalyzer := services.NewAnalyzer(mountPoint)
+		servicesAnalyzer.SetRules(ruleSet.Services)
+		if err := servicesAnalyzer.SetPolicy(servicePolicy); err != nil {
+			return err
+		}
+		sandboxAnalyzer := services.NewSandboxAnalyzer(mountPoint)
+		snmpAnalyzer := snmp.NewAnalyzer(mountPoint)
+		mailAnalyzer := mail.NewAnalyzer(mountPoint)
+		networkAnalyzer := network.NewAnalyzer(mountPoint)
+		networkAnalyzer.SetRules(ruleSet.Network)
 
 		// Executa as análises
 		sshIssues, err := sshAnalyzer.Analyze()
@@ -31,6 +51,16 @@ Gera um relatório detalhado com as descobertas e recomendações.`,
 			return fmt.Errorf("erro ao analisar configurações SSH: %w", err)
 		}
 
+		keysIssues, err := keysAnalyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao analisar chaves autorizadas: %w", err)
+		}
+
+		clientIssues, err := clientAnalyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao analisar configuração do cliente SSH: %w", err)
+		}
+
 		sysctlIssues, err := sysctlAnalyzer.Analyze()
 		if err != nil {
 			return fmt.Errorf("erro ao analisar configurações sysctl: %w", err)
@@ -41,13 +71,43 @@ Gera um relatório detalhado com as descobertas e recomendações.`,
 			return fmt.Errorf("erro ao analisar serviços: %w", err)
 		}
 
+		// O isolamento só é avaliado em sistemas com systemd
+		var sandboxIssues []report.Issue
+		if services.HasSystemd(mountPoint) {
+			sandboxIssues, err = sandboxAnalyzer.Analyze()
+			if err != nil {
+				return fmt.Errorf("erro ao avaliar o isolamento dos serviços: %w", err)
+			}
+		}
+
+		snmpIssues, err := snmpAnalyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao analisar configuração do snmpd: %w", err)
+		}
+
+		mailIssues, err := mailAnalyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao analisar configuração dos servidores de email: %w", err)
+		}
+
+		networkIssues, err := networkAnalyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao analisar portas em escuta: %w", err)
+		}
+
 		// Cria e exibe o relatório
 		reportGenerator := report.NewGenerator(outputFormat)
 
 		allIssues := []report.Issue{}
 		allIssues = append(allIssues, sshIssues...)
+		allIssues = append(allIssues, keysIssues...)
+		allIssues = append(allIssues, clientIssues...)
 		allIssues = append(allIssues, sysctlIssues...)
 		allIssues = append(allIssues, servicesIssues...)
+		allIssues = append(allIssues, sandboxIssues...)
+		allIssues = append(allIssues, snmpIssues...)
+		allIssues = append(allIssues, mailIssues...)
+		allIssues = append(allIssues, networkIssues...)
 
 		reportData, err := reportGenerator.Generate(allIssues)
 		if err != nil {
@@ -57,7 +117,7 @@ Gera um relatório detalhado com as descobertas e recomendações.`,
 		fmt.Println(reportData)
 
 		// Resumo das descobertas
-		var critical, warning, info int
+		var critical, warning, info, skipped int
 		for _, issue := range allIssues {
 			switch issue.Severity {
 			case "CRITICAL":
@@ -66,6 +126,8 @@ Gera um relatório detalhado com as descobertas e recomendações.`,
 				warning++
 			case "INFO":
 				info++
+			case "SKIP":
+				skipped++
 			}
 		}
 
@@ -73,7 +135,8 @@ Gera um relatório detalhado com as descobertas e recomendações.`,
 		fmt.Printf("  Problemas críticos: %d\n", critical)
 		fmt.Printf("  Avisos: %d\n", warning)
 		fmt.Printf("  Informações: %d\n", info)
-		fmt.Printf("  Total: %d\n", len(allIssues))
+		fmt.Printf("  Verificações ignoradas: %d\n", skipped)
+		fmt.Printf("  Total: %d\n", len(allIssues)-skipped)
 
 		// Se --apply foi especificado, gerar e aplicar correções
 		if applyFixes {
@@ -83,6 +146,14 @@ Gera um relatório detalhado com as descobertas e recomendações.`,
 				return fmt.Errorf("erro ao aplicar correções SSH: %w", err)
 			}
 
+			if err := keysAnalyzer.Fix(); err != nil {
+				return fmt.Errorf("erro ao aplicar correções de chaves autorizadas: %w", err)
+			}
+
+			if err := clientAnalyzer.Fix(); err != nil {
+				return fmt.Errorf("erro ao aplicar correções do cliente SSH: %w", err)
+			}
+
 			if err := sysctlAnalyzer.Fix(); err != nil {
 				return fmt.Errorf("erro ao aplicar correções sysctl: %w", err)
 			}
@@ -91,6 +162,28 @@ Gera um relatório detalhado com as descobertas e recomendações.`,
 				return fmt.Errorf("erro ao aplicar correções de serviços: %w", err)
 			}
 
+			if len(sandboxIssues) > 0 {
+				if err := sandboxAnalyzer.Fix(); err != nil {
+					return fmt.Errorf("erro ao gerar drop-ins de isolamento: %w", err)
+				}
+			}
+
+			if len(snmpIssues) > 0 {
+				if err := snmpAnalyzer.Fix(); err != nil {
+					return fmt.Errorf("erro ao aplicar correções do snmpd: %w", err)
+				}
+			}
+
+			if len(mailIssues) > 0 {
+				if err := mailAnalyzer.Fix(); err != nil {
+					return fmt.Errorf("erro ao aplicar correções dos servidores de email: %w", err)
+				}
+			}
+
+			if err := networkAnalyzer.Fix(); err != nil {
+				return fmt.Errorf("erro ao aplicar correções de rede: %w", err)
+			}
+
 			fmt.Println("Todas as correções foram aplicadas com sucesso!")
 		}
 
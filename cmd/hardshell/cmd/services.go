@@ -13,12 +13,32 @@ var servicesCmd = &cobra.Command{
 	Short: "Analisa os serviços ativos",
 	Long: `Verifica os serviços ativos no sistema para identificar serviços potencialmente perigosos
 ou mal configurados. Inclui verificação de serviços como telnet, rsh, rlogin, e outros
-serviços inseguros.`,
+serviços inseguros.
+Com --mount, as units que seriam iniciadas no boot são resolvidas a partir dos
+arquivos do systemd (targets, aliases, presets, units mascaradas e sockets).
+Também verifica os serviços habilitados no inetd e no xinetd e os sockets em escuta.
+No sistema em execução, as units e seus estados (carga, ativação e arquivo) são
+consultados no gerenciador do systemd pelo D-Bus; HARDSHELL_SYSTEMD_BUS aponta para
+outro endereço D-Bus.
+Scripts SysV (links S* em rcN.d) e OpenRC (/etc/runlevels) são lidos do sistema de
+arquivos, sem executar os scripts; o estado de execução vem de /proc.
+As regras podem ser alteradas na seção services do arquivo de regras, com nomes
+exatos, globs ou expressões regulares (regex:), exclusões, tipos de unit e pacotes de origem.
+Com --apply, as units em execução são paradas e desabilitadas pelo D-Bus. Apenas regras
+CRITICAL e WARNING, ou com action disable ou mask, são corrigidas; regras INFO e com
+action report (snmpd, servidores de email e servidor X) são apenas reportadas.
+Com --mount e --apply, as correções são feitas nos arquivos do sistema montado: as units
+são desabilitadas (links removidos e scripts SysV desabilitados) ou, com
+--service-policy mask, mascaradas; entradas do inetd e do xinetd são desabilitadas.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println("Analisando serviços ativos...")
 
 		// Cria o analisador de serviços
 		analyzer := services.NewAnalyzer(mountPoint)
+		analyzer.SetRules(ruleSet.Services)
+		if err := analyzer.SetPolicy(servicePolicy); err != nil {
+			return err
+		}
 
 		// Executa a análise
 		issues, err := analyzer.Analyze()
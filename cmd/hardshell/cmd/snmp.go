@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mairinkdev/Hardshell/internal/snmp"
+	"github.com/spf13/cobra"
+)
+
+// snmpCmd representa o comando snmp
+var snmpCmd = &cobra.Command{
+	Use:   "snmp",
+	Short: "Analisa a configuração do snmpd",
+	Long: `Quando o snmpd é iniciado no boot (systemd ou SysV/OpenRC), lê o /etc/snmp/snmpd.conf
+e os arquivos incluídos por includeFile e includeDir e verifica:
+communities padrão (public, private) ou fracas, acesso de escrita via SNMP v1/v2c
+(rwcommunity, authcommunity write ou com2sec, group e access do VACM), communities
+sem restrição de origem e a ausência de usuários SNMPv3 com authPriv.
+Com --apply, as linhas das communities padrão e das communities com escrita são
+comentadas, com backup .bak; redes de origem e usuários SNMPv3 precisam ser definidos
+pelo administrador.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Println("Analisando configuração do snmpd...")
+
+		// Cria o analisador do snmpd
+		analyzer := snmp.NewAnalyzer(mountPoint)
+
+		// Executa a análise
+		issues, err := analyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao analisar configuração do snmpd: %w", err)
+		}
+
+		// Exibe os resultados
+		fmt.Printf("Encontradas %d questões na configuração do snmpd\n", len(issues))
+		for _, issue := range issues {
+			fmt.Printf("[%s] %s\n", issue.Severity, issue.Description)
+		}
+
+		// Se --apply foi especificado, gerar e aplicar correções
+		if applyFixes {
+			fmt.Println("Aplicando correções...")
+			if err := analyzer.Fix(); err != nil {
+				return fmt.Errorf("erro ao aplicar correções: %w", err)
+			}
+			fmt.Println("Correções aplicadas com sucesso!")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snmpCmd)
+}
@@ -12,12 +12,20 @@ var sshCmd = &cobra.Command{
 	Use:   "ssh",
 	Short: "Analisa a configuração do SSH",
 	Long: `Verifica a configuração do sshd_config em busca de configurações inseguras
-como PermitRootLogin, Protocol, PasswordAuthentication e outras opções críticas.`,
+como PermitRootLogin, Protocol, PasswordAuthentication e outras opções críticas.
+Também verifica a configuração do cliente SSH (ssh_config), como StrictHostKeyChecking,
+ForwardAgent e HashKnownHosts.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println("Analisando configurações SSH...")
 
-		// Cria o analisador SSH
+		// Cria os analisadores SSH
 		analyzer := ssh.NewAnalyzer(mountPoint)
+		analyzer.SetForce(forceFixes)
+		analyzer.SetDropIn(sshDropIn)
+		analyzer.SetRules(ruleSet.SSH)
+		keysAnalyzer := ssh.NewAuthorizedKeysAnalyzer(mountPoint)
+		clientAnalyzer := ssh.NewClientAnalyzer(mountPoint)
+		clientAnalyzer.SetUserConfigs(sshUserConfigs)
 
 		// Executa a análise
 		issues, err := analyzer.Analyze()
@@ -25,6 +33,18 @@ como PermitRootLogin, Protocol, PasswordAuthentication e outras opções crític
 			return fmt.Errorf("erro ao analisar configurações SSH: %w", err)
 		}
 
+		keysIssues, err := keysAnalyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao analisar chaves autorizadas: %w", err)
+		}
+		issues = append(issues, keysIssues...)
+
+		clientIssues, err := clientAnalyzer.Analyze()
+		if err != nil {
+			return fmt.Errorf("erro ao analisar configuração do cliente SSH: %w", err)
+		}
+		issues = append(issues, clientIssues...)
+
 		// Exibe os resultados
 		fmt.Printf("Encontradas %d questões nas configurações SSH\n", len(issues))
 		for _, issue := range issues {
@@ -37,6 +57,12 @@ como PermitRootLogin, Protocol, PasswordAuthentication e outras opções crític
 			if err := analyzer.Fix(); err != nil {
 				return fmt.Errorf("erro ao aplicar correções: %w", err)
 			}
+			if err := keysAnalyzer.Fix(); err != nil {
+				return fmt.Errorf("erro ao aplicar correções: %w", err)
+			}
+			if err := clientAnalyzer.Fix(); err != nil {
+				return fmt.Errorf("erro ao aplicar correções: %w", err)
+			}
 			fmt.Println("Correções aplicadas com sucesso!")
 		}
 
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/mairinkdev/Hardshell/internal/report"
 	"github.com/mairinkdev/Hardshell/internal/sysctl"
 	"github.com/spf13/cobra"
 )
@@ -13,16 +14,33 @@ var sysctlCmd = &cobra.Command{
 	Short: "Analisa as configurações sysctl",
 	Long: `Verifica as configurações do sysctl.conf e arquivos em sysctl.d para
 garantir que as configurações relacionadas à segurança estão adequadas.
+No sistema em execução, também compara os valores atuais em /proc/sys com os
+persistidos e aponta divergências entre eles.
+Os arquivos são lidos na mesma ordem do systemd-sysctl e cada problema informa
+o arquivo e a linha que definem o valor.
 Analisa parâmetros como:
   - net.ipv4.tcp_syncookies
   - net.ipv4.conf.all.accept_redirects
+  - net.ipv4.conf.*.accept_redirects (default e cada interface)
   - kernel.randomize_va_space
-  - fs.protected_hardlinks/symlinks`,
+  - fs.protected_hardlinks/symlinks/fifos/regular
+  - net.ipv6.conf.*.accept_ra/accept_redirects/accept_source_route
+  - kernel.yama.ptrace_scope, kernel.unprivileged_bpf_disabled, net.core.bpf_jit_harden
+Parâmetros que não existem no kernel em execução são reportados como SKIP.
+Com --apply, os valores corrigidos são gravados em /etc/sysctl.d/99-hardshell.conf
+e, no sistema em execução, aplicados em /proc/sys.
+Em contêineres, os parâmetros globais do kernel são reportados como SKIP, pois
+cabem ao host; apenas os dos namespaces de rede e IPC são verificados.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		fmt.Println("Analisando configurações sysctl...")
 
 		// Cria o analisador sysctl
 		analyzer := sysctl.NewAnalyzer(mountPoint)
+		analyzer.SetRules(ruleSet.Sysctl)
+
+		if analyzer.InContainer() {
+			fmt.Println("Contêiner detectado: apenas parâmetros dos namespaces de rede e IPC serão verificados.")
+		}
 
 		// Executa a análise
 		issues, err := analyzer.Analyze()
@@ -30,8 +48,17 @@ Analisa parâmetros como:
 			return fmt.Errorf("erro ao analisar configurações sysctl: %w", err)
 		}
 
-		// Exibe os resultados
-		fmt.Printf("Encontradas %d questões nas configurações sysctl\n", len(issues))
+		// Exibe os resultados; parâmetros ignorados (SKIP) não são questões
+		skipped := 0
+		for _, issue := range issues {
+			if issue.Severity == report.SeveritySkip {
+				skipped++
+			}
+		}
+		fmt.Printf("Encontradas %d questões nas configurações sysctl\n", len(issues)-skipped)
+		if skipped > 0 {
+			fmt.Printf("Parâmetros ignorados: %d\n", skipped)
+		}
 		for _, issue := range issues {
 			fmt.Printf("[%s] %s\n", issue.Severity, issue.Description)
 		}
@@ -2,7 +2,11 @@ module github.com/mairinkdev/Hardshell
 
 go 1.19
 
-require github.com/spf13/cobra v1.9.1
+require (
+	github.com/godbus/dbus/v5 v5.1.0
+	github.com/spf13/cobra v1.9.1
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
@@ -0,0 +1,112 @@
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Suffix é o sufixo dos arquivos candidatos, que não casa com os padrões *.conf dos
+// diretórios de drop-ins (sshd_config.d, sysctl.d)
+const Suffix = ".hardshell-new"
+
+// Header é o cabeçalho gravado nos arquivos gerenciados pelo Hardshell
+const Header = "# Arquivo gerenciado pelo Hardshell. Alterações manuais podem ser sobrescritas.\n"
+
+// Change representa a alteração de um arquivo de configuração. O novo conteúdo é gravado
+// primeiro em um arquivo candidato, que só substitui o original em Install.
+type Change struct {
+	Path    string
+	Old     []byte
+	New     []byte
+	Mode    os.FileMode
+	Existed bool
+}
+
+// Candidate retorna o caminho do arquivo candidato
+func (c *Change) Candidate() string {
+	return c.Path + Suffix
+}
+
+// Load lê o conteúdo atual de um arquivo que será alterado
+func Load(path string) (*Change, error) {
+	change := &Change{Path: path, Mode: 0644}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return change, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao verificar %s: %w", path, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+
+	change.Old = data
+	change.Mode = info.Mode().Perm()
+	change.Existed = true
+	return change, nil
+}
+
+// Write grava os arquivos candidatos ao lado dos originais. Em caso de falha, nenhum
+// candidato é mantido.
+func Write(changes []*Change) error {
+	for _, change := range changes {
+		if err := os.MkdirAll(filepath.Dir(change.Path), 0755); err != nil {
+			Remove(changes)
+			return fmt.Errorf("erro ao criar diretório %s: %w", filepath.Dir(change.Path), err)
+		}
+		if err := os.WriteFile(change.Candidate(), change.New, change.Mode); err != nil {
+			Remove(changes)
+			return fmt.Errorf("erro ao gravar configuração candidata %s: %w", change.Candidate(), err)
+		}
+	}
+	return nil
+}
+
+// Install substitui os arquivos pelos candidatos já gravados, criando antes um backup .bak
+// de cada arquivo existente quando backup é verdadeiro. Em caso de falha, os candidatos
+// restantes são removidos e os arquivos já substituídos voltam ao conteúdo anterior.
+func Install(changes []*Change, backup bool) error {
+	for i, change := range changes {
+		if backup && change.Existed {
+			backupPath := change.Path + ".bak"
+			if err := os.WriteFile(backupPath, change.Old, change.Mode); err != nil {
+				Remove(changes)
+				Restore(changes[:i])
+				return fmt.Errorf("erro ao criar backup do arquivo de configuração: %w", err)
+			}
+			fmt.Printf("Backup criado em %s\n", backupPath)
+		}
+
+		if err := os.Rename(change.Candidate(), change.Path); err != nil {
+			Remove(changes)
+			Restore(changes[:i])
+			return fmt.Errorf("erro ao substituir %s: %w", change.Path, err)
+		}
+	}
+	return nil
+}
+
+// Restore devolve os arquivos ao conteúdo anterior, removendo os que não existiam
+func Restore(changes []*Change) {
+	for _, change := range changes {
+		if !change.Existed {
+			os.Remove(change.Path)
+			continue
+		}
+		if err := os.WriteFile(change.Path, change.Old, change.Mode); err != nil {
+			fmt.Printf("Erro ao restaurar %s: %v\n", change.Path, err)
+		}
+	}
+}
+
+// Remove remove os arquivos candidatos que não serão usados
+func Remove(changes []*Change) {
+	for _, change := range changes {
+		os.Remove(change.Candidate())
+	}
+}
@@ -0,0 +1,101 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// loadChanges prepara um arquivo existente e um novo, ambos com conteúdo novo
+func loadChanges(t *testing.T) (string, []*Change) {
+	t.Helper()
+
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "main.conf")
+	if err := os.WriteFile(existing, []byte("antigo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []*Change
+	for _, path := range []string{existing, filepath.Join(dir, "conf.d", "00-hardshell.conf")} {
+		change, err := Load(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		change.New = []byte(Header + "novo\n")
+		changes = append(changes, change)
+	}
+	return dir, changes
+}
+
+func TestLoad(t *testing.T) {
+	_, changes := loadChanges(t)
+
+	tests := []struct {
+		change  *Change
+		existed bool
+		old     string
+		mode    os.FileMode
+	}{
+		{changes[0], true, "antigo\n", 0600},
+		{changes[1], false, "", 0644},
+	}
+
+	for _, tt := range tests {
+		if tt.change.Existed != tt.existed || string(tt.change.Old) != tt.old || tt.change.Mode != tt.mode {
+			t.Errorf("Load(%s) = %+v, esperado Existed=%v Old=%q Mode=%v", tt.change.Path, tt.change, tt.existed, tt.old, tt.mode)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	dir, changes := loadChanges(t)
+
+	if err := Write(changes); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := Install(changes, true); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+
+	for _, change := range changes {
+		if data, _ := os.ReadFile(change.Path); string(data) != string(change.New) {
+			t.Errorf("%s = %q, esperado %q", change.Path, data, change.New)
+		}
+		if _, err := os.Stat(change.Candidate()); err == nil {
+			t.Errorf("candidato restante: %s", change.Candidate())
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "main.conf.bak")); string(data) != "antigo\n" {
+		t.Errorf("backup = %q, esperado o conteúdo anterior", data)
+	}
+	if info, _ := os.Stat(changes[0].Path); info.Mode().Perm() != 0600 {
+		t.Errorf("permissões = %v, esperado 0600", info.Mode().Perm())
+	}
+}
+
+func TestInstallRollback(t *testing.T) {
+	_, changes := loadChanges(t)
+
+	// A ordem é invertida para que o arquivo novo seja instalado antes da falha
+	changes = []*Change{changes[1], changes[0]}
+	if err := Write(changes); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	os.Remove(changes[1].Candidate())
+
+	if err := Install(changes, false); err == nil {
+		t.Fatal("Install deveria falhar sem o candidato")
+	}
+	if _, err := os.Stat(changes[0].Path); !os.IsNotExist(err) {
+		t.Errorf("o arquivo que não existia deveria ser removido: %v", err)
+	}
+	if data, _ := os.ReadFile(changes[1].Path); string(data) != "antigo\n" {
+		t.Errorf("%s = %q, esperado o conteúdo anterior", changes[1].Path, data)
+	}
+	for _, change := range changes {
+		if _, err := os.Stat(change.Candidate()); err == nil {
+			t.Errorf("candidato restante: %s", change.Candidate())
+		}
+	}
+}
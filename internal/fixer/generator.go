@@ -4,12 +4,92 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/mairinkdev/Hardshell/internal/atomicfile"
 	"github.com/mairinkdev/Hardshell/internal/report"
 )
 
+const (
+	// sshdConfigPath e sshdDropInPath são os arquivos de configuração do sshd editados pelas correções
+	sshdConfigPath = "/etc/ssh/sshd_config"
+	sshdDropInPath = "/etc/ssh/sshd_config.d/00-hardshell.conf"
+)
+
+// sshdConfigPattern encontra o caminho do sshd_config, sem casar com o do diretório sshd_config.d
+var sshdConfigPattern = regexp.MustCompile(`(` + regexp.QuoteMeta(sshdConfigPath) + `)([^._A-Za-z0-9-]|$)`)
+
+// logicalPathPattern encontra os caminhos do sistema analisado usados pelos comandos. Caminhos
+// que o analisador já converteu para o mountPoint não casam, pois não iniciam uma palavra.
+var logicalPathPattern = regexp.MustCompile(`(^|[\s'"=])(/etc/ssh/|/etc/sysctl\.conf)`)
+
+// sshStageScript cria as cópias candidatas e os backups antes das correções SSH
+const sshStageScript = `# As correções SSH editam cópias candidatas do sshd_config e do drop-in, que só
+# substituem os arquivos em uso depois de validadas com sshd -t
+SSHD_CONFIG=` + sshdConfigPath + `
+SSHD_DROPIN=` + sshdDropInPath + `
+SSHD_BACKUP="$SSHD_CONFIG.bak.$(date +%Y%m%d%H%M%S)"
+SSHD_DROPIN_BACKUP=""
+cp -p "$SSHD_CONFIG" "$SSHD_BACKUP" && log "INFO" "Backup criado: $SSHD_BACKUP"
+cp -p "$SSHD_CONFIG" "$SSHD_CONFIG` + atomicfile.Suffix + `"
+if [ -f "$SSHD_DROPIN" ]; then
+    SSHD_DROPIN_BACKUP="$SSHD_DROPIN.bak.$(date +%Y%m%d%H%M%S)"
+    cp -p "$SSHD_DROPIN" "$SSHD_DROPIN_BACKUP" && log "INFO" "Backup criado: $SSHD_DROPIN_BACKUP"
+    cp -p "$SSHD_DROPIN" "$SSHD_DROPIN` + atomicfile.Suffix + `"
+fi
+
+# Recarrega o sshd ativo; sem serviço ativo, a configuração é lida na próxima inicialização
+function reload_sshd() {
+    if command -v systemctl >/dev/null 2>&1; then
+        for unit in ssh.service sshd.service; do
+            if systemctl is-active --quiet "$unit"; then
+                systemctl reload "$unit"
+                return $?
+            fi
+        done
+        log "INFO" "Serviço SSH não está ativo; a nova configuração será usada na próxima inicialização"
+        return 0
+    fi
+    service ssh reload 2>/dev/null || service sshd reload
+}
+
+`
+
+// sshInstallScript valida as cópias candidatas, as coloca no lugar dos arquivos e recarrega o sshd.
+// O drop-in candidato é incluído antes do sshd_config candidato, como no arquivo final.
+const sshInstallScript = `# Valida a configuração candidata antes de substituir os arquivos em uso
+SSHD_VALIDATE=$(mktemp)
+{
+    if [ -f "$SSHD_DROPIN` + atomicfile.Suffix + `" ]; then
+        echo "Include $SSHD_DROPIN` + atomicfile.Suffix + `"
+    fi
+    cat "$SSHD_CONFIG` + atomicfile.Suffix + `"
+} > "$SSHD_VALIDATE"
+if sshd -t -f "$SSHD_VALIDATE"; then
+    mv -f "$SSHD_CONFIG` + atomicfile.Suffix + `" "$SSHD_CONFIG"
+    if [ -f "$SSHD_DROPIN` + atomicfile.Suffix + `" ]; then
+        mv -f "$SSHD_DROPIN` + atomicfile.Suffix + `" "$SSHD_DROPIN"
+    fi
+    if ! reload_sshd; then
+        log "ERROR" "Falha ao recarregar o sshd, restaurando a configuração anterior"
+        cp -p "$SSHD_BACKUP" "$SSHD_CONFIG"
+        if [ -n "$SSHD_DROPIN_BACKUP" ]; then
+            cp -p "$SSHD_DROPIN_BACKUP" "$SSHD_DROPIN"
+        else
+            rm -f "$SSHD_DROPIN"
+        fi
+        reload_sshd || log "ERROR" "Falha ao recarregar a configuração SSH restaurada"
+    fi
+else
+    log "ERROR" "Configuração SSH inválida, nenhuma alteração do sshd_config foi aplicada"
+    rm -f "$SSHD_CONFIG` + atomicfile.Suffix + `" "$SSHD_DROPIN` + atomicfile.Suffix + `"
+fi
+rm -f "$SSHD_VALIDATE"
+
+`
+
 // Generator é responsável pela geração de scripts de correção
 type Generator struct {
 	mountPoint string
@@ -106,6 +186,10 @@ log "INFO" "Iniciando aplicação de correções de segurança..."
 		// Adiciona comandos de backup para arquivos específicos
 		switch category {
 		case "ssh":
+			if g.mountPoint == "" {
+				sb.WriteString(sshStageScript)
+				break
+			}
 			sb.WriteString("# Backup do arquivo de configuração SSH\n")
 			sb.WriteString("backup_file /etc/ssh/sshd_config\n\n")
 		case "sysctl":
@@ -122,6 +206,8 @@ log "INFO" "Iniciando aplicação de correções de segurança..."
 			command := issue.FixCommand
 			if g.mountPoint != "" {
 				command = g.adaptCommandForMountPoint(command)
+			} else if category == "ssh" {
+				command = stageSSHCommand(command)
 			}
 
 			sb.WriteString(fmt.Sprintf("%s\n", command))
@@ -131,6 +217,12 @@ log "INFO" "Iniciando aplicação de correções de segurança..."
 			sb.WriteString(fmt.Sprintf("    log \"ERROR\" \"Falha ao aplicar correção: %s\"\n", issue.Description))
 			sb.WriteString("fi\n\n")
 		}
+
+		// Valida as cópias candidatas, substitui os arquivos e recarrega o sshd,
+		// restaurando a configuração anterior se o reload falhar
+		if category == "ssh" && g.mountPoint == "" {
+			sb.WriteString(sshInstallScript)
+		}
 	}
 
 	// Adiciona o rodapé do script
@@ -146,14 +238,22 @@ log "INFO" "Iniciando aplicação de correções de segurança..."
 	return nil
 }
 
+// stageSSHCommand faz um comando de correção SSH editar as cópias candidatas do
+// sshd_config e do drop-in em vez dos arquivos em uso
+func stageSSHCommand(command string) string {
+	command = strings.Replace(command, sshdDropInPath, sshdDropInPath+atomicfile.Suffix, -1)
+	return sshdConfigPattern.ReplaceAllString(command, "${1}"+atomicfile.Suffix+"${2}")
+}
+
 // adaptCommandForMountPoint adapta um comando para uso com um ponto de montagem
 func (g *Generator) adaptCommandForMountPoint(command string) string {
 	// Substitui referências a arquivos específicos
-	command = strings.Replace(command, "/etc/ssh/sshd_config", filepath.Join(g.mountPoint, "etc/ssh/sshd_config"), -1)
-	command = strings.Replace(command, "/etc/sysctl.conf", filepath.Join(g.mountPoint, "etc/sysctl.conf"), -1)
+	command = logicalPathPattern.ReplaceAllString(command, "${1}"+filepath.Clean(g.mountPoint)+"${2}")
 
-	// Remove comandos que não podem ser executados em um mountPoint
+	// Remove comandos que não podem ser executados em um mountPoint; systemctl --root
+	// altera os arquivos do sistema montado e é mantido
 	command = strings.Replace(command, "systemctl ", "# systemctl ", -1)
+	command = strings.Replace(command, "# systemctl --root=", "systemctl --root=", -1)
 	command = strings.Replace(command, "service ", "# service ", -1)
 	command = strings.Replace(command, "sysctl -p", "# sysctl -p", -1)
 
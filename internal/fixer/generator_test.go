@@ -0,0 +1,203 @@
+package fixer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/atomicfile"
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+func TestStageSSHCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{
+			"sed -i '3a PermitRootLogin no' /etc/ssh/sshd_config",
+			"sed -i '3a PermitRootLogin no' /etc/ssh/sshd_config.hardshell-new",
+		},
+		{
+			"mkdir -p /etc/ssh/sshd_config.d && echo 'X11Forwarding no' >> /etc/ssh/sshd_config.d/00-hardshell.conf && sed -i '1i Include sshd_config.d/00-hardshell.conf' /etc/ssh/sshd_config",
+			"mkdir -p /etc/ssh/sshd_config.d && echo 'X11Forwarding no' >> /etc/ssh/sshd_config.d/00-hardshell.conf.hardshell-new && sed -i '1i Include sshd_config.d/00-hardshell.conf' /etc/ssh/sshd_config.hardshell-new",
+		},
+		{
+			// Arquivos incluídos e chaves de host não são copiados
+			"ssh-keygen -q -t ed25519 -N '' -f /etc/ssh/ssh_host_ed25519_key",
+			"ssh-keygen -q -t ed25519 -N '' -f /etc/ssh/ssh_host_ed25519_key",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := stageSSHCommand(tt.command); got != tt.want {
+			t.Errorf("stageSSHCommand(%q) =\n%s\nesperado\n%s", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestAdaptCommandForMountPoint(t *testing.T) {
+	g := NewGenerator("/mnt/image/")
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{
+			"sed -i '3a PermitRootLogin no' /etc/ssh/sshd_config && systemctl reload ssh",
+			"sed -i '3a PermitRootLogin no' /mnt/image/etc/ssh/sshd_config && # systemctl reload ssh",
+		},
+		{
+			// Caminhos já convertidos pelo analisador não recebem o mountPoint de novo
+			"sed -i '\\#AAAA#d' /mnt/image/etc/ssh/authorized_keys/backup",
+			"sed -i '\\#AAAA#d' /mnt/image/etc/ssh/authorized_keys/backup",
+		},
+		{
+			"systemctl --root=/mnt/image disable telnet.socket",
+			"systemctl --root=/mnt/image disable telnet.socket",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := g.adaptCommandForMountPoint(tt.command); got != tt.want {
+			t.Errorf("adaptCommandForMountPoint(%q) =\n%s\nesperado\n%s", tt.command, got, tt.want)
+		}
+	}
+}
+
+// sshScriptIssues editam o sshd_config e criam o drop-in
+var sshScriptIssues = []report.Issue{
+	{
+		Category:    "ssh",
+		Severity:    report.SeverityCritical,
+		Description: "Login direto como root deve ser desabilitado",
+		FixCommand:  "sed -i 's/^PermitRootLogin yes/PermitRootLogin no/' /etc/ssh/sshd_config",
+	},
+	{
+		Category:    "ssh",
+		Severity:    report.SeverityWarning,
+		Description: "Autenticação por senha deve ser desabilitada",
+		FixCommand:  "mkdir -p /etc/ssh/sshd_config.d && echo 'PasswordAuthentication no' >> /etc/ssh/sshd_config.d/00-hardshell.conf",
+	},
+}
+
+// runSSHScript gera o script de correção e o executa com /etc/ssh apontando para root e
+// com sshd e systemctl simulados, que terminam com os códigos informados
+func runSSHScript(t *testing.T, root string, sshdExit, reloadExit string) string {
+	t.Helper()
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash não encontrado")
+	}
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "fix.sh")
+	if err := NewGenerator("").GenerateScript(sshScriptIssues, script); err != nil {
+		t.Fatalf("GenerateScript: %v", err)
+	}
+
+	data, err := os.ReadFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Replace(string(data), "/etc/ssh", filepath.Join(root, "etc/ssh"), -1)
+	content = strings.Replace(content, `"$EUID" -ne 0`, "1 -eq 0", 1)
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	bin := filepath.Join(dir, "bin")
+	log := filepath.Join(dir, "log")
+	stubs := map[string]string{
+		"sshd":      "echo \"sshd $*\" >> " + log + "\ncat \"$3\" >> " + log + "\nexit " + sshdExit + "\n",
+		"systemctl": "echo \"systemctl $*\" >> " + log + "\n[ \"$1\" = reload ] && exit " + reloadExit + "\nexit 0\n",
+	}
+	if err := os.MkdirAll(bin, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range stubs {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(bash, script)
+	cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("script falhou: %v\n%s", err, output)
+	}
+
+	calls, _ := os.ReadFile(log)
+	return string(calls)
+}
+
+// sshRoot cria um /etc/ssh com um sshd_config que permite login como root
+func sshRoot(t *testing.T) (string, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	config := filepath.Join(root, "etc/ssh/sshd_config")
+	if err := os.MkdirAll(filepath.Dir(config), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config, []byte("PermitRootLogin yes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root, config
+}
+
+// assertSSHFiles verifica o sshd_config, o drop-in e a ausência de cópias candidatas
+func assertSSHFiles(t *testing.T, root, config, wantConfig string, wantDropIn bool) {
+	t.Helper()
+
+	if data, _ := os.ReadFile(config); string(data) != wantConfig {
+		t.Errorf("sshd_config = %q, esperado %q", data, wantConfig)
+	}
+	dropIn := filepath.Join(root, "etc/ssh/sshd_config.d/00-hardshell.conf")
+	if _, err := os.Stat(dropIn); (err == nil) != wantDropIn {
+		t.Errorf("drop-in existe = %v, esperado %v", err == nil, wantDropIn)
+	}
+	if candidates, _ := filepath.Glob(filepath.Join(root, "etc/ssh/*"+atomicfile.Suffix)); len(candidates) > 0 {
+		t.Errorf("cópias candidatas restantes: %v", candidates)
+	}
+	if candidates, _ := filepath.Glob(filepath.Join(root, "etc/ssh/sshd_config.d/*"+atomicfile.Suffix)); len(candidates) > 0 {
+		t.Errorf("cópias candidatas restantes: %v", candidates)
+	}
+}
+
+func TestSSHScriptApplies(t *testing.T) {
+	root, config := sshRoot(t)
+	calls := runSSHScript(t, root, "0", "0")
+
+	assertSSHFiles(t, root, config, "PermitRootLogin no\n", true)
+	if !strings.Contains(calls, "Include "+filepath.Join(root, "etc/ssh/sshd_config.d/00-hardshell.conf")+atomicfile.Suffix) {
+		t.Errorf("o sshd -t não validou o drop-in candidato:\n%s", calls)
+	}
+	if !strings.Contains(calls, "systemctl reload ssh.service") {
+		t.Errorf("o sshd não foi recarregado:\n%s", calls)
+	}
+}
+
+func TestSSHScriptInvalidConfig(t *testing.T) {
+	root, config := sshRoot(t)
+	calls := runSSHScript(t, root, "1", "0")
+
+	assertSSHFiles(t, root, config, "PermitRootLogin yes\n", false)
+	if strings.Contains(calls, "systemctl reload") {
+		t.Errorf("o sshd não deveria ser recarregado com uma configuração inválida:\n%s", calls)
+	}
+}
+
+func TestSSHScriptReloadFailure(t *testing.T) {
+	root, config := sshRoot(t)
+	calls := runSSHScript(t, root, "0", "1")
+
+	assertSSHFiles(t, root, config, "PermitRootLogin yes\n", false)
+	if strings.Count(calls, "systemctl reload") != 2 {
+		t.Errorf("a configuração restaurada deveria ser recarregada:\n%s", calls)
+	}
+}
@@ -0,0 +1,322 @@
+package mail
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/services"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// minRelayPrefix é o menor prefixo IPv4 aceito sem alerta nas redes autorizadas a fazer relay
+const minRelayPrefix = 16
+
+// Analyzer é o analisador da configuração dos servidores de email (Postfix e Exim)
+type Analyzer struct {
+	mountPoint string
+}
+
+// option é um parâmetro de configuração, com as linhas que ocupa no arquivo
+type option struct {
+	Value string
+
+	// Kind é o tipo de lista do Exim (hostlist, domainlist...), vazio nos demais casos
+	Kind string
+
+	// Line e End são a primeira e a última linha da definição, incluindo continuações
+	Line int
+	End  int
+}
+
+// change é uma alteração em um arquivo de configuração: substitui as linhas Start a End
+// por Text ou, com Insert, insere Text antes da linha Start (no fim do arquivo se Start for 0)
+type change struct {
+	Service string
+	File    string
+	Text    string
+	Start   int
+	End     int
+	Insert  bool
+}
+
+// NewAnalyzer cria um novo analisador dos servidores de email
+func NewAnalyzer(mountPoint string) *Analyzer {
+	return &Analyzer{
+		mountPoint: mountPoint,
+	}
+}
+
+// Analyze verifica a configuração do Postfix e do Exim quando são iniciados no boot
+func (a *Analyzer) Analyze() ([]report.Issue, error) {
+	issues, _, err := a.analyze()
+	return issues, err
+}
+
+// analyze retorna os problemas e as alterações de configuração que os corrigem
+func (a *Analyzer) analyze() ([]report.Issue, []change, error) {
+	var issues []report.Issue
+	var changes []change
+
+	if _, enabled := services.Enabled(a.mountPoint, "postfix"); enabled {
+		postfixIssues, postfixChanges, err := a.analyzePostfix()
+		if err != nil {
+			return nil, nil, err
+		}
+		issues = append(issues, postfixIssues...)
+		changes = append(changes, postfixChanges...)
+	}
+
+	if _, enabled := services.Enabled(a.mountPoint, "exim4", "exim"); enabled {
+		eximIssues, eximChanges, err := a.analyzeExim()
+		if err != nil {
+			return nil, nil, err
+		}
+		issues = append(issues, eximIssues...)
+		changes = append(changes, eximChanges...)
+	}
+
+	return issues, changes, nil
+}
+
+// Fix aplica as correções de configuração, mantendo um backup .bak de cada arquivo alterado.
+// No sistema em execução, a configuração é validada e o servidor de email recarregado; se a
+// validação falhar, os arquivos são restaurados. Interfaces de escuta e redes de relay amplas
+// dependem do papel do servidor e não são alteradas.
+func (a *Analyzer) Fix() error {
+	_, changes, err := a.analyze()
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Println("Nenhuma correção automática para os servidores de email.")
+		return nil
+	}
+
+	byFile := make(map[string][]change)
+	var files, mtas []string
+	for _, c := range changes {
+		if _, ok := byFile[c.File]; !ok {
+			files = append(files, c.File)
+		}
+		byFile[c.File] = append(byFile[c.File], c)
+		if !util.ContainsString(mtas, c.Service) {
+			mtas = append(mtas, c.Service)
+		}
+	}
+
+	originals := make(map[string][]byte)
+	for _, file := range files {
+		path := util.JoinMount(a.mountPoint, file)
+		original, err := applyChanges(path, byFile[file])
+		if err != nil {
+			restoreFiles(originals)
+			return err
+		}
+		originals[path] = original
+	}
+
+	if a.mountPoint != "" {
+		return nil
+	}
+	for _, mta := range mtas {
+		if err := reload(mta); err != nil {
+			restoreFiles(originals)
+			return fmt.Errorf("configuração restaurada: %w", err)
+		}
+	}
+	return nil
+}
+
+// applyChanges aplica as alterações em um arquivo e retorna o conteúdo anterior
+// (nil se o arquivo não existia)
+func applyChanges(path string, changes []change) ([]byte, error) {
+	mode := os.FileMode(0644)
+	data, err := os.ReadFile(path)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	if existed {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao verificar %s: %w", path, err)
+		}
+		mode = info.Mode().Perm()
+	}
+
+	var lines []string
+	if content := strings.TrimSuffix(string(data), "\n"); content != "" {
+		lines = strings.Split(content, "\n")
+	}
+
+	// As alterações são aplicadas de baixo para cima para preservar a numeração das linhas
+	var appended []string
+	var positioned []change
+	for _, c := range changes {
+		if c.Insert && c.Start == 0 {
+			appended = append(appended, c.Text)
+			continue
+		}
+		positioned = append(positioned, c)
+	}
+	sort.SliceStable(positioned, func(i, j int) bool {
+		return positioned[i].Start > positioned[j].Start
+	})
+	for _, c := range positioned {
+		if c.Start < 1 || c.Start-1 > len(lines) {
+			continue
+		}
+		end := c.Start - 1
+		if !c.Insert && c.End <= len(lines) {
+			end = c.End
+		}
+		rest := append([]string{c.Text}, lines[end:]...)
+		lines = append(lines[:c.Start-1], rest...)
+	}
+	lines = append(lines, appended...)
+
+	if existed {
+		if err := os.WriteFile(path+".bak", data, mode); err != nil {
+			return nil, fmt.Errorf("erro ao criar backup de %s: %w", path, err)
+		}
+		fmt.Printf("Backup criado em %s.bak\n", path)
+	} else if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), mode); err != nil {
+		return nil, fmt.Errorf("erro ao gravar %s: %w", path, err)
+	}
+
+	for _, c := range changes {
+		fmt.Printf("%s: %s\n", path, c.Text)
+	}
+
+	if !existed {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// restoreFiles devolve os arquivos ao conteúdo anterior, removendo os que foram criados
+func restoreFiles(originals map[string][]byte) {
+	for path, data := range originals {
+		if data == nil {
+			os.Remove(path)
+			continue
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			fmt.Printf("Aviso: não foi possível restaurar %s: %v\n", path, err)
+		}
+	}
+}
+
+// reload valida a configuração do servidor de email e a aplica
+func reload(mta string) error {
+	switch mta {
+	case "postfix":
+		if !util.HasCommand("postfix") {
+			fmt.Println("Recarregue o Postfix para aplicar as alterações (ex: postfix reload).")
+			return nil
+		}
+		if err := run("postfix", "check"); err != nil {
+			return err
+		}
+		return run("postfix", "reload")
+	case "exim4":
+		// No Debian, a configuração do Exim é gerada a partir dos arquivos alterados
+		if !util.HasCommand("update-exim4.conf") {
+			fmt.Println("Execute update-exim4.conf e reinicie o exim4 para aplicar as alterações.")
+			return nil
+		}
+		if err := run("update-exim4.conf"); err != nil {
+			return err
+		}
+		fmt.Println("Reinicie o exim4 para aplicar as alterações (ex: systemctl restart exim4).")
+	case "exim":
+		if util.HasCommand("exim") {
+			if err := run("exim", "-bV"); err != nil {
+				return err
+			}
+		}
+		fmt.Println("Reinicie o exim para aplicar as alterações (ex: systemctl restart exim).")
+	}
+	return nil
+}
+
+// run executa um comando e inclui sua saída no erro
+func run(name string, args ...string) error {
+	fmt.Printf("Executando: %s\n", strings.TrimSpace(name+" "+strings.Join(args, " ")))
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// newIssue cria um problema da categoria mail
+func newIssue(severity report.Severity, key, description, current, recommended, source string) report.Issue {
+	return report.Issue{
+		Category:         "mail",
+		Severity:         severity,
+		Key:              key,
+		Description:      description,
+		CurrentValue:     current,
+		RecommendedValue: recommended,
+		Source:           source,
+	}
+}
+
+// widestNetwork retorna a rede mais ampla de uma lista de hosts autorizados a fazer relay e
+// o tamanho do seu prefixo: 0 para qualquer origem (*, 0.0.0.0/0 ou ::/0) e -1 quando não
+// há redes IPv4 além do loopback. Tabelas de lookup e exclusões são ignoradas.
+func widestNetwork(items []string) (string, int) {
+	widest, prefix := "", -1
+	for _, item := range items {
+		if item == "*" || strings.HasSuffix(item, "/0") {
+			return item, 0
+		}
+		if strings.HasPrefix(item, "!") || strings.Contains(item, ":") {
+			continue
+		}
+		ip, network, err := net.ParseCIDR(item)
+		if err != nil || ip.To4() == nil || ip.IsLoopback() {
+			continue
+		}
+		if ones, _ := network.Mask.Size(); prefix == -1 || ones < prefix {
+			widest, prefix = item, ones
+		}
+	}
+	return widest, prefix
+}
+
+// splitList separa uma lista do Postfix, cujos itens são separados por vírgulas ou espaços
+func splitList(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// parseYes interpreta os valores booleanos do Postfix e do Exim
+func parseYes(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "true", "1":
+		return true
+	}
+	return false
+}
+
+// shellQuote protege um valor para uso em um comando de shell: com aspas duplas quando contém
+// aspas simples e nada que o shell expanda, e com aspas simples nos demais casos
+func shellQuote(value string) string {
+	if strings.Contains(value, "'") && !strings.ContainsAny(value, "$`\\\"!") {
+		return `"` + value + `"`
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
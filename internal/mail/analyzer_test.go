@@ -0,0 +1,90 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+// writeTree grava os arquivos informados (caminho do sistema analisado -> conteúdo) em um mountPoint temporário
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for path, content := range files {
+		file := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// findIssue retorna o problema com a chave informada
+func findIssue(issues []report.Issue, key string) (report.Issue, bool) {
+	for _, issue := range issues {
+		if issue.Key == key {
+			return issue, true
+		}
+	}
+	return report.Issue{}, false
+}
+
+func TestWidestNetwork(t *testing.T) {
+	tests := []struct {
+		items   []string
+		network string
+		prefix  int
+	}{
+		{[]string{"127.0.0.0/8", "[::1]/128"}, "", -1},
+		{[]string{"127.0.0.0/8", "0.0.0.0/0"}, "0.0.0.0/0", 0},
+		{[]string{"*"}, "*", 0},
+		{[]string{"::/0"}, "::/0", 0},
+		{[]string{"192.168.1.0/24", "10.0.0.0/8"}, "10.0.0.0/8", 8},
+		{[]string{"!10.0.0.0/8", "192.168.1.0/24"}, "192.168.1.0/24", 24},
+		{[]string{"hash:/etc/postfix/network_table", "172.16.0.0/12"}, "172.16.0.0/12", 12},
+	}
+
+	for _, tt := range tests {
+		network, prefix := widestNetwork(tt.items)
+		if network != tt.network || prefix != tt.prefix {
+			t.Errorf("widestNetwork(%v) = %q, %d, esperado %q, %d", tt.items, network, prefix, tt.network, tt.prefix)
+		}
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/postfix/main.cf": "smtpd_banner = $myhostname ESMTP $mail_name\n" +
+			"mynetworks = 127.0.0.0/8\n" +
+			"    0.0.0.0/0\n" +
+			"relayhost =\n",
+	})
+	path := filepath.Join(root, "/etc/postfix/main.cf")
+
+	changes := []change{
+		{Text: "smtpd_banner = $myhostname ESMTP", Start: 1, End: 1},
+		{Text: "mynetworks = 127.0.0.0/8", Start: 2, End: 3},
+		{Text: "disable_vrfy_command = yes", Insert: true},
+	}
+	original, err := applyChanges(path, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "smtpd_banner = $myhostname ESMTP\n" +
+		"mynetworks = 127.0.0.0/8\n" +
+		"relayhost =\n" +
+		"disable_vrfy_command = yes\n"
+	if data, _ := os.ReadFile(path); string(data) != want {
+		t.Errorf("main.cf = %q, esperado %q", data, want)
+	}
+	if backup, _ := os.ReadFile(path + ".bak"); string(backup) != string(original) {
+		t.Errorf("backup = %q, esperado o conteúdo anterior", backup)
+	}
+}
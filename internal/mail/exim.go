@@ -0,0 +1,410 @@
+package mail
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// eximDebianConfig é a configuração do debconf a partir da qual o Debian gera a do Exim
+const eximDebianConfig = "/etc/exim4/update-exim4.conf.conf"
+
+// eximDebianMacros são os arquivos de macros locais do Debian, para configuração única e dividida
+const (
+	eximDebianMacros      = "/etc/exim4/exim4.conf.localmacros"
+	eximDebianSplitMacros = "/etc/exim4/conf.d/main/000_localmacros"
+)
+
+// eximDebianCertificate é o certificado padrão do exim4 no Debian (gerado por exim-gencert)
+const eximDebianCertificate = "/etc/exim4/exim.crt"
+
+// eximConfigPaths são os locais da configuração do Exim nas demais distribuições e em instalações manuais
+var eximConfigPaths = []string{"/etc/exim/exim.conf", "/etc/exim/configure", "/etc/exim.conf", "/etc/mail/exim.conf", "/usr/exim/configure"}
+
+// eximBanner é um banner que não revela o software nem a versão
+const eximBanner = "$smtp_active_hostname ESMTP"
+
+// eximDefaults são os valores padrão das opções verificadas, usados quando não estão na configuração
+var eximDefaults = map[string]string{
+	"smtp_banner":     "$smtp_active_hostname ESMTP Exim $version_number $tod_full",
+	"openssl_options": "+no_sslv2 +no_sslv3 +single_dh_use +no_ticket +no_renegotiation",
+}
+
+// eximLegacyTLSOptions desabilitam TLSv1 e TLSv1.1 no Exim compilado com OpenSSL
+var eximLegacyTLSOptions = []string{"+no_tlsv1", "+no_tlsv1_1"}
+
+// eximConfig são as opções da seção principal da configuração do Exim (antes do primeiro begin)
+type eximConfig struct {
+	Path    string
+	Options map[string]option
+
+	// Begin é a linha do primeiro begin, antes da qual novas opções são inseridas
+	Begin int
+}
+
+// get retorna o valor de uma opção, ou o padrão quando não definida
+func (c *eximConfig) get(name string) (string, bool) {
+	if opt, ok := c.Options[name]; ok {
+		return opt.Value, true
+	}
+	return eximDefaults[name], false
+}
+
+// readEximConfig lê as opções e macros da seção principal de uma configuração do Exim.
+// Linhas terminadas em \ continuam na linha seguinte; diretivas como .ifdef e .include
+// não são avaliadas, e a última definição de uma opção prevalece.
+func readEximConfig(mountPoint, path string) (*eximConfig, error) {
+	file, err := os.Open(util.JoinMount(mountPoint, path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config := &eximConfig{Path: path, Options: make(map[string]option)}
+	var logical strings.Builder
+	start, number := 0, 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		number++
+		trimmed := strings.TrimSpace(scanner.Text())
+		if logical.Len() == 0 {
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ".") {
+				continue
+			}
+			start = number
+		}
+
+		if strings.HasSuffix(trimmed, `\`) {
+			logical.WriteString(strings.TrimSuffix(trimmed, `\`))
+			continue
+		}
+		logical.WriteString(trimmed)
+		line := logical.String()
+		logical.Reset()
+
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == "begin" {
+			config.Begin = start
+			break
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name := strings.Fields(parts[0])
+		opt := option{Value: strings.TrimSpace(parts[1]), Line: start, End: number}
+		if len(name) > 0 && name[0] == "hide" {
+			name = name[1:]
+		}
+		if len(name) == 2 && strings.HasSuffix(name[0], "list") {
+			opt.Kind = name[0]
+			name = name[1:]
+		}
+		if len(name) == 1 {
+			config.Options[name[0]] = opt
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// readDebianConfig lê o update-exim4.conf.conf, um arquivo de variáveis de shell (dc_nome='valor')
+func readDebianConfig(mountPoint, path string) (map[string]option, error) {
+	file, err := os.Open(util.JoinMount(mountPoint, path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]option)
+	number := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		number++
+		trimmed := strings.TrimSpace(scanner.Text())
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		parts := strings.SplitN(trimmed, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		values[parts[0]] = option{Value: strings.Trim(parts[1], `'"`), Line: number, End: number}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+// analyzeExim verifica a configuração do Exim: no Debian, os valores do debconf e as macros
+// locais usadas pelo modelo de configuração; nas demais distribuições, a configuração principal
+func (a *Analyzer) analyzeExim() ([]report.Issue, []change, error) {
+	if _, err := os.Stat(util.JoinMount(a.mountPoint, eximDebianConfig)); err == nil {
+		return a.analyzeDebianExim()
+	}
+
+	for _, path := range eximConfigPaths {
+		config, err := readEximConfig(a.mountPoint, path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("erro ao ler configuração do Exim: %w", err)
+		}
+		issues, changes := a.analyzeEximConfig(config)
+		return issues, changes, nil
+	}
+	return nil, nil, nil
+}
+
+// analyzeDebianExim verifica o exim4 configurado pelo debconf (Debian e Ubuntu)
+func (a *Analyzer) analyzeDebianExim() ([]report.Issue, []change, error) {
+	values, err := readDebianConfig(a.mountPoint, eximDebianConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler configuração do exim4: %w", err)
+	}
+
+	macrosPath := eximDebianMacros
+	if parseYes(values["dc_use_split_config"].Value) {
+		macrosPath = eximDebianSplitMacros
+	}
+	macros, err := readEximConfig(a.mountPoint, macrosPath)
+	if os.IsNotExist(err) {
+		macros = &eximConfig{Path: macrosPath, Options: make(map[string]option)}
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler macros do exim4: %w", err)
+	}
+
+	var issues []report.Issue
+	var changes []change
+
+	// setValue troca o valor de uma variável do debconf
+	setValue := func(issue *report.Issue, key, value string) {
+		c := change{Service: "exim4", File: eximDebianConfig, Text: fmt.Sprintf("%s='%s'", key, value)}
+		if opt, ok := values[key]; ok {
+			c.Start, c.End = opt.Line, opt.End
+		} else {
+			c.Insert = true
+		}
+		issue.FixCommand = a.eximCommand(c)
+		changes = append(changes, c)
+	}
+	// setMacro define uma macro no arquivo de macros locais
+	setMacro := func(issue *report.Issue, name, value string) {
+		c := change{Service: "exim4", File: macrosPath, Text: name + " = " + value}
+		if opt, ok := macros.Options[name]; ok {
+			c.Start, c.End = opt.Line, opt.End
+		} else {
+			c.Insert = true
+		}
+		issue.FixCommand = a.eximCommand(c)
+		changes = append(changes, c)
+	}
+	source := func(file string, opt option, ok bool) string {
+		if ok {
+			return fmt.Sprintf("%s:%d", file, opt.Line)
+		}
+		return file
+	}
+
+	// Relay aberto
+	nets, ok := values["dc_relay_nets"]
+	if network, prefix := widestNetwork(eximList(nets.Value)); prefix == 0 {
+		issue := newIssue(report.SeverityCritical, "dc_relay_nets",
+			fmt.Sprintf("dc_relay_nets inclui %s: qualquer endereço pode usar o exim4 como relay", network),
+			nets.Value, "vazio (apenas a própria máquina)", source(eximDebianConfig, nets, ok))
+		setValue(&issue, "dc_relay_nets", "")
+		issues = append(issues, issue)
+	} else if prefix > 0 && prefix < minRelayPrefix {
+		issues = append(issues, newIssue(report.SeverityWarning, "dc_relay_nets",
+			fmt.Sprintf("dc_relay_nets inclui a rede ampla %s, que pode usar o exim4 como relay sem autenticação", network),
+			nets.Value, "apenas as redes que enviam email por este servidor", source(eximDebianConfig, nets, ok)))
+	}
+
+	domains, ok := values["dc_relay_domains"]
+	if util.ContainsString(eximList(domains.Value), "*") {
+		issue := newIssue(report.SeverityCritical, "dc_relay_domains",
+			"dc_relay_domains aceita qualquer domínio: o exim4 repassa mensagens para qualquer destino",
+			domains.Value, "vazio (apenas os domínios locais)", source(eximDebianConfig, domains, ok))
+		setValue(&issue, "dc_relay_domains", "")
+		issues = append(issues, issue)
+	}
+
+	if interfaces, ok := values["dc_local_interfaces"]; len(eximList(interfaces.Value)) == 0 {
+		issues = append(issues, newIssue(report.SeverityInfo, "dc_local_interfaces",
+			"exim4 aceita conexões SMTP em todas as interfaces; servidores que apenas enviam email devem escutar no loopback",
+			interfaces.Value, "127.0.0.1 ; ::1", source(eximDebianConfig, interfaces, ok)))
+	}
+
+	// TLS: o modelo do Debian só anuncia STARTTLS com MAIN_TLS_ENABLE definida
+	if _, ok := macros.Options["MAIN_TLS_ENABLE"]; !ok {
+		_, custom := macros.Options["MAIN_TLS_CERTIFICATE"]
+		_, err := os.Stat(util.JoinMount(a.mountPoint, eximDebianCertificate))
+		description := "exim4 não oferece STARTTLS: mensagens e credenciais trafegam em texto claro"
+		issue := newIssue(report.SeverityWarning, "MAIN_TLS_ENABLE", description, "", "yes", macrosPath)
+		if custom || err == nil {
+			setMacro(&issue, "MAIN_TLS_ENABLE", "yes")
+		} else {
+			issue.Description += " (gere o certificado com /usr/share/doc/exim4-base/examples/exim-gencert)"
+		}
+		issues = append(issues, issue)
+	}
+
+	// Banner: sem MAIN_SMTP_BANNER, vale o padrão do Exim, que inclui a versão
+	banner, ok := macros.Options["MAIN_SMTP_BANNER"]
+	if !ok {
+		banner.Value = eximDefaults["smtp_banner"]
+	}
+	if issue, disclosed := bannerIssue("MAIN_SMTP_BANNER", banner.Value, source(macrosPath, banner, ok)); disclosed {
+		setMacro(&issue, "MAIN_SMTP_BANNER", eximBanner)
+		issues = append(issues, issue)
+	}
+
+	return issues, changes, nil
+}
+
+// analyzeEximConfig verifica as opções da configuração principal do Exim
+func (a *Analyzer) analyzeEximConfig(config *eximConfig) ([]report.Issue, []change) {
+	var issues []report.Issue
+	var changes []change
+
+	// set troca o valor de uma opção; opções ausentes são inseridas antes do primeiro begin
+	set := func(issue *report.Issue, key, text string) {
+		c := change{Service: "exim", File: config.Path, Text: text}
+		if opt, ok := config.Options[key]; ok {
+			c.Start, c.End = opt.Line, opt.End
+		} else {
+			c.Start, c.Insert = config.Begin, true
+		}
+		issue.FixCommand = a.eximCommand(c)
+		changes = append(changes, c)
+	}
+	source := func(key string) string {
+		if opt, ok := config.Options[key]; ok {
+			return fmt.Sprintf("%s:%d", config.Path, opt.Line)
+		}
+		return config.Path
+	}
+
+	// Relay aberto
+	if hosts, ok := config.Options["relay_from_hosts"]; ok {
+		if network, prefix := widestNetwork(eximList(hosts.Value)); prefix == 0 {
+			issue := newIssue(report.SeverityCritical, "relay_from_hosts",
+				fmt.Sprintf("relay_from_hosts inclui %s: qualquer endereço pode usar o Exim como relay", network),
+				hosts.Value, "<; 127.0.0.1 ; ::1", source("relay_from_hosts"))
+			set(&issue, "relay_from_hosts", "hostlist relay_from_hosts = <; 127.0.0.1 ; ::1")
+			issues = append(issues, issue)
+		} else if prefix > 0 && prefix < minRelayPrefix {
+			issues = append(issues, newIssue(report.SeverityWarning, "relay_from_hosts",
+				fmt.Sprintf("relay_from_hosts inclui a rede ampla %s, que pode usar o Exim como relay sem autenticação", network),
+				hosts.Value, "apenas as redes que enviam email por este servidor", source("relay_from_hosts")))
+		}
+	}
+
+	if domains, ok := config.Options["relay_to_domains"]; ok && util.ContainsString(eximList(domains.Value), "*") {
+		issue := newIssue(report.SeverityCritical, "relay_to_domains",
+			"relay_to_domains aceita qualquer domínio: o Exim repassa mensagens para qualquer destino",
+			domains.Value, "vazio (apenas os domínios locais)", source("relay_to_domains"))
+		set(&issue, "relay_to_domains", "domainlist relay_to_domains =")
+		issues = append(issues, issue)
+	}
+
+	if _, ok := config.Options["local_interfaces"]; !ok {
+		issues = append(issues, newIssue(report.SeverityInfo, "local_interfaces",
+			"Exim aceita conexões SMTP em todas as interfaces; servidores que apenas enviam email devem escutar no loopback",
+			"", "<; 127.0.0.1 ; ::1", config.Path))
+	}
+
+	// TLS: o Exim anuncia STARTTLS para todos os hosts por padrão (4.92+)
+	if advertise, ok := config.Options["tls_advertise_hosts"]; ok && len(eximList(advertise.Value)) == 0 {
+		issue := newIssue(report.SeverityWarning, "tls_advertise_hosts",
+			"Exim não oferece STARTTLS: mensagens e credenciais trafegam em texto claro",
+			"", "*", source("tls_advertise_hosts"))
+		if _, cert := config.Options["tls_certificate"]; cert {
+			set(&issue, "tls_advertise_hosts", "tls_advertise_hosts = *")
+		} else {
+			issue.Description += " (configure tls_certificate e tls_privatekey)"
+		}
+		issues = append(issues, issue)
+	} else {
+		current, _ := config.get("openssl_options")
+		recommended := current
+		for _, legacy := range eximLegacyTLSOptions {
+			if !util.ContainsString(strings.Fields(current), legacy) {
+				recommended += " " + legacy
+			}
+		}
+		if recommended != current {
+			issue := newIssue(report.SeverityWarning, "openssl_options",
+				"openssl_options não desabilita TLSv1 e TLSv1.1 (Exim compilado com OpenSSL)",
+				current, recommended, source("openssl_options"))
+			set(&issue, "openssl_options", "openssl_options = "+recommended)
+			issues = append(issues, issue)
+		}
+	}
+
+	banner, _ := config.get("smtp_banner")
+	if issue, disclosed := bannerIssue("smtp_banner", banner, source("smtp_banner")); disclosed {
+		set(&issue, "smtp_banner", "smtp_banner = "+eximBanner)
+		issues = append(issues, issue)
+	}
+
+	return issues, changes
+}
+
+// bannerIssue verifica se o banner SMTP do Exim revela a versão ou o software
+func bannerIssue(key, banner, source string) (report.Issue, bool) {
+	switch {
+	case strings.Contains(banner, "version_number"):
+		return newIssue(report.SeverityWarning, key, "O banner SMTP revela a versão do Exim", banner, eximBanner, source), true
+	case strings.Contains(strings.ToLower(banner), "exim"):
+		return newIssue(report.SeverityInfo, key, "O banner SMTP revela o software de email (Exim)", banner, eximBanner, source), true
+	}
+	return report.Issue{}, false
+}
+
+// eximCommand gera o comando de shell que aplica uma alteração na configuração do Exim
+func (a *Analyzer) eximCommand(c change) string {
+	path := util.JoinMount(a.mountPoint, c.File)
+	var command string
+	switch {
+	case c.Insert && c.Start == 0:
+		command = fmt.Sprintf("printf '%%s\\n' %s >> %s", shellQuote(c.Text), path)
+	case c.Insert:
+		command = fmt.Sprintf("sed -i %s %s", shellQuote(fmt.Sprintf("%di\\%s", c.Start, c.Text)), path)
+	default:
+		command = fmt.Sprintf("sed -i %s %s", shellQuote(fmt.Sprintf("%d,%dc\\%s", c.Start, c.End, c.Text)), path)
+	}
+
+	if a.mountPoint == "" && c.Service == "exim4" {
+		command += " && update-exim4.conf && systemctl restart exim4"
+	}
+	return command
+}
+
+// eximList separa uma lista do Exim. O separador padrão é ":", e pode ser trocado com um
+// prefixo "<" (ex: "<; 127.0.0.1 ; ::1"); nas variáveis do debconf, ";" também separa itens.
+func eximList(value string) []string {
+	value = strings.TrimSpace(value)
+	separators := ":;"
+	if strings.HasPrefix(value, "<") && len(value) > 1 {
+		separators, value = value[1:2], value[2:]
+	}
+
+	var items []string
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return strings.ContainsRune(separators, r) }) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,189 @@
+package mail
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+func TestEximList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"127.0.0.1 : 192.168.0.0/16", []string{"127.0.0.1", "192.168.0.0/16"}},
+		{"<; 127.0.0.1 ; ::1", []string{"127.0.0.1", "::1"}},
+		{"<, 10.0.0.0/8, *", []string{"10.0.0.0/8", "*"}},
+		{"127.0.0.1;10.0.0.0/8", []string{"127.0.0.1", "10.0.0.0/8"}},
+	}
+
+	for _, tt := range tests {
+		if got := eximList(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("eximList(%q) = %v, esperado %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestReadEximConfig(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/exim/exim.conf": "# configuração principal\n" +
+			".ifdef TLS\n" +
+			"tls_certificate = /etc/exim/cert.pem\n" +
+			".endif\n" +
+			"hostlist relay_from_hosts = <; 127.0.0.1 ; \\\n" +
+			"    0.0.0.0/0\n" +
+			"hide mysql_servers = localhost/db/user/secret\n" +
+			"\n" +
+			"begin acl\n" +
+			"acl_check_rcpt:\n" +
+			"  accept hosts = :\n",
+	})
+
+	config, err := readEximConfig(root, "/etc/exim/exim.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	relay := config.Options["relay_from_hosts"]
+	if relay.Value != "<; 127.0.0.1 ; 0.0.0.0/0" || relay.Kind != "hostlist" || relay.Line != 5 || relay.End != 6 {
+		t.Errorf("relay_from_hosts = %+v", relay)
+	}
+	if _, ok := config.Options["mysql_servers"]; !ok {
+		t.Error("opção com hide não encontrada")
+	}
+	if _, ok := config.Options["tls_certificate"]; !ok {
+		t.Error("opção dentro de .ifdef não encontrada")
+	}
+	if config.Begin != 9 {
+		t.Errorf("Begin = %d, esperado 9", config.Begin)
+	}
+	// As ACLs após o begin não pertencem à seção principal
+	if _, ok := config.Options["accept hosts"]; ok || len(config.Options) != 3 {
+		t.Errorf("opções = %v, esperado apenas a seção principal", config.Options)
+	}
+}
+
+func TestAnalyzeEximConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		key      string
+		severity report.Severity
+		found    bool
+	}{
+		{
+			name:     "relay de qualquer origem",
+			config:   "hostlist relay_from_hosts = <; 127.0.0.1 ; 0.0.0.0/0\nbegin acl\n",
+			key:      "relay_from_hosts",
+			severity: report.SeverityCritical,
+			found:    true,
+		},
+		{
+			name:     "relay de rede ampla",
+			config:   "hostlist relay_from_hosts = 127.0.0.1 : 10.0.0.0/8\nbegin acl\n",
+			key:      "relay_from_hosts",
+			severity: report.SeverityWarning,
+			found:    true,
+		},
+		{
+			name:   "relay apenas local",
+			config: "hostlist relay_from_hosts = <; 127.0.0.1 ; ::1\nbegin acl\n",
+			key:    "relay_from_hosts",
+		},
+		{
+			name:     "relay para qualquer domínio",
+			config:   "domainlist relay_to_domains = *\nbegin acl\n",
+			key:      "relay_to_domains",
+			severity: report.SeverityCritical,
+			found:    true,
+		},
+		{
+			name:     "TLS sem desabilitar TLSv1",
+			config:   "begin acl\n",
+			key:      "openssl_options",
+			severity: report.SeverityWarning,
+			found:    true,
+		},
+		{
+			name:   "TLS com TLSv1 desabilitado",
+			config: "openssl_options = +no_sslv2 +no_sslv3 +no_tlsv1 +no_tlsv1_1\nbegin acl\n",
+			key:    "openssl_options",
+		},
+		{
+			name:     "STARTTLS desabilitado",
+			config:   "tls_advertise_hosts =\nbegin acl\n",
+			key:      "tls_advertise_hosts",
+			severity: report.SeverityWarning,
+			found:    true,
+		},
+		{
+			name:     "banner padrão com a versão",
+			config:   "begin acl\n",
+			key:      "smtp_banner",
+			severity: report.SeverityWarning,
+			found:    true,
+		},
+		{
+			name:   "banner sem o software",
+			config: "smtp_banner = " + eximBanner + "\nbegin acl\n",
+			key:    "smtp_banner",
+		},
+	}
+
+	for _, tt := range tests {
+		root := writeTree(t, map[string]string{"/etc/exim/exim.conf": tt.config})
+		issues, _, err := NewAnalyzer(root).analyzeExim()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		issue, found := findIssue(issues, tt.key)
+		if found != tt.found {
+			t.Errorf("%s: problema em %s = %v, esperado %v", tt.name, tt.key, found, tt.found)
+			continue
+		}
+		if found && issue.Severity != tt.severity {
+			t.Errorf("%s: severidade = %s, esperado %s", tt.name, issue.Severity, tt.severity)
+		}
+	}
+}
+
+func TestAnalyzeEximInsertsBeforeBegin(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/exim/exim.conf": "primary_hostname = mail.example.com\n\nbegin acl\n",
+	})
+
+	_, changes, err := NewAnalyzer(root).analyzeExim()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range changes {
+		if !c.Insert || c.Start != 3 {
+			t.Errorf("alteração %q = %+v, esperado inserir antes do begin na linha 3", c.Text, c)
+		}
+	}
+}
+
+func TestAnalyzeDebianExim(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		eximDebianConfig: "dc_eximconfig_configtype='internet'\n" +
+			"dc_relay_nets='127.0.0.1 ; 0.0.0.0/0'\n" +
+			"dc_relay_domains=''\n" +
+			"dc_local_interfaces='127.0.0.1 ; ::1'\n",
+		eximDebianMacros: "MAIN_TLS_ENABLE = yes\nMAIN_SMTP_BANNER = " + eximBanner + "\n",
+	})
+
+	issues, changes, err := NewAnalyzer(root).analyzeExim()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(issues) != 1 || issues[0].Key != "dc_relay_nets" || issues[0].Severity != report.SeverityCritical {
+		t.Fatalf("problemas = %+v, esperado apenas dc_relay_nets", issues)
+	}
+	if len(changes) != 1 || changes[0].Text != "dc_relay_nets=''" || changes[0].Start != 2 {
+		t.Errorf("alterações = %+v, esperado esvaziar dc_relay_nets na linha 2", changes)
+	}
+}
@@ -0,0 +1,268 @@
+package mail
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// postfixConfigPath é o arquivo de configuração principal do Postfix
+const postfixConfigPath = "/etc/postfix/main.cf"
+
+// postfixRelayRestrictions é o valor padrão do smtpd_relay_restrictions (Postfix 2.10+)
+const postfixRelayRestrictions = "permit_mynetworks, permit_sasl_authenticated, defer_unauth_destination"
+
+// postfixLoopbackNetworks restringe o relay sem autenticação à própria máquina
+const postfixLoopbackNetworks = "127.0.0.0/8 [::ffff:127.0.0.0]/104 [::1]/128"
+
+// postfixTLSProtocols exclui os protocolos obsoletos, em uma sintaxe aceita por todas as versões
+const postfixTLSProtocols = "!SSLv2, !SSLv3, !TLSv1, !TLSv1.1"
+
+// postfixBanner é um banner que não revela o software nem a versão
+const postfixBanner = "$myhostname ESMTP"
+
+// postfixDefaults são os valores padrão dos parâmetros verificados, usados quando não estão no main.cf
+var postfixDefaults = map[string]string{
+	"inet_interfaces":               "all",
+	"mynetworks_style":              "host",
+	"smtpd_banner":                  "$myhostname ESMTP $mail_name",
+	"smtpd_tls_protocols":           "!SSLv2, !SSLv3",
+	"smtpd_tls_mandatory_protocols": "!SSLv2, !SSLv3",
+	"disable_vrfy_command":          "no",
+}
+
+// postfixConfig é o main.cf lido, com as linhas de cada parâmetro
+type postfixConfig struct {
+	Path   string
+	Params map[string]option
+}
+
+// get retorna o valor de um parâmetro, ou o padrão quando não definido
+func (c *postfixConfig) get(name string) (string, bool) {
+	if param, ok := c.Params[name]; ok {
+		return param.Value, true
+	}
+	return postfixDefaults[name], false
+}
+
+// readPostfixConfig lê o main.cf. Linhas iniciadas por espaço continuam o parâmetro anterior,
+// e a última definição de um parâmetro prevalece.
+func readPostfixConfig(mountPoint, path string) (*postfixConfig, error) {
+	file, err := os.Open(util.JoinMount(mountPoint, path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	config := &postfixConfig{Path: path, Params: make(map[string]option)}
+	current := ""
+	number := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		number++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			if param, ok := config.Params[current]; ok {
+				param.Value = strings.TrimSpace(param.Value + " " + trimmed)
+				param.End = number
+				config.Params[current] = param
+			}
+			continue
+		}
+
+		parts := strings.SplitN(trimmed, "=", 2)
+		if len(parts) != 2 {
+			current = ""
+			continue
+		}
+		current = strings.TrimSpace(parts[0])
+		config.Params[current] = option{Value: strings.TrimSpace(parts[1]), Line: number, End: number}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// analyzePostfix verifica relay aberto, interfaces, TLS e o banner do Postfix
+func (a *Analyzer) analyzePostfix() ([]report.Issue, []change, error) {
+	config, err := readPostfixConfig(a.mountPoint, postfixConfigPath)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler configuração do Postfix: %w", err)
+	}
+
+	var issues []report.Issue
+	var changes []change
+
+	// add registra um problema; com fix, o parâmetro recebe o valor recomendado no main.cf
+	add := func(severity report.Severity, key, description, recommended string, fix bool) {
+		current, _ := config.get(key)
+		issue := report.Issue{
+			Category:         "mail",
+			Severity:         severity,
+			Key:              key,
+			Description:      description,
+			CurrentValue:     current,
+			RecommendedValue: recommended,
+			Source:           postfixConfigPath,
+		}
+		param, ok := config.Params[key]
+		if ok {
+			issue.Source = fmt.Sprintf("%s:%d", postfixConfigPath, param.Line)
+		}
+		if fix {
+			issue.FixCommand = a.postconfCommand(key, recommended)
+			c := change{Service: "postfix", File: postfixConfigPath, Text: key + " = " + recommended, Insert: !ok}
+			if ok {
+				c.Start, c.End = param.Line, param.End
+			}
+			changes = append(changes, c)
+		}
+		issues = append(issues, issue)
+	}
+
+	// Relay aberto: a mensagem só é repassada se as duas listas de restrições permitirem
+	relay, relaySet := config.get("smtpd_relay_restrictions")
+	recipient, _ := config.get("smtpd_recipient_restrictions")
+	if relaySet && permitsRelay(relay) && permitsRelay(recipient) {
+		add(report.SeverityCritical, "smtpd_relay_restrictions",
+			"Postfix é um relay aberto: smtpd_relay_restrictions e smtpd_recipient_restrictions não rejeitam destinos não autorizados",
+			postfixRelayRestrictions, true)
+	}
+
+	if networks, ok := config.get("mynetworks"); ok {
+		if network, prefix := widestNetwork(splitList(networks)); prefix == 0 {
+			add(report.SeverityCritical, "mynetworks",
+				fmt.Sprintf("mynetworks inclui %s: qualquer endereço pode usar o servidor como relay", network),
+				postfixLoopbackNetworks, true)
+		} else if prefix > 0 && prefix < minRelayPrefix {
+			add(report.SeverityWarning, "mynetworks",
+				fmt.Sprintf("mynetworks inclui a rede ampla %s, que pode usar o servidor como relay sem autenticação", network),
+				"apenas as redes que enviam email por este servidor", false)
+		}
+	} else if style, _ := config.get("mynetworks_style"); style == "subnet" || style == "class" {
+		add(report.SeverityWarning, "mynetworks_style",
+			fmt.Sprintf("mynetworks_style = %s permite relay sem autenticação de toda a rede local", style),
+			"host", true)
+	}
+
+	if interfaces, _ := config.get("inet_interfaces"); util.ContainsString(splitList(interfaces), "all") {
+		add(report.SeverityInfo, "inet_interfaces",
+			"Postfix aceita conexões SMTP em todas as interfaces; servidores que apenas enviam email devem usar loopback-only",
+			"loopback-only", false)
+	}
+
+	// TLS
+	level, _ := config.get("smtpd_tls_security_level")
+	useTLS, _ := config.get("smtpd_use_tls")
+	tlsEnabled := level == "may" || level == "encrypt" || level == "dane" || parseYes(useTLS)
+	if !tlsEnabled {
+		_, cert := config.Params["smtpd_tls_cert_file"]
+		_, chain := config.Params["smtpd_tls_chain_files"]
+		description := "Postfix não oferece STARTTLS: mensagens e credenciais trafegam em texto claro"
+		if !cert && !chain {
+			description += " (configure smtpd_tls_cert_file e smtpd_tls_key_file)"
+		}
+		add(report.SeverityWarning, "smtpd_tls_security_level", description, "may", cert || chain)
+
+		// Com um certificado configurado, a correção habilita o TLS, e as demais opções são corrigidas junto
+		tlsEnabled = cert || chain
+	}
+	if tlsEnabled {
+		for _, key := range []string{"smtpd_tls_protocols", "smtpd_tls_mandatory_protocols"} {
+			if protocols, _ := config.get(key); !excludesLegacyTLS(protocols) {
+				add(report.SeverityWarning, key,
+					fmt.Sprintf("%s permite protocolos obsoletos (SSLv3, TLSv1 ou TLSv1.1)", key),
+					postfixTLSProtocols, true)
+			}
+		}
+	}
+
+	if sasl, _ := config.get("smtpd_sasl_auth_enable"); parseYes(sasl) {
+		if authOnly, _ := config.get("smtpd_tls_auth_only"); !parseYes(authOnly) {
+			add(report.SeverityWarning, "smtpd_tls_auth_only",
+				"Autenticação SMTP é oferecida antes do STARTTLS: senhas podem trafegar em texto claro",
+				"yes", tlsEnabled)
+		}
+	}
+
+	// Banner
+	banner, _ := config.get("smtpd_banner")
+	switch {
+	case strings.Contains(banner, "mail_version"):
+		add(report.SeverityWarning, "smtpd_banner", "O banner SMTP revela a versão do Postfix", postfixBanner, true)
+	case strings.Contains(banner, "mail_name") || strings.Contains(strings.ToLower(banner), "postfix"):
+		add(report.SeverityInfo, "smtpd_banner", "O banner SMTP revela o software de email (Postfix)", postfixBanner, true)
+	}
+
+	if vrfy, _ := config.get("disable_vrfy_command"); !parseYes(vrfy) {
+		add(report.SeverityWarning, "disable_vrfy_command",
+			"O comando VRFY está habilitado e permite enumerar contas de email", "yes", true)
+	}
+
+	return issues, changes, nil
+}
+
+// postconfCommand gera o comando postconf que altera um parâmetro do main.cf
+func (a *Analyzer) postconfCommand(key, value string) string {
+	setting := shellQuote(key + " = " + value)
+	if a.mountPoint != "" {
+		return fmt.Sprintf("postconf -c %s -e %s", util.JoinMount(a.mountPoint, "/etc/postfix"), setting)
+	}
+	return fmt.Sprintf("postconf -e %s && postfix reload", setting)
+}
+
+// permitsRelay verifica se uma lista de restrições do Postfix deixa passar destinos não
+// autorizados. A avaliação para na primeira restrição que decide a mensagem; uma lista
+// vazia não rejeita nada.
+func permitsRelay(restrictions string) bool {
+	for _, restriction := range splitList(restrictions) {
+		switch restriction {
+		case "reject_unauth_destination", "defer_unauth_destination", "reject", "defer":
+			return false
+		case "permit":
+			return true
+		}
+	}
+	return true
+}
+
+// excludesLegacyTLS verifica se uma lista de protocolos do Postfix exclui SSLv3, TLSv1 e TLSv1.1.
+// A lista pode usar exclusões (!TLSv1), um mínimo (>=TLSv1.2) ou enumerar os protocolos permitidos.
+func excludesLegacyTLS(protocols string) bool {
+	excluded := make(map[string]bool)
+	allowed := make(map[string]bool)
+	for _, protocol := range splitList(protocols) {
+		switch {
+		case protocol == ">=TLSv1.2" || protocol == ">=TLSv1.3":
+			return true
+		case strings.HasPrefix(protocol, "!"):
+			excluded[protocol[1:]] = true
+		default:
+			allowed[protocol] = true
+		}
+	}
+
+	for _, legacy := range []string{"SSLv3", "TLSv1", "TLSv1.1"} {
+		if len(allowed) == 0 && !excluded[legacy] {
+			return false
+		}
+		if len(allowed) > 0 && allowed[legacy] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+func TestPermitsRelay(t *testing.T) {
+	tests := []struct {
+		restrictions string
+		want         bool
+	}{
+		{"", true},
+		{"permit_mynetworks, permit_sasl_authenticated, defer_unauth_destination", false},
+		{"permit_mynetworks reject_unauth_destination", false},
+		{"permit_mynetworks, permit", true},
+		{"permit_mynetworks, permit, reject_unauth_destination", true},
+		{"permit_sasl_authenticated", true},
+		{"reject", false},
+	}
+
+	for _, tt := range tests {
+		if got := permitsRelay(tt.restrictions); got != tt.want {
+			t.Errorf("permitsRelay(%q) = %v, esperado %v", tt.restrictions, got, tt.want)
+		}
+	}
+}
+
+func TestExcludesLegacyTLS(t *testing.T) {
+	tests := []struct {
+		protocols string
+		want      bool
+	}{
+		{"!SSLv2, !SSLv3", false},
+		{"!SSLv2, !SSLv3, !TLSv1, !TLSv1.1", true},
+		{">=TLSv1.2", true},
+		{">=TLSv1.3", true},
+		{"TLSv1.2 TLSv1.3", true},
+		{"TLSv1.1 TLSv1.2", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := excludesLegacyTLS(tt.protocols); got != tt.want {
+			t.Errorf("excludesLegacyTLS(%q) = %v, esperado %v", tt.protocols, got, tt.want)
+		}
+	}
+}
+
+func TestReadPostfixConfigContinuation(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		postfixConfigPath: "# comentário\n" +
+			"smtpd_relay_restrictions = permit_mynetworks,\n" +
+			"    permit_sasl_authenticated,\n" +
+			"\tdefer_unauth_destination\n" +
+			"mynetworks = 10.0.0.0/8\n" +
+			"mynetworks = 127.0.0.0/8\n",
+	})
+
+	config, err := readPostfixConfig(root, postfixConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	relay := config.Params["smtpd_relay_restrictions"]
+	if relay.Value != postfixRelayRestrictions || relay.Line != 2 || relay.End != 4 {
+		t.Errorf("smtpd_relay_restrictions = %+v, esperado %q nas linhas 2 a 4", relay, postfixRelayRestrictions)
+	}
+	if networks, _ := config.get("mynetworks"); networks != "127.0.0.0/8" {
+		t.Errorf("mynetworks = %q, esperado a última definição", networks)
+	}
+	if style, set := config.get("mynetworks_style"); set || style != "host" {
+		t.Errorf("mynetworks_style = %q, %v, esperado o padrão host", style, set)
+	}
+}
+
+func TestAnalyzePostfix(t *testing.T) {
+	tls := "smtpd_tls_security_level = may\n" +
+		"smtpd_tls_cert_file = /etc/ssl/certs/mail.pem\n"
+
+	tests := []struct {
+		name     string
+		config   string
+		key      string
+		severity report.Severity
+		found    bool
+	}{
+		{
+			name:     "relay aberto",
+			config:   "smtpd_relay_restrictions = permit_mynetworks, permit\n",
+			key:      "smtpd_relay_restrictions",
+			severity: report.SeverityCritical,
+			found:    true,
+		},
+		{
+			name:   "relay restrito por defer_unauth_destination",
+			config: "smtpd_relay_restrictions = " + postfixRelayRestrictions + "\n",
+			key:    "smtpd_relay_restrictions",
+		},
+		{
+			// O relay só é aberto se smtpd_recipient_restrictions também permitir
+			name: "relay restrito pelas restrições de destinatário",
+			config: "smtpd_relay_restrictions = permit\n" +
+				"smtpd_recipient_restrictions = permit_mynetworks,\n" +
+				"  reject_unauth_destination\n",
+			key: "smtpd_relay_restrictions",
+		},
+		{
+			name:     "mynetworks com qualquer origem",
+			config:   "mynetworks = 127.0.0.0/8\n  0.0.0.0/0\n",
+			key:      "mynetworks",
+			severity: report.SeverityCritical,
+			found:    true,
+		},
+		{
+			name:     "mynetworks com rede ampla",
+			config:   "mynetworks = 127.0.0.0/8, 10.0.0.0/8\n",
+			key:      "mynetworks",
+			severity: report.SeverityWarning,
+			found:    true,
+		},
+		{
+			name:   "mynetworks apenas loopback",
+			config: "mynetworks = " + postfixLoopbackNetworks + "\n",
+			key:    "mynetworks",
+		},
+		{
+			name:     "protocolos TLS obsoletos",
+			config:   tls,
+			key:      "smtpd_tls_protocols",
+			severity: report.SeverityWarning,
+			found:    true,
+		},
+		{
+			name:   "protocolos TLS com mínimo",
+			config: tls + "smtpd_tls_protocols = >=TLSv1.2\n",
+			key:    "smtpd_tls_protocols",
+		},
+		{
+			name:     "banner com a versão",
+			config:   "smtpd_banner = $myhostname ESMTP $mail_name ($mail_version)\n",
+			key:      "smtpd_banner",
+			severity: report.SeverityWarning,
+			found:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		root := writeTree(t, map[string]string{postfixConfigPath: tt.config})
+		issues, _, err := NewAnalyzer(root).analyzePostfix()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		issue, found := findIssue(issues, tt.key)
+		if found != tt.found {
+			t.Errorf("%s: problema em %s = %v, esperado %v", tt.name, tt.key, found, tt.found)
+			continue
+		}
+		if found && issue.Severity != tt.severity {
+			t.Errorf("%s: severidade = %s, esperado %s", tt.name, issue.Severity, tt.severity)
+		}
+	}
+}
+
+func TestAnalyzePostfixChanges(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		postfixConfigPath: "mynetworks = 127.0.0.0/8,\n    0.0.0.0/0\n",
+	})
+
+	issues, changes, err := NewAnalyzer(root).analyzePostfix()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue, _ := findIssue(issues, "mynetworks")
+	if issue.Source != postfixConfigPath+":1" || !strings.Contains(issue.FixCommand, "postconf -c") {
+		t.Errorf("mynetworks: Source = %q, FixCommand = %q", issue.Source, issue.FixCommand)
+	}
+
+	// A correção substitui a definição inteira, incluindo a linha de continuação
+	for _, c := range changes {
+		if strings.HasPrefix(c.Text, "mynetworks") && (c.Start != 1 || c.End != 2 || c.Insert) {
+			t.Errorf("alteração de mynetworks = %+v, esperado as linhas 1 a 2", c)
+		}
+	}
+}
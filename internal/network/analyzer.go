@@ -0,0 +1,258 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/rules"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// Analyzer é o analisador das portas em escuta
+type Analyzer struct {
+	mountPoint string
+	allow      []rules.ListenerRule
+}
+
+// cleartextService é um protocolo que trafega dados ou credenciais sem criptografia
+type cleartextService struct {
+	Name     string
+	Severity report.Severity
+}
+
+// cleartextServices são os protocolos em texto claro conhecidos, por protocolo/porta
+var cleartextServices = map[string]cleartextService{
+	"tcp/21":   {Name: "FTP", Severity: report.SeverityCritical},
+	"tcp/23":   {Name: "Telnet", Severity: report.SeverityCritical},
+	"udp/69":   {Name: "TFTP", Severity: report.SeverityCritical},
+	"tcp/512":  {Name: "rexec", Severity: report.SeverityCritical},
+	"tcp/513":  {Name: "rlogin", Severity: report.SeverityCritical},
+	"tcp/514":  {Name: "rsh", Severity: report.SeverityCritical},
+	"tcp/79":   {Name: "Finger", Severity: report.SeverityWarning},
+	"tcp/80":   {Name: "HTTP", Severity: report.SeverityWarning},
+	"tcp/110":  {Name: "POP3", Severity: report.SeverityWarning},
+	"tcp/143":  {Name: "IMAP", Severity: report.SeverityWarning},
+	"udp/161":  {Name: "SNMP", Severity: report.SeverityWarning},
+	"tcp/389":  {Name: "LDAP", Severity: report.SeverityWarning},
+	"udp/514":  {Name: "syslog", Severity: report.SeverityWarning},
+	"tcp/873":  {Name: "rsync", Severity: report.SeverityWarning},
+	"tcp/5900": {Name: "VNC", Severity: report.SeverityWarning},
+	"tcp/6000": {Name: "X11", Severity: report.SeverityWarning},
+}
+
+// exposure agrupa os sockets de uma mesma porta e processo, como os de IPv4 e IPv6
+type exposure struct {
+	Protocol  string
+	Port      int
+	Addresses []string
+	Processes []process
+}
+
+// NewAnalyzer cria um novo analisador de portas em escuta
+func NewAnalyzer(mountPoint string) *Analyzer {
+	return &Analyzer{
+		mountPoint: mountPoint,
+	}
+}
+
+// SetRules aplica a lista de portas e processos esperados do arquivo de regras
+func (a *Analyzer) SetRules(custom rules.NetworkRules) {
+	a.allow = custom.Allow
+}
+
+// Analyze lista os sockets em escuta em endereços que não são de loopback
+func (a *Analyzer) Analyze() ([]report.Issue, error) {
+	// As tabelas de sockets são do kernel em execução, e não do sistema em mountPoint
+	if a.mountPoint != "" {
+		return []report.Issue{{
+			Category:    "network",
+			Severity:    report.SeveritySkip,
+			Key:         "listeners",
+			Description: "Portas em escuta não podem ser verificadas em um sistema de arquivos montado",
+		}}, nil
+	}
+
+	listeners, err := readListeners()
+	if err != nil {
+		return nil, err
+	}
+	owners := socketOwners()
+
+	var issues []report.Issue
+	for _, exp := range a.exposures(listeners, owners) {
+		issues = append(issues, exp.issue())
+	}
+	return issues, nil
+}
+
+// exposures agrupa os sockets expostos por protocolo, porta e processos, descartando
+// os de loopback e os permitidos pelo arquivo de regras
+func (a *Analyzer) exposures(listeners []listener, owners map[string][]process) []*exposure {
+	grouped := make(map[string]*exposure)
+	var order []string
+
+	for _, l := range listeners {
+		if isLoopback(l.Address) {
+			continue
+		}
+
+		procs := owners[l.Inode]
+		if a.allowed(l, procs) {
+			continue
+		}
+
+		id := fmt.Sprintf("%s/%d/%s", l.Protocol, l.Port, processNames(procs))
+		exp, ok := grouped[id]
+		if !ok {
+			exp = &exposure{Protocol: l.Protocol, Port: l.Port, Processes: procs}
+			grouped[id] = exp
+			order = append(order, id)
+		}
+		exp.Addresses = util.AppendUnique(exp.Addresses, formatAddress(l.Address))
+	}
+
+	exposures := make([]*exposure, 0, len(order))
+	for _, id := range order {
+		exposures = append(exposures, grouped[id])
+	}
+	sort.SliceStable(exposures, func(i, j int) bool {
+		if exposures[i].Protocol != exposures[j].Protocol {
+			return exposures[i].Protocol < exposures[j].Protocol
+		}
+		return exposures[i].Port < exposures[j].Port
+	})
+	return exposures
+}
+
+// allowed verifica se o socket está na lista de permitidos. Uma regra com processo
+// vale se qualquer um dos processos donos do socket tiver o nome informado.
+func (a *Analyzer) allowed(l listener, procs []process) bool {
+	for _, rule := range a.allow {
+		if len(procs) == 0 && rule.Matches(l.Protocol, l.Port, "") {
+			return true
+		}
+		for _, proc := range procs {
+			if rule.Matches(l.Protocol, l.Port, proc.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// issue gera o problema de um socket exposto. Protocolos em texto claro conhecidos
+// recebem a severidade do protocolo; os demais são informativos.
+func (e *exposure) issue() report.Issue {
+	key := fmt.Sprintf("%s/%d", e.Protocol, e.Port)
+	owner := processNames(e.Processes)
+
+	severity := report.SeverityInfo
+	description := fmt.Sprintf("%s em escuta em %s", owner, strings.Join(e.Addresses, ", "))
+	recommended := "Restringir a loopback, a uma interface interna ou adicionar à lista de permitidos"
+	if service, ok := cleartextServices[key]; ok {
+		severity = service.Severity
+		description = fmt.Sprintf("%s (%s, texto claro) em escuta em %s", owner, service.Name, strings.Join(e.Addresses, ", "))
+		recommended = fmt.Sprintf("Desabilitar o %s ou substituí-lo por um protocolo criptografado", service.Name)
+	}
+
+	return report.Issue{
+		Category:         "network",
+		Severity:         severity,
+		Key:              key,
+		Description:      description,
+		CurrentValue:     strings.Join(e.Addresses, ", "),
+		RecommendedValue: recommended,
+		Source:           processSource(e.Processes),
+		FixCommand:       e.fixCommand(),
+	}
+}
+
+// fixCommand sugere parar a unit do systemd dona do socket. Instâncias de template
+// (ex: telnet@0-...service, criadas por sockets com Accept=yes) não são paradas diretamente.
+func (e *exposure) fixCommand() string {
+	var units []string
+	for _, proc := range e.Processes {
+		if proc.Unit != "" && !strings.Contains(proc.Unit, "@") {
+			units = util.AppendUnique(units, proc.Unit)
+		}
+	}
+	if len(units) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("systemctl disable --now %s", strings.Join(units, " "))
+}
+
+// processNames descreve os processos donos de um socket
+func processNames(procs []process) string {
+	if len(procs) == 0 {
+		return "processo desconhecido"
+	}
+
+	var names []string
+	for _, proc := range procs {
+		name := proc.Name
+		if name == "" {
+			name = "?"
+		}
+		names = util.AppendUnique(names, name)
+	}
+	return strings.Join(names, ",")
+}
+
+// processSource informa os PIDs e as units dos processos donos do socket
+func processSource(procs []process) string {
+	var parts []string
+	for _, proc := range procs {
+		part := fmt.Sprintf("pid %d", proc.PID)
+		if proc.Unit != "" {
+			part += " (" + proc.Unit + ")"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatAddress formata o endereço de escuta, com colchetes nos endereços IPv6
+func formatAddress(address net.IP) string {
+	switch {
+	case address.Equal(net.IPv4zero):
+		return "0.0.0.0"
+	case address.Equal(net.IPv6unspecified):
+		return "[::]"
+	case address.To4() != nil:
+		return address.String()
+	}
+	return "[" + address.String() + "]"
+}
+
+// Fix exibe as correções para os sockets expostos
+func (a *Analyzer) Fix() error {
+	issues, err := a.Analyze()
+	if err != nil {
+		return err
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("Nenhuma porta exposta encontrada.")
+		return nil
+	}
+
+	for _, issue := range issues {
+		if issue.FixCommand == "" {
+			if issue.Severity != report.SeveritySkip {
+				fmt.Printf("Revise manualmente %s: %s\n", issue.Key, issue.Description)
+			}
+			continue
+		}
+
+		fmt.Printf("Aplicando correção: %s\n", issue.FixCommand)
+
+		// Parar serviços pode interromper o acesso remoto; por enquanto, apenas simula a execução
+		fmt.Printf("  [Simulando] %s\n", issue.FixCommand)
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/rules"
+)
+
+func TestExposures(t *testing.T) {
+	listeners := []listener{
+		{Protocol: "tcp", Address: net.ParseIP("127.0.0.1"), Port: 631, Inode: "1"},
+		{Protocol: "tcp", Address: net.ParseIP("::ffff:127.0.0.1"), Port: 25, Inode: "2"},
+		{Protocol: "tcp", Address: net.ParseIP("0.0.0.0"), Port: 23, Inode: "3"},
+		{Protocol: "tcp", Address: net.ParseIP("::"), Port: 23, Inode: "4"},
+		{Protocol: "tcp", Address: net.ParseIP("0.0.0.0"), Port: 22, Inode: "5"},
+		{Protocol: "udp", Address: net.ParseIP("0.0.0.0"), Port: 68, Inode: "6"},
+	}
+	telnet := []process{{PID: 300, Name: "in.telnetd", Unit: "inetd.service"}}
+	owners := map[string][]process{
+		"3": telnet,
+		"4": telnet,
+		"5": {{PID: 200, Name: "sshd", Unit: "ssh.service"}},
+		"6": {{PID: 100, Name: "dhclient", Unit: "networking.service"}},
+	}
+
+	a := NewAnalyzer("")
+	a.SetRules(rules.NetworkRules{Allow: []rules.ListenerRule{{Port: 22, Process: "sshd"}}})
+	exposures := a.exposures(listeners, owners)
+
+	// Os sockets de loopback e os permitidos são descartados; IPv4 e IPv6 do mesmo processo são agrupados
+	if len(exposures) != 2 {
+		t.Fatalf("exposições = %d, esperado 2", len(exposures))
+	}
+
+	issue := exposures[0].issue()
+	if issue.Key != "tcp/23" || issue.Severity != report.SeverityCritical || issue.CurrentValue != "0.0.0.0, [::]" {
+		t.Errorf("tcp/23 = %+v", issue)
+	}
+	if issue.FixCommand != "systemctl disable --now inetd.service" {
+		t.Errorf("FixCommand = %q", issue.FixCommand)
+	}
+	if issue := exposures[1].issue(); issue.Key != "udp/68" || issue.Severity != report.SeverityInfo {
+		t.Errorf("udp/68 = %+v", issue)
+	}
+}
+
+func TestFixCommandSkipsTemplateInstances(t *testing.T) {
+	exp := &exposure{Protocol: "tcp", Port: 23, Processes: []process{{PID: 1, Name: "in.telnetd", Unit: "telnet@0-10.0.0.1:23-10.0.0.2:5555.service"}}}
+	if command := exp.fixCommand(); command != "" {
+		t.Errorf("fixCommand() = %q, esperado vazio para instância de template", command)
+	}
+}
+
+func TestAnalyzeMountPoint(t *testing.T) {
+	issues, err := NewAnalyzer("/mnt/image").Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Severity != report.SeveritySkip {
+		t.Errorf("problemas = %+v, esperado apenas SKIP", issues)
+	}
+}
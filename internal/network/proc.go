@@ -0,0 +1,241 @@
+package network
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procPath é onde o kernel expõe as tabelas de sockets e os processos
+const procPath = "/proc"
+
+// Estados dos sockets em /proc/net (include/net/tcp_states.h)
+const (
+	// stateListen é um socket TCP aguardando conexões
+	stateListen = "0A"
+
+	// stateUnconnected é um socket UDP sem destino fixo, que recebe de qualquer origem
+	stateUnconnected = "07"
+)
+
+// socketTable é uma tabela de sockets em /proc/net
+type socketTable struct {
+	File     string
+	Protocol string
+	State    string
+}
+
+// socketTables são as tabelas lidas, com o estado que indica um socket em escuta
+var socketTables = []socketTable{
+	{File: "net/tcp", Protocol: "tcp", State: stateListen},
+	{File: "net/tcp6", Protocol: "tcp", State: stateListen},
+	{File: "net/udp", Protocol: "udp", State: stateUnconnected},
+	{File: "net/udp6", Protocol: "udp", State: stateUnconnected},
+}
+
+// listener é um socket em escuta
+type listener struct {
+	Protocol string
+	Address  net.IP
+	Port     int
+	Inode    string
+}
+
+// process é um processo dono de um socket
+type process struct {
+	PID  int
+	Name string
+	Unit string
+}
+
+// readListeners lê os sockets em escuta das tabelas de /proc/net
+func readListeners() ([]listener, error) {
+	var listeners []listener
+	found := false
+
+	for _, table := range socketTables {
+		entries, err := readSocketTable(filepath.Join(procPath, table.File), table)
+		if os.IsNotExist(err) {
+			// Sem suporte a IPv6 no kernel, tcp6 e udp6 não existem
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found = true
+		listeners = append(listeners, entries...)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("nenhuma tabela de sockets encontrada em %s/net", procPath)
+	}
+	return listeners, nil
+}
+
+// readSocketTable lê uma tabela de /proc/net no formato:
+// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+func readSocketTable(path string, table socketTable) ([]listener, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var listeners []listener
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // cabeçalho
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != table.State {
+			continue
+		}
+
+		address, port, err := parseAddress(fields[1])
+		if err != nil {
+			continue
+		}
+
+		// Um socket UDP conectado a um destino não recebe de qualquer origem
+		if table.Protocol == "udp" {
+			if _, remotePort, err := parseAddress(fields[2]); err != nil || remotePort != 0 {
+				continue
+			}
+		}
+
+		listeners = append(listeners, listener{
+			Protocol: table.Protocol,
+			Address:  address,
+			Port:     port,
+			Inode:    fields[9],
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+	return listeners, nil
+}
+
+// parseAddress decodifica um endereço "IP:porta" de /proc/net. O IP é gravado em
+// hexadecimal em palavras de 32 bits na ordem de bytes do host (little-endian) e a porta,
+// em hexadecimal na ordem natural.
+func parseAddress(value string) (net.IP, int, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return nil, 0, fmt.Errorf("endereço inválido: %s", value)
+	}
+
+	raw, err := hex.DecodeString(parts[0])
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("endereço inválido: %s", value)
+	}
+	for i := 0; i < len(raw); i += 4 {
+		raw[i], raw[i+1], raw[i+2], raw[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("porta inválida: %s", value)
+	}
+
+	return net.IP(raw), int(port), nil
+}
+
+// socketOwners associa o inode de cada socket aos processos que o mantêm aberto, a partir
+// dos links "socket:[inode]" em /proc/<pid>/fd. Sem privilégios, apenas os processos do
+// próprio usuário são visíveis.
+func socketOwners() map[string][]process {
+	owners := make(map[string][]process)
+
+	entries, err := os.ReadDir(procPath)
+	if err != nil {
+		return owners
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || !entry.IsDir() {
+			continue
+		}
+
+		fdDir := filepath.Join(procPath, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+
+		var proc *process
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(target, "socket:[") {
+				continue
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]")
+
+			if proc == nil {
+				proc = &process{PID: pid, Name: processName(pid), Unit: processUnit(pid)}
+			}
+			owners[inode] = appendProcess(owners[inode], *proc)
+		}
+	}
+
+	return owners
+}
+
+// appendProcess adiciona um processo à lista, sem repetir o mesmo PID
+func appendProcess(list []process, proc process) []process {
+	for _, existing := range list {
+		if existing.PID == proc.PID {
+			return list
+		}
+	}
+	return append(list, proc)
+}
+
+// processName lê o nome do processo em /proc/<pid>/comm
+func processName(pid int) string {
+	data, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "comm"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// processUnit identifica a unit do systemd do processo pelo seu cgroup
+func processUnit(pid int) string {
+	data, err := os.ReadFile(filepath.Join(procPath, strconv.Itoa(pid), "cgroup"))
+	if err != nil {
+		return ""
+	}
+	return cgroupUnit(string(data))
+}
+
+// cgroupUnit extrai a unit do systemd de um /proc/<pid>/cgroup
+// (ex: 0::/system.slice/vsftpd.service -> vsftpd.service)
+func cgroupUnit(data string) string {
+	for _, line := range strings.Split(data, "\n") {
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 || !strings.Contains(parts[2], ".slice/") {
+			continue
+		}
+		unit := filepath.Base(parts[2])
+		if strings.HasSuffix(unit, ".service") || strings.HasSuffix(unit, ".socket") {
+			return unit
+		}
+	}
+	return ""
+}
+
+// isLoopback verifica se o endereço só aceita conexões da própria máquina,
+// incluindo endereços IPv4 de loopback mapeados em IPv6 (::ffff:127.0.0.1)
+func isLoopback(address net.IP) bool {
+	if v4 := address.To4(); v4 != nil {
+		return v4.IsLoopback()
+	}
+	return address.IsLoopback()
+}
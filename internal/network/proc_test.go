@@ -0,0 +1,143 @@
+package network
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// socketHeader é o cabeçalho das tabelas de /proc/net
+const socketHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+// writeSocketTable grava uma tabela de sockets no formato de /proc/net
+func writeSocketTable(t *testing.T, lines string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "table")
+	if err := os.WriteFile(path, []byte(socketHeader+lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		value   string
+		address string
+		port    int
+	}{
+		{"0100007F:0016", "127.0.0.1", 22},
+		{"00000000:0050", "0.0.0.0", 80},
+		{"0101A8C0:01BB", "192.168.1.1", 443},
+		{"00000000000000000000000000000000:0016", "::", 22},
+		{"00000000000000000000000001000000:0277", "::1", 631},
+		{"0000000000000000FFFF00000100007F:0019", "127.0.0.1", 25},
+		{"000080FE00000000FF0054509FCBA6FE:0222", "fe80::5054:ff:fea6:cb9f", 546},
+	}
+
+	for _, tt := range tests {
+		address, port, err := parseAddress(tt.value)
+		if err != nil {
+			t.Errorf("parseAddress(%q): %v", tt.value, err)
+			continue
+		}
+		if !address.Equal(net.ParseIP(tt.address)) || port != tt.port {
+			t.Errorf("parseAddress(%q) = %s:%d, esperado %s:%d", tt.value, address, port, tt.address, tt.port)
+		}
+	}
+
+	for _, value := range []string{"0100007F", "0100007:0016", "01000000FF:0016", "0100007F:XYZ", "0100007F:10000"} {
+		if _, _, err := parseAddress(value); err == nil {
+			t.Errorf("parseAddress(%q) deveria falhar", value)
+		}
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{"127.0.0.1", true},
+		{"127.0.0.53", true},
+		{"::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"::ffff:0.0.0.0", false},
+		{"192.168.1.10", false},
+	}
+
+	for _, tt := range tests {
+		if got := isLoopback(net.ParseIP(tt.address)); got != tt.want {
+			t.Errorf("isLoopback(%s) = %v, esperado %v", tt.address, got, tt.want)
+		}
+	}
+}
+
+func TestReadSocketTableTCP(t *testing.T) {
+	path := writeSocketTable(t,
+		"   0: 0100007F:0277 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0\n"+
+			"   1: 00000000:0016 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1002 1 0000000000000000 100 0 0 10 0\n"+
+			"   2: 0F02000A:0016 0202000A:D1C2 01 00000000:00000000 02:0009C2D7 00000000     0        0 1003 4 0000000000000000 20 4 31 10 -1\n"+
+			"   3: invalid\n")
+
+	listeners, err := readSocketTable(path, socketTable{Protocol: "tcp", State: stateListen})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A conexão estabelecida (estado 01) e a linha inválida são ignoradas
+	if len(listeners) != 2 {
+		t.Fatalf("listeners = %+v, esperado 2", listeners)
+	}
+	if l := listeners[1]; l.Protocol != "tcp" || !l.Address.Equal(net.IPv4zero) || l.Port != 22 || l.Inode != "1002" {
+		t.Errorf("listener = %+v, esperado tcp 0.0.0.0:22 inode 1002", l)
+	}
+}
+
+func TestReadSocketTableUDP(t *testing.T) {
+	path := writeSocketTable(t,
+		"  123: 00000000:0044 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 2001 2 0000000000000000 0\n"+
+			"  456: 0F02000A:A1B2 08080808:0035 07 00000000:00000000 00:00000000 00000000  1000        0 2002 2 0000000000000000 0\n"+
+			"  789: 0F02000A:A1B3 08080808:0035 01 00000000:00000000 00:00000000 00000000  1000        0 2003 2 0000000000000000 0\n")
+
+	listeners, err := readSocketTable(path, socketTable{Protocol: "udp", State: stateUnconnected})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Sockets UDP conectados a um destino, mesmo no estado 07, não recebem de qualquer origem
+	if len(listeners) != 1 || listeners[0].Port != 68 || listeners[0].Inode != "2001" {
+		t.Errorf("listeners = %+v, esperado apenas udp 0.0.0.0:68", listeners)
+	}
+}
+
+func TestReadSocketTableMissing(t *testing.T) {
+	_, err := readSocketTable(filepath.Join(t.TempDir(), "tcp6"), socketTable{Protocol: "tcp", State: stateListen})
+	if !os.IsNotExist(err) {
+		t.Errorf("erro = %v, esperado arquivo inexistente", err)
+	}
+}
+
+func TestCgroupUnit(t *testing.T) {
+	tests := []struct {
+		cgroup string
+		want   string
+	}{
+		{"0::/system.slice/vsftpd.service\n", "vsftpd.service"},
+		{"0::/system.slice/system-getty.slice/getty@tty1.service\n", "getty@tty1.service"},
+		{"12:pids:/system.slice/cups.socket\n1:name=systemd:/system.slice/cups.socket\n", "cups.socket"},
+		{"0::/user.slice/user-1000.slice/session-2.scope\n", ""},
+		{"0::/init.scope\n", ""},
+		{"0::/\n", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := cgroupUnit(tt.cgroup); got != tt.want {
+			t.Errorf("cgroupUnit(%q) = %q, esperado %q", tt.cgroup, got, tt.want)
+		}
+	}
+}
@@ -53,10 +53,18 @@ func (g *Generator) generateText(issues []Issue) (string, error) {
 				sb.WriteString(fmt.Sprintf("   Valor atual: %s\n", issue.CurrentValue))
 			}
 
+			if issue.Source != "" {
+				sb.WriteString(fmt.Sprintf("   Definido em: %s\n", issue.Source))
+			}
+
 			if issue.RecommendedValue != "" {
 				sb.WriteString(fmt.Sprintf("   Valor recomendado: %s\n", issue.RecommendedValue))
 			}
 
+			if issue.Expected != "" {
+				sb.WriteString(fmt.Sprintf("   Esperado: %s\n", issue.Expected))
+			}
+
 			if issue.FixCommand != "" {
 				sb.WriteString(fmt.Sprintf("   Correção: %s\n", issue.FixCommand))
 			}
@@ -78,6 +86,7 @@ func (g *Generator) generateJSON(issues []Issue) (string, error) {
 			Critical int `json:"critical"`
 			Warning  int `json:"warning"`
 			Info     int `json:"info"`
+			Skipped  int `json:"skipped"`
 			Total    int `json:"total"`
 		} `json:"summary"`
 	}
@@ -95,10 +104,13 @@ func (g *Generator) generateJSON(issues []Issue) (string, error) {
 			report.Summary.Warning++
 		case SeverityInfo:
 			report.Summary.Info++
+		case SeveritySkip:
+			report.Summary.Skipped++
 		}
 	}
 
-	report.Summary.Total = len(issues)
+	// Verificações ignoradas não são problemas
+	report.Summary.Total = len(issues) - report.Summary.Skipped
 
 	// Serializa para JSON
 	jsonData, err := json.MarshalIndent(report, "", "  ")
@@ -150,6 +162,9 @@ func (g *Generator) generateHTML(issues []Issue) (string, error) {
         .INFO {
             border-left-color: #3399ff;
         }
+        .SKIP {
+            border-left-color: #777;
+        }
         .severity {
             font-weight: bold;
             padding: 2px 8px;
@@ -168,6 +183,10 @@ func (g *Generator) generateHTML(issues []Issue) (string, error) {
             background-color: #3399ff;
             color: white;
         }
+        .severity.SKIP {
+            background-color: #777;
+            color: white;
+        }
         .summary {
             display: flex;
             margin: 20px 0;
@@ -210,7 +229,7 @@ func (g *Generator) generateHTML(issues []Issue) (string, error) {
 `)
 
 	// Resumo
-	var critical, warning, info int
+	var critical, warning, info, skipped int
 	for _, issue := range issues {
 		switch issue.Severity {
 		case SeverityCritical:
@@ -219,6 +238,8 @@ func (g *Generator) generateHTML(issues []Issue) (string, error) {
 			warning++
 		case SeverityInfo:
 			info++
+		case SeveritySkip:
+			skipped++
 		}
 	}
 
@@ -243,7 +264,7 @@ func (g *Generator) generateHTML(issues []Issue) (string, error) {
         </div>
         <div class="summary-item total">
             <div class="summary-number">`)
-	sb.WriteString(fmt.Sprintf("%d", len(issues)))
+	sb.WriteString(fmt.Sprintf("%d", len(issues)-skipped))
 	sb.WriteString(`</div>
             <div>Total</div>
         </div>
@@ -270,10 +291,18 @@ func (g *Generator) generateHTML(issues []Issue) (string, error) {
 				sb.WriteString(fmt.Sprintf("        <p>Valor atual: <code>%s</code></p>\n", issue.CurrentValue))
 			}
 
+			if issue.Source != "" {
+				sb.WriteString(fmt.Sprintf("        <p>Definido em: <code>%s</code></p>\n", issue.Source))
+			}
+
 			if issue.RecommendedValue != "" {
 				sb.WriteString(fmt.Sprintf("        <p>Valor recomendado: <code>%s</code></p>\n", issue.RecommendedValue))
 			}
 
+			if issue.Expected != "" {
+				sb.WriteString(fmt.Sprintf("        <p>Esperado: %s</p>\n", issue.Expected))
+			}
+
 			if issue.FixCommand != "" {
 				sb.WriteString(fmt.Sprintf("        <p>Correção:</p>\n        <pre class=\"fix\">%s</pre>\n", issue.FixCommand))
 			}
@@ -12,6 +12,9 @@ const (
 
 	// SeverityInfo indica uma informação ou sugestão de melhoria
 	SeverityInfo Severity = "INFO"
+
+	// SeveritySkip indica uma verificação que não se aplica ao sistema analisado (ex: parâmetro inexistente no kernel)
+	SeveritySkip Severity = "SKIP"
 )
 
 // Issue representa um problema de segurança encontrado durante a análise
@@ -22,6 +25,9 @@ type Issue struct {
 	// Severity é o nível de severidade do problema
 	Severity Severity
 
+	// Key é a chave de configuração relacionada ao problema, quando houver (ex: PermitRootLogin)
+	Key string
+
 	// Description é a descrição do problema
 	Description string
 
@@ -31,6 +37,12 @@ type Issue struct {
 	// RecommendedValue é o valor recomendado para a configuração
 	RecommendedValue string
 
+	// Source é o arquivo e a linha que definem o valor atual (ex: /etc/sysctl.d/10-net.conf:3), quando conhecidos
+	Source string
+
+	// Expected descreve os valores aceitos pela regra (ex: "no máximo 4"), quando houver
+	Expected string
+
 	// FixCommand é o comando ou configuração necessária para corrigir o problema
 	FixCommand string
 }
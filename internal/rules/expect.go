@@ -0,0 +1,376 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Operadores de comparação suportados pelas expectativas
+const (
+	// OpEqual exige que o valor seja igual ao esperado (sem diferenciar maiúsculas)
+	OpEqual = "eq"
+
+	// OpOneOf exige que o valor seja um dos valores listados
+	OpOneOf = "one-of"
+
+	// OpMin exige um número maior ou igual ao esperado
+	OpMin = "min"
+
+	// OpMax exige um número menor ou igual ao esperado
+	OpMax = "max"
+
+	// OpRange exige um número entre Min e Max, inclusive
+	OpRange = "range"
+
+	// OpBitmask exige que os bits de Set estejam ativos e os bits de Clear desativados
+	OpBitmask = "bitmask"
+
+	// OpContains exige que a lista contenha todos os itens esperados
+	OpContains = "contains"
+
+	// OpExcludes exige que a lista não contenha nenhum dos itens listados
+	OpExcludes = "excludes"
+
+	// OpRegex exige que o valor case com a expressão regular
+	OpRegex = "regex"
+
+	// OpAll exige que todas as expectativas de All sejam atendidas
+	OpAll = "all"
+)
+
+// Expectation descreve de forma declarativa o valor aceito para uma configuração.
+// Quando Value, Values ou Min/Max não são informados, o valor recomendado da regra é usado.
+type Expectation struct {
+	Op      string        `yaml:"op"`
+	Value   string        `yaml:"value"`
+	Values  []string      `yaml:"values"`
+	Min     string        `yaml:"min"`
+	Max     string        `yaml:"max"`
+	Set     string        `yaml:"set"`
+	Clear   string        `yaml:"clear"`
+	Pattern string        `yaml:"pattern"`
+	All     []Expectation `yaml:"all"`
+
+	// Field seleciona um campo (a partir de 1) de um valor separado por ":" (ex: MaxStartups 10:30:60).
+	// Quando o valor tem menos campos, o último é usado.
+	Field int `yaml:"field"`
+
+	// Duration interpreta os números no formato de tempo do OpenSSH (ex: 90, 1m30s, 2h)
+	Duration bool `yaml:"duration"`
+}
+
+// Equal cria uma expectativa de igualdade com o valor recomendado
+func Equal() Expectation {
+	return Expectation{Op: OpEqual}
+}
+
+// OneOf cria uma expectativa que aceita qualquer um dos valores
+func OneOf(values ...string) Expectation {
+	return Expectation{Op: OpOneOf, Values: values}
+}
+
+// AtLeast cria uma expectativa numérica de valor mínimo (vazio usa o valor recomendado)
+func AtLeast(min string) Expectation {
+	return Expectation{Op: OpMin, Min: min}
+}
+
+// AtMost cria uma expectativa numérica de valor máximo (vazio usa o valor recomendado)
+func AtMost(max string) Expectation {
+	return Expectation{Op: OpMax, Max: max}
+}
+
+// Between cria uma expectativa numérica de intervalo
+func Between(min, max string) Expectation {
+	return Expectation{Op: OpRange, Min: min, Max: max}
+}
+
+// Contains cria uma expectativa de lista que deve conter os itens (vazio usa o valor recomendado)
+func Contains(values ...string) Expectation {
+	return Expectation{Op: OpContains, Values: values}
+}
+
+// Excludes cria uma expectativa de lista que não pode conter os itens
+func Excludes(values ...string) Expectation {
+	return Expectation{Op: OpExcludes, Values: values}
+}
+
+// AllOf cria uma expectativa composta
+func AllOf(expectations ...Expectation) Expectation {
+	return Expectation{Op: OpAll, All: expectations}
+}
+
+// Match verifica se o valor atual atende à expectativa
+func (e Expectation) Match(actual, recommended string) bool {
+	actual = strings.TrimSpace(e.field(actual))
+
+	switch e.op() {
+	case OpEqual:
+		return strings.EqualFold(actual, e.value(recommended))
+	case OpOneOf:
+		for _, value := range e.values(recommended) {
+			if strings.EqualFold(actual, value) {
+				return true
+			}
+		}
+		return false
+	case OpMin, OpMax, OpRange:
+		current, ok := e.number(actual)
+		if !ok {
+			return false
+		}
+		min, max := e.bounds(recommended)
+		if min != "" {
+			limit, ok := e.number(min)
+			if !ok || current < limit {
+				return false
+			}
+		}
+		if max != "" {
+			limit, ok := e.number(max)
+			if !ok || current > limit {
+				return false
+			}
+		}
+		return true
+	case OpBitmask:
+		current, err := strconv.ParseInt(actual, 0, 64)
+		if err != nil {
+			return false
+		}
+		set, _ := parseMask(e.Set)
+		clear, _ := parseMask(e.Clear)
+		return current&set == set && current&clear == 0
+	case OpContains:
+		items := listItems(actual)
+		for _, value := range e.values(recommended) {
+			if !items[strings.ToLower(value)] {
+				return false
+			}
+		}
+		return actual != ""
+	case OpExcludes:
+		items := listItems(actual)
+		for _, value := range e.values(recommended) {
+			if items[strings.ToLower(value)] {
+				return false
+			}
+		}
+		return true
+	case OpRegex:
+		re, err := regexp.Compile(e.Pattern)
+		return err == nil && re.MatchString(actual)
+	case OpAll:
+		for _, expectation := range e.All {
+			if !expectation.Match(actual, recommended) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// Describe retorna uma descrição legível do valor aceito, usada nos relatórios
+func (e Expectation) Describe(recommended string) string {
+	prefix := ""
+	if e.Field > 0 {
+		prefix = fmt.Sprintf("campo %d ", e.Field)
+	}
+
+	switch e.op() {
+	case OpEqual:
+		return prefix + "igual a " + e.value(recommended)
+	case OpOneOf:
+		return prefix + "um de: " + strings.Join(e.values(recommended), ", ")
+	case OpMin, OpMax, OpRange:
+		min, max := e.bounds(recommended)
+		switch {
+		case min != "" && max != "":
+			return fmt.Sprintf("%sentre %s e %s", prefix, min, max)
+		case min != "":
+			return prefix + "no mínimo " + min
+		default:
+			return prefix + "no máximo " + max
+		}
+	case OpBitmask:
+		var parts []string
+		if e.Set != "" {
+			parts = append(parts, "bits "+e.Set+" ativos")
+		}
+		if e.Clear != "" {
+			parts = append(parts, "bits "+e.Clear+" desativados")
+		}
+		return prefix + strings.Join(parts, " e ")
+	case OpContains:
+		if len(e.values(recommended)) == 0 {
+			return prefix + "definido"
+		}
+		return prefix + "contém " + strings.Join(e.values(recommended), ", ")
+	case OpExcludes:
+		return prefix + "não contém " + strings.Join(e.values(recommended), ", ")
+	case OpRegex:
+		return prefix + "corresponde a /" + e.Pattern + "/"
+	case OpAll:
+		var parts []string
+		for _, expectation := range e.All {
+			parts = append(parts, expectation.Describe(recommended))
+		}
+		return prefix + strings.Join(parts, " e ")
+	}
+
+	return prefix + e.Op
+}
+
+// Validate verifica se a expectativa é válida, para que erros no arquivo de regras sejam
+// reportados ao carregá-lo e não silenciosamente durante a análise
+func (e Expectation) Validate() error {
+	switch e.op() {
+	case OpEqual, OpContains, OpExcludes:
+	case OpOneOf:
+		if len(e.Values) == 0 && e.Value == "" {
+			return fmt.Errorf("%s exige values", OpOneOf)
+		}
+	case OpMin, OpMax:
+	case OpRange:
+		if e.Min == "" || e.Max == "" {
+			return fmt.Errorf("%s exige min e max", OpRange)
+		}
+	case OpBitmask:
+		if e.Set == "" && e.Clear == "" {
+			return fmt.Errorf("%s exige set ou clear", OpBitmask)
+		}
+		if _, err := parseMask(e.Set); err != nil {
+			return fmt.Errorf("máscara inválida em set: %s", e.Set)
+		}
+		if _, err := parseMask(e.Clear); err != nil {
+			return fmt.Errorf("máscara inválida em clear: %s", e.Clear)
+		}
+	case OpRegex:
+		if _, err := regexp.Compile(e.Pattern); err != nil {
+			return fmt.Errorf("expressão regular inválida: %w", err)
+		}
+	case OpAll:
+		if len(e.All) == 0 {
+			return fmt.Errorf("%s exige ao menos uma expectativa", OpAll)
+		}
+		for _, expectation := range e.All {
+			if err := expectation.Validate(); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("operador desconhecido: %s", e.Op)
+	}
+
+	for _, bound := range []string{e.Min, e.Max} {
+		if _, ok := e.number(bound); bound != "" && !ok {
+			return fmt.Errorf("valor numérico inválido: %s", bound)
+		}
+	}
+
+	return nil
+}
+
+// op retorna o operador, usando igualdade quando não informado
+func (e Expectation) op() string {
+	if e.Op == "" {
+		return OpEqual
+	}
+	return strings.ToLower(e.Op)
+}
+
+// value retorna o valor esperado de uma comparação simples
+func (e Expectation) value(recommended string) string {
+	if e.Value != "" {
+		return e.Value
+	}
+	return e.field(recommended)
+}
+
+// values retorna os valores de uma comparação com lista
+func (e Expectation) values(recommended string) []string {
+	if len(e.Values) > 0 {
+		return e.Values
+	}
+	return strings.Fields(e.value(recommended))
+}
+
+// bounds retorna os limites de uma comparação numérica
+func (e Expectation) bounds(recommended string) (string, string) {
+	min, max := e.Min, e.Max
+	switch e.op() {
+	case OpMin:
+		if min == "" {
+			min = e.value(recommended)
+		}
+	case OpMax:
+		if max == "" {
+			max = e.value(recommended)
+		}
+	}
+	return min, max
+}
+
+// field seleciona o campo configurado de um valor separado por ":"
+func (e Expectation) field(value string) string {
+	if e.Field <= 0 {
+		return value
+	}
+	parts := strings.Split(value, ":")
+	if e.Field > len(parts) {
+		return parts[len(parts)-1]
+	}
+	return parts[e.Field-1]
+}
+
+// number converte um valor numérico, considerando o formato de tempo do OpenSSH quando configurado
+func (e Expectation) number(value string) (int64, bool) {
+	if e.Duration {
+		return parseDuration(value)
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	return n, err == nil
+}
+
+// parseDuration converte um intervalo no formato do OpenSSH (ex: "120", "2m", "1h30m") em segundos
+func parseDuration(value string) (int64, bool) {
+	units := map[byte]int64{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+
+	var total, number int64
+	digits := 0
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= '0' && c <= '9':
+			number = number*10 + int64(c-'0')
+			digits++
+		case units[c|0x20] > 0 && digits > 0:
+			total += number * units[c|0x20]
+			number, digits = 0, 0
+		default:
+			return 0, false
+		}
+	}
+
+	return total + number, value != ""
+}
+
+// parseMask converte uma máscara decimal ou hexadecimal (0x...)
+func parseMask(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 0, 64)
+}
+
+// listItems separa uma lista de valores por espaços ou vírgulas
+func listItems(value string) map[string]bool {
+	items := make(map[string]bool)
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ' ' || r == '\t' || r == ',' }) {
+		items[strings.ToLower(item)] = true
+	}
+	return items
+}
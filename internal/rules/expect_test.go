@@ -0,0 +1,158 @@
+package rules
+
+import "testing"
+
+func TestExpectationMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		expectation Expectation
+		actual      string
+		recommended string
+		want        bool
+	}{
+		{"igualdade sem diferenciar maiúsculas", Equal(), "No", "no", true},
+		{"igualdade diferente", Equal(), "yes", "no", false},
+		{"operador vazio usa igualdade", Expectation{}, "no", "no", true},
+		{"igualdade com value", Expectation{Op: OpEqual, Value: "prohibit-password"}, "prohibit-password", "no", true},
+		{"um dos valores", OneOf("no", "prohibit-password"), "prohibit-password", "", true},
+		{"fora dos valores", OneOf("no", "prohibit-password"), "yes", "", false},
+
+		// Comparação numérica, e não de texto: "10" < "4" como string
+		{"mínimo atendido", AtLeast(""), "10", "4", true},
+		{"mínimo violado", AtLeast("4"), "3", "", false},
+		{"máximo numérico e não textual", AtMost(""), "10", "4", false},
+		{"máximo atendido", AtMost(""), "3", "4", true},
+		{"máximo no limite", AtMost("4"), "4", "", true},
+		{"valor não numérico", AtMost("4"), "abc", "", false},
+		{"intervalo atendido", Between("1", "10"), "5", "", true},
+		{"intervalo abaixo", Between("1", "10"), "0", "", false},
+		{"intervalo acima", Between("1", "10"), "11", "", false},
+		{"limite inválido", AtMost("x"), "1", "", false},
+
+		{"duração abaixo do máximo", Expectation{Op: OpMax, Max: "2m", Duration: true}, "1m30s", "", true},
+		{"duração acima do máximo", Expectation{Op: OpMax, Max: "60", Duration: true}, "1m30s", "", false},
+		{"duração inválida", Expectation{Op: OpMax, Max: "60", Duration: true}, "1x", "", false},
+
+		{"campo selecionado", Expectation{Op: OpMax, Max: "10", Field: 1}, "10:30:60", "", true},
+		{"campo acima do máximo", Expectation{Op: OpMax, Max: "50", Field: 3}, "10:30:60", "", false},
+		{"campo ausente usa o último", Expectation{Op: OpMax, Max: "10", Field: 3}, "10", "", true},
+
+		{"bits ativos", Expectation{Op: OpBitmask, Set: "0x2"}, "2", "", true},
+		{"bit ausente", Expectation{Op: OpBitmask, Set: "0x2"}, "1", "", false},
+		{"bits desativados", Expectation{Op: OpBitmask, Clear: "4"}, "3", "", true},
+		{"bit proibido ativo", Expectation{Op: OpBitmask, Clear: "4"}, "7", "", false},
+		{"máscara hexadecimal no valor", Expectation{Op: OpBitmask, Set: "1", Clear: "2"}, "0x1", "", true},
+		{"valor de máscara inválido", Expectation{Op: OpBitmask, Set: "1"}, "abc", "", false},
+
+		{"lista contém", Contains("curve25519-sha256"), "curve25519-sha256,diffie-hellman-group14-sha256", "", true},
+		{"lista não contém", Contains("curve25519-sha256"), "diffie-hellman-group1-sha1", "", false},
+		{"contém o recomendado", Contains(), "alice bob", "bob", true},
+		{"lista vazia", Contains(), "", "", false},
+		{"lista exclui", Excludes("diffie-hellman-group1-sha1"), "curve25519-sha256", "", true},
+		{"lista com item proibido", Excludes("diffie-hellman-group1-sha1"), "curve25519-sha256,Diffie-Hellman-Group1-SHA1", "", false},
+
+		{"expressão regular", Expectation{Op: OpRegex, Pattern: `^[0-9]+$`}, "120", "", true},
+		{"expressão regular não atendida", Expectation{Op: OpRegex, Pattern: `^[0-9]+$`}, "2m", "", false},
+		{"expressão regular inválida", Expectation{Op: OpRegex, Pattern: `(`}, "", "", false},
+
+		{"todas atendidas", AllOf(AtLeast("1"), AtMost("4")), "3", "", true},
+		{"uma não atendida", AllOf(AtLeast("1"), AtMost("4")), "5", "", false},
+		{"operador desconhecido", Expectation{Op: "gt"}, "5", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.expectation.Match(tt.actual, tt.recommended); got != tt.want {
+			t.Errorf("%s: Match(%q, %q) = %v, esperado %v", tt.name, tt.actual, tt.recommended, got, tt.want)
+		}
+	}
+}
+
+func TestExpectationDescribe(t *testing.T) {
+	tests := []struct {
+		expectation Expectation
+		recommended string
+		want        string
+	}{
+		{Equal(), "no", "igual a no"},
+		{OneOf("no", "prohibit-password"), "", "um de: no, prohibit-password"},
+		{AtLeast(""), "4", "no mínimo 4"},
+		{AtMost(""), "4", "no máximo 4"},
+		{Between("1", "10"), "", "entre 1 e 10"},
+		{Expectation{Op: OpMax, Max: "10", Field: 1}, "", "campo 1 no máximo 10"},
+		{Expectation{Op: OpBitmask, Set: "0x2", Clear: "0x4"}, "", "bits 0x2 ativos e bits 0x4 desativados"},
+		{Contains(), "", "definido"},
+		{Contains("a", "b"), "", "contém a, b"},
+		{Excludes("a"), "", "não contém a"},
+		{Expectation{Op: OpRegex, Pattern: "^no$"}, "", "corresponde a /^no$/"},
+		{AllOf(AtLeast("1"), AtMost("4")), "", "no mínimo 1 e no máximo 4"},
+		{Expectation{Op: "gt"}, "", "gt"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.expectation.Describe(tt.recommended); got != tt.want {
+			t.Errorf("Describe(%+v) = %q, esperado %q", tt.expectation, got, tt.want)
+		}
+	}
+}
+
+func TestExpectationValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		expectation Expectation
+		valid       bool
+	}{
+		{"igualdade", Equal(), true},
+		{"um de sem valores", Expectation{Op: OpOneOf}, false},
+		{"um de com valores", OneOf("no"), true},
+		{"mínimo numérico", AtLeast("4"), true},
+		{"mínimo inválido", AtLeast("quatro"), false},
+		{"intervalo sem máximo", Expectation{Op: OpRange, Min: "1"}, false},
+		{"intervalo completo", Between("1", "10"), true},
+		{"duração válida", Expectation{Op: OpMax, Max: "1h30m", Duration: true}, true},
+		{"duração inválida", Expectation{Op: OpMax, Max: "1h30x", Duration: true}, false},
+		{"máscara vazia", Expectation{Op: OpBitmask}, false},
+		{"máscara set inválida", Expectation{Op: OpBitmask, Set: "0xZZ"}, false},
+		{"máscara clear inválida", Expectation{Op: OpBitmask, Clear: "abc"}, false},
+		{"máscara válida", Expectation{Op: OpBitmask, Set: "0x2", Clear: "4"}, true},
+		{"expressão regular inválida", Expectation{Op: OpRegex, Pattern: "("}, false},
+		{"expressão regular válida", Expectation{Op: OpRegex, Pattern: "^no$"}, true},
+		{"composta vazia", Expectation{Op: OpAll}, false},
+		{"composta com item inválido", AllOf(Equal(), Expectation{Op: OpRange}), false},
+		{"composta válida", AllOf(AtLeast("1"), AtMost("4")), true},
+		{"operador desconhecido", Expectation{Op: "gt"}, false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.expectation.Validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: Validate() = %v, esperado válido=%v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+		ok    bool
+	}{
+		{"120", 120, true},
+		{"2m", 120, true},
+		{"1m30s", 90, true},
+		{"1h30m", 5400, true},
+		{"1H", 3600, true},
+		{"1d", 86400, true},
+		{"1w", 604800, true},
+		{"", 0, false},
+		{"m", 0, false},
+		{"1x", 0, false},
+		{"-1", 0, false},
+		{"1m 30s", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseDuration(tt.value)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseDuration(%q) = %d, %v, esperado %d, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
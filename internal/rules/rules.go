@@ -0,0 +1,158 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleSet é o conteúdo de um arquivo de regras do Hardshell
+type RuleSet struct {
+	// SSH são as regras para o sshd_config
+	SSH []Rule `yaml:"ssh"`
+
+	// Sysctl são as regras para os parâmetros do kernel
+	Sysctl []Rule `yaml:"sysctl"`
+
+	// Services são as regras para os serviços inseguros
+	Services []ServiceRule `yaml:"services"`
+
+	// Network configura a análise das portas em escuta
+	Network NetworkRules `yaml:"network"`
+}
+
+// NetworkRules configura a análise das portas em escuta
+type NetworkRules struct {
+	// Allow são as portas e os processos esperados, que não são reportados
+	Allow []ListenerRule `yaml:"allow"`
+}
+
+// ListenerRule identifica sockets em escuta pela porta, pelo protocolo e/ou pelo processo.
+// Campos vazios valem para qualquer valor.
+type ListenerRule struct {
+	Port     int    `yaml:"port"`
+	Protocol string `yaml:"protocol"`
+	Process  string `yaml:"process"`
+}
+
+// Matches verifica se um socket em escuta corresponde à regra
+func (l ListenerRule) Matches(protocol string, port int, process string) bool {
+	if l.Port != 0 && l.Port != port {
+		return false
+	}
+	if l.Protocol != "" && !strings.EqualFold(l.Protocol, protocol) {
+		return false
+	}
+	if l.Process != "" && l.Process != process {
+		return false
+	}
+	return true
+}
+
+// validate verifica se a regra identifica algum socket e se os valores são válidos
+func (l ListenerRule) validate() error {
+	if l.Port == 0 && l.Process == "" {
+		return fmt.Errorf("regra sem porta (port) nem processo (process)")
+	}
+	if l.Port < 0 || l.Port > 65535 {
+		return fmt.Errorf("porta inválida: %d", l.Port)
+	}
+	switch strings.ToLower(l.Protocol) {
+	case "", "tcp", "udp":
+		return nil
+	}
+	return fmt.Errorf("protocolo inválido: %s (use tcp ou udp)", l.Protocol)
+}
+
+// Rule é uma regra de configuração declarada no arquivo de regras.
+// Quando a chave já existe nas regras padrão, os campos preenchidos substituem os valores padrão.
+type Rule struct {
+	Key              string `yaml:"key"`
+	RecommendedValue string `yaml:"recommended_value"`
+	Severity         string `yaml:"severity"`
+	Description      string `yaml:"description"`
+
+	// Expect define como o valor atual é comparado; sem ele, a regra padrão é mantida
+	// ou, em regras novas, o valor deve ser igual ao recomendado
+	Expect *Expectation `yaml:"expect"`
+}
+
+// DefaultPath retorna o arquivo de regras usado quando --config não é informado
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".hardshell.yaml")
+}
+
+// Load lê um arquivo de regras. Sem caminho, usa o arquivo padrão se ele existir;
+// caso contrário, retorna um conjunto vazio e as regras padrão são usadas.
+func Load(path string) (*RuleSet, error) {
+	if path == "" {
+		path = DefaultPath()
+		if _, err := os.Stat(path); path == "" || os.IsNotExist(err) {
+			return &RuleSet{}, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de regras: %w", err)
+	}
+
+	ruleSet := &RuleSet{}
+	if err := yaml.Unmarshal(data, ruleSet); err != nil {
+		return nil, fmt.Errorf("erro ao interpretar arquivo de regras %s: %w", path, err)
+	}
+
+	for _, rule := range ruleSet.SSH {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("regra SSH inválida em %s: %w", path, err)
+		}
+	}
+
+	for _, rule := range ruleSet.Sysctl {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("regra sysctl inválida em %s: %w", path, err)
+		}
+	}
+
+	for _, rule := range ruleSet.Services {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("regra de serviço inválida em %s: %w", path, err)
+		}
+	}
+
+	for _, allow := range ruleSet.Network.Allow {
+		if err := allow.validate(); err != nil {
+			return nil, fmt.Errorf("regra de rede inválida em %s: %w", path, err)
+		}
+	}
+
+	return ruleSet, nil
+}
+
+// validate verifica os campos obrigatórios e a severidade de uma regra
+func (r Rule) validate() error {
+	if r.Key == "" {
+		return fmt.Errorf("regra sem chave (key)")
+	}
+
+	if r.Expect != nil {
+		if err := r.Expect.Validate(); err != nil {
+			return fmt.Errorf("expectativa inválida para %s: %w", r.Key, err)
+		}
+	}
+
+	switch report.Severity(r.Severity) {
+	case "", report.SeverityCritical, report.SeverityWarning, report.SeverityInfo:
+		return nil
+	}
+
+	return fmt.Errorf("severidade inválida para %s: %s (use CRITICAL, WARNING ou INFO)", r.Key, r.Severity)
+}
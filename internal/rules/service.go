@@ -0,0 +1,176 @@
+package rules
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+// regexPrefix indica um padrão de serviço interpretado como expressão regular
+const regexPrefix = "regex:"
+
+// Tipos de serviço aceitos em unit_types, além dos tipos de unit do systemd
+const (
+	// UnitTypeInetd indica um serviço habilitado no inetd ou no xinetd
+	UnitTypeInetd = "inetd"
+
+	// UnitTypeInit indica um script de inicialização SysV ou OpenRC
+	UnitTypeInit = "init"
+)
+
+// unitTypeNames são os valores aceitos em unit_types
+var unitTypeNames = []string{
+	"service", "socket", "target", "timer", "path", "mount", "automount", "swap", "slice", "scope", "device",
+	UnitTypeInetd, UnitTypeInit,
+}
+
+// ServiceRule é uma regra de serviço declarada no arquivo de regras.
+// Quando o nome já existe nas regras padrão, os campos preenchidos substituem os valores padrão
+// e as exclusões são somadas às da regra padrão. Regras novas sem match correspondem ao próprio nome.
+type ServiceRule struct {
+	Name        string `yaml:"name"`
+	Severity    string `yaml:"severity"`
+	Description string `yaml:"description"`
+
+	// Action é a correção dos serviços com --apply: disable, mask ou report (apenas reportar).
+	// Vazia, vale a política de --service-policy para regras CRITICAL e WARNING; regras INFO
+	// são apenas reportadas.
+	Action string `yaml:"action"`
+
+	ServiceMatch `yaml:",inline"`
+}
+
+// ServiceMatch descreve os serviços a que uma regra se aplica. Cada padrão é um nome exato,
+// um glob (ex: "ypserv*") ou, com o prefixo "regex:", uma expressão regular que deve
+// corresponder ao nome inteiro (ex: "regex:(in\\.)?telnetd?").
+type ServiceMatch struct {
+	// Match são os padrões dos nomes reportados
+	Match []string `yaml:"match"`
+
+	// Exclude são os padrões de nomes que nunca são reportados, mesmo que correspondam a Match
+	Exclude []string `yaml:"exclude"`
+
+	// UnitTypes restringe a regra a tipos de unit (service, socket, ...), a serviços do
+	// inetd/xinetd (inetd) ou a scripts de inicialização (init); vazio vale para todos
+	UnitTypes []string `yaml:"unit_types"`
+
+	// Packages restringe a regra a serviços instalados por um dos pacotes listados.
+	// Quando o pacote dono do serviço não pode ser determinado, a regra não se aplica.
+	Packages []string `yaml:"packages"`
+}
+
+// Matches verifica se algum dos nomes de um serviço do tipo informado corresponde à regra
+// e nenhum deles corresponde às exclusões. A origem do pacote é verificada à parte.
+func (m ServiceMatch) Matches(unitType string, names ...string) bool {
+	if len(m.UnitTypes) > 0 && !containsFold(m.UnitTypes, unitType) {
+		return false
+	}
+
+	matched := false
+	for _, name := range names {
+		if matchAny(m.Exclude, name) {
+			return false
+		}
+		if matchAny(m.Match, name) {
+			matched = true
+		}
+	}
+	return matched
+}
+
+// Validate verifica os padrões e os tipos de unit
+func (m ServiceMatch) Validate() error {
+	for _, pattern := range append(append([]string{}, m.Match...), m.Exclude...) {
+		if err := validatePattern(pattern); err != nil {
+			return err
+		}
+	}
+	for _, unitType := range m.UnitTypes {
+		if !containsFold(unitTypeNames, unitType) {
+			return fmt.Errorf("tipo de unit inválido: %s (use %s)", unitType, strings.Join(unitTypeNames, ", "))
+		}
+	}
+	return nil
+}
+
+// validate verifica os campos obrigatórios, a severidade e os padrões de uma regra de serviço
+func (r ServiceRule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("regra sem nome (name)")
+	}
+
+	if err := r.ServiceMatch.Validate(); err != nil {
+		return fmt.Errorf("padrão inválido para %s: %w", r.Name, err)
+	}
+
+	switch r.Action {
+	case "", "disable", "mask", "report":
+	default:
+		return fmt.Errorf("ação inválida para %s: %s (use disable, mask ou report)", r.Name, r.Action)
+	}
+
+	switch report.Severity(r.Severity) {
+	case "", report.SeverityCritical, report.SeverityWarning, report.SeverityInfo:
+		return nil
+	}
+
+	return fmt.Errorf("severidade inválida para %s: %s (use CRITICAL, WARNING ou INFO)", r.Name, r.Severity)
+}
+
+// matchAny verifica se o nome corresponde a algum dos padrões
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern compara um nome com um padrão exato, glob ou "regex:"
+func matchPattern(pattern, name string) bool {
+	if strings.HasPrefix(pattern, regexPrefix) {
+		re, err := compileServiceRegex(pattern)
+		return err == nil && re.MatchString(name)
+	}
+	if strings.ContainsAny(pattern, "*?[") {
+		matched, err := path.Match(pattern, name)
+		return err == nil && matched
+	}
+	return pattern == name
+}
+
+// validatePattern verifica se um padrão pode ser interpretado
+func validatePattern(pattern string) error {
+	if pattern == "" || pattern == regexPrefix {
+		return fmt.Errorf("padrão vazio")
+	}
+	if strings.HasPrefix(pattern, regexPrefix) {
+		if _, err := compileServiceRegex(pattern); err != nil {
+			return fmt.Errorf("expressão regular inválida %q: %w", pattern, err)
+		}
+		return nil
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("glob inválido %q: %w", pattern, err)
+	}
+	return nil
+}
+
+// compileServiceRegex compila um padrão "regex:", ancorado para corresponder ao nome inteiro
+func compileServiceRegex(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + strings.TrimPrefix(pattern, regexPrefix) + ")$")
+}
+
+// containsFold verifica se a lista contém o valor, sem diferenciar maiúsculas
+func containsFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
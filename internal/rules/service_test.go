@@ -0,0 +1,58 @@
+package rules
+
+import "testing"
+
+func TestServiceMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		match    ServiceMatch
+		unitType string
+		names    []string
+		want     bool
+	}{
+		{"nome exato", ServiceMatch{Match: []string{"telnet.socket"}}, "socket", []string{"telnet.socket"}, true},
+		{"nome diferente", ServiceMatch{Match: []string{"telnet.socket"}}, "socket", []string{"telnet.service"}, false},
+		{"glob", ServiceMatch{Match: []string{"ypserv*"}}, "service", []string{"ypserv.service"}, true},
+		{"glob com classe", ServiceMatch{Match: []string{"rsh[d.]*"}}, "service", []string{"rshd.service"}, true},
+		{"regex ancorada", ServiceMatch{Match: []string{`regex:(in\.)?telnetd?`}}, "inetd", []string{"in.telnetd"}, true},
+		{"regex sem o nome inteiro", ServiceMatch{Match: []string{`regex:telnet`}}, "service", []string{"telnet.service"}, false},
+		{"regex inválida", ServiceMatch{Match: []string{"regex:("}}, "service", []string{"("}, false},
+		{"qualquer um dos nomes", ServiceMatch{Match: []string{"tftp"}}, "inetd", []string{"in.tftpd", "tftp"}, true},
+		{"exclusão", ServiceMatch{Match: []string{"rpc*"}, Exclude: []string{"rpcbind.socket"}}, "socket", []string{"rpcbind.socket"}, false},
+		{"exclusão por outro nome", ServiceMatch{Match: []string{"tftp"}, Exclude: []string{"regex:in\\..*"}}, "inetd", []string{"tftp", "in.tftpd"}, false},
+		{"fora da exclusão", ServiceMatch{Match: []string{"rpc*"}, Exclude: []string{"rpcbind.socket"}}, "service", []string{"rpc-statd.service"}, true},
+		{"tipo permitido", ServiceMatch{Match: []string{"telnet*"}, UnitTypes: []string{"Socket"}}, "socket", []string{"telnet.socket"}, true},
+		{"tipo não permitido", ServiceMatch{Match: []string{"telnet*"}, UnitTypes: []string{"socket"}}, "init", []string{"telnet"}, false},
+		{"sem padrões", ServiceMatch{}, "service", []string{"ssh.service"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.match.Matches(tt.unitType, tt.names...); got != tt.want {
+			t.Errorf("%s: Matches(%q, %v) = %v, esperado %v", tt.name, tt.unitType, tt.names, got, tt.want)
+		}
+	}
+}
+
+func TestServiceRuleValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  ServiceRule
+		valid bool
+	}{
+		{"regra válida", ServiceRule{Name: "telnet", Action: "mask", Severity: "CRITICAL", ServiceMatch: ServiceMatch{Match: []string{"telnet*", `regex:(in\.)?telnetd`}, UnitTypes: []string{"socket", "inetd"}}}, true},
+		{"sem nome", ServiceRule{ServiceMatch: ServiceMatch{Match: []string{"telnet"}}}, false},
+		{"glob inválido", ServiceRule{Name: "x", ServiceMatch: ServiceMatch{Match: []string{"telnet["}}}, false},
+		{"regex inválida na exclusão", ServiceRule{Name: "x", ServiceMatch: ServiceMatch{Exclude: []string{"regex:("}}}, false},
+		{"regex vazia", ServiceRule{Name: "x", ServiceMatch: ServiceMatch{Match: []string{"regex:"}}}, false},
+		{"padrão vazio", ServiceRule{Name: "x", ServiceMatch: ServiceMatch{Match: []string{""}}}, false},
+		{"tipo de unit inválido", ServiceRule{Name: "x", ServiceMatch: ServiceMatch{UnitTypes: []string{"daemon"}}}, false},
+		{"ação inválida", ServiceRule{Name: "x", Action: "remove"}, false},
+		{"severidade inválida", ServiceRule{Name: "x", Severity: "HIGH"}, false},
+	}
+
+	for _, tt := range tests {
+		if err := tt.rule.validate(); (err == nil) != tt.valid {
+			t.Errorf("%s: validate() = %v, esperado válido=%v", tt.name, err, tt.valid)
+		}
+	}
+}
@@ -3,17 +3,20 @@ package services
 import (
 	"fmt"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"strings"
 
 	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/rules"
+	"github.com/mairinkdev/Hardshell/internal/systemd"
+	"github.com/mairinkdev/Hardshell/internal/util"
 )
 
 // Analyzer é o analisador de serviços
 type Analyzer struct {
 	mountPoint string
 	rules      []ServiceRule
+	packages   *packageIndex
+	policy     string
 }
 
 // ServiceRule representa uma regra para verificação de serviço
@@ -21,7 +24,23 @@ type ServiceRule struct {
 	Name        string
 	Description string
 	Severity    report.Severity
-	CheckFunc   func(string) bool
+	rules.ServiceMatch
+
+	// Action é a correção da regra: disable, mask ou report (sem correção). Vazia, a
+	// política do analisador vale para as regras CRITICAL e WARNING, e as demais são só reportadas.
+	Action string
+}
+
+// candidate é um serviço encontrado no sistema, comparado com as regras
+type candidate struct {
+	// Type é o tipo da unit (service, socket, ...), inetd ou init
+	Type string
+
+	// Names são os nomes comparados com os padrões das regras
+	Names []string
+
+	// Path é o arquivo da unit ou o programa, usado para descobrir o pacote de origem
+	Path string
 }
 
 // NewAnalyzer cria um novo analisador de serviços
@@ -29,173 +48,318 @@ func NewAnalyzer(mountPoint string) *Analyzer {
 	return &Analyzer{
 		mountPoint: mountPoint,
 		rules:      getDefaultRules(),
+		packages:   newPackageIndex(mountPoint),
+		policy:     PolicyDisable,
 	}
 }
 
-// Analyze analisa os serviços ativos no sistema
-func (a *Analyzer) Analyze() ([]report.Issue, error) {
-	// Se estiver analisando um mountPoint, não podemos verificar serviços ativos diretamente
-	if a.mountPoint != "" {
-		// Verificamos os serviços habilitados olhando para os symlinks em /etc/systemd/system/multi-user.target.wants/
-		systemdDir := filepath.Join(a.mountPoint, "etc/systemd/system/multi-user.target.wants")
-		return a.analyzeSystemdDir(systemdDir)
-	}
-
-	// Verifica serviços ativos usando systemctl (se disponível)
-	if hasCommand("systemctl") {
-		return a.analyzeSystemctl()
+// SetPolicy define como as units do systemd são corrigidas: desabilitadas
+// (PolicyDisable) ou mascaradas (PolicyMask). Regras com action própria não são afetadas.
+func (a *Analyzer) SetPolicy(policy string) error {
+	if !validPolicy(policy) {
+		return fmt.Errorf("política de correção inválida: %s (use %s ou %s)", policy, PolicyDisable, PolicyMask)
 	}
-
-	// Alternativa para sistemas sem systemd
-	if hasCommand("service") {
-		return a.analyzeServiceCommand()
-	}
-
-	// Se nenhum método estiver disponível, retorna uma mensagem de erro
-	return nil, fmt.Errorf("não foi possível encontrar um método para verificar serviços ativos")
+	a.policy = policy
+	return nil
 }
 
-// analyzeSystemdDir analisa os serviços habilitados em um diretório systemd
-func (a *Analyzer) analyzeSystemdDir(dir string) ([]report.Issue, error) {
-	var issues []report.Issue
-
-	// Verifica se o diretório existe
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return nil, fmt.Errorf("diretório systemd não encontrado: %s", dir)
+// remediates verifica se os serviços da regra são corrigidos com --apply. Regras INFO e as de
+// serviços cuja configuração é verificada por outro comando (snmp, mail) são apenas reportadas,
+// a menos que declarem uma action.
+func (a *Analyzer) remediates(rule ServiceRule) bool {
+	switch rule.Action {
+	case PolicyDisable, PolicyMask:
+		return true
+	case ActionReport:
+		return false
 	}
+	return rule.Severity == report.SeverityCritical || rule.Severity == report.SeverityWarning
+}
 
-	// Lista os arquivos no diretório
-	files, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao ler diretório systemd: %w", err)
+// rulePolicy retorna a política de correção de uma regra
+func (a *Analyzer) rulePolicy(rule ServiceRule) string {
+	if rule.Action != "" {
+		return rule.Action
 	}
+	return a.policy
+}
 
-	// Analisa cada serviço
-	for _, file := range files {
-		if file.IsDir() {
-			continue
+// SetRules aplica as regras do arquivo de regras. Regras com o nome de uma regra padrão
+// substituem os campos preenchidos e somam suas exclusões às da regra padrão; as demais são
+// adicionadas e, sem match, correspondem ao próprio nome.
+func (a *Analyzer) SetRules(custom []rules.ServiceRule) {
+	for _, rule := range custom {
+		idx := -1
+		for i := range a.rules {
+			if a.rules[i].Name == rule.Name {
+				idx = i
+				break
+			}
 		}
 
-		serviceName := file.Name()
-		if strings.HasSuffix(serviceName, ".service") {
-			serviceName = strings.TrimSuffix(serviceName, ".service")
+		if idx < 0 {
+			a.rules = append(a.rules, ServiceRule{
+				Name:         rule.Name,
+				Description:  fmt.Sprintf("Serviço %s não deve estar habilitado", rule.Name),
+				Severity:     report.SeverityWarning,
+				ServiceMatch: rules.ServiceMatch{Match: []string{rule.Name}},
+			})
+			idx = len(a.rules) - 1
 		}
 
-		// Verifica cada regra
-		for _, rule := range a.rules {
-			if rule.CheckFunc(serviceName) {
-				issues = append(issues, report.Issue{
-					Category:    "services",
-					Severity:    rule.Severity,
-					Description: fmt.Sprintf("%s (%s está habilitado)", rule.Description, serviceName),
-					FixCommand:  fmt.Sprintf("systemctl disable %s && systemctl stop %s", serviceName, serviceName),
-				})
-			}
+		if rule.Severity != "" {
+			a.rules[idx].Severity = report.Severity(rule.Severity)
+		}
+		if rule.Description != "" {
+			a.rules[idx].Description = rule.Description
+		}
+		if len(rule.Match) > 0 {
+			a.rules[idx].Match = rule.Match
+		}
+		if len(rule.Exclude) > 0 {
+			a.rules[idx].Exclude = append(append([]string{}, a.rules[idx].Exclude...), rule.Exclude...)
+		}
+		if len(rule.UnitTypes) > 0 {
+			a.rules[idx].UnitTypes = rule.UnitTypes
+		}
+		if len(rule.Packages) > 0 {
+			a.rules[idx].Packages = rule.Packages
+		}
+		if rule.Action != "" {
+			a.rules[idx].Action = rule.Action
 		}
 	}
+}
 
-	return issues, nil
+// Analyze analisa os serviços ativos no sistema
+func (a *Analyzer) Analyze() ([]report.Issue, error) {
+	issues, _, err := a.analyze()
+	return issues, err
 }
 
-// analyzeSystemctl analisa os serviços ativos usando systemctl
-func (a *Analyzer) analyzeSystemctl() ([]report.Issue, error) {
+// analyze analisa os serviços e retorna também as correções de cada problema
+func (a *Analyzer) analyze() ([]report.Issue, []remediation, error) {
 	var issues []report.Issue
+	var fixes []remediation
+	var err error
+
+	switch {
+	case a.mountPoint != "":
+		// Se estiver analisando um mountPoint, não podemos verificar serviços ativos diretamente.
+		// Resolve, a partir dos arquivos de units, o que o systemd iniciaria no boot.
+		systemd := hasUnitDirs(a.mountPoint)
+		if !systemd && !hasInitScripts(a.mountPoint) && !hasSuperServer(a.mountPoint) {
+			return nil, nil, fmt.Errorf("nenhum diretório de units do systemd ou de scripts de inicialização encontrado em %s", a.mountPoint)
+		}
+		if systemd {
+			issues, fixes = a.analyzeUnitGraph()
+		}
+		initIssues, initFixes := a.analyzeInitScripts(systemd)
+		issues, fixes = append(issues, initIssues...), append(fixes, initFixes...)
+	case systemdBooted() || os.Getenv(systemd.AddressEnv) != "":
+		// Consulta o estado das units diretamente no gerenciador do systemd, pelo D-Bus
+		client, connErr := systemd.Connect()
+		if connErr != nil {
+			// Sem acesso ao D-Bus (ex: sem permissão ou em um contêiner), resolve o boot pelos arquivos de units
+			issues, fixes = a.analyzeUnitGraph()
+			issues = append(issues, report.Issue{
+				Category:    "services",
+				Severity:    report.SeverityInfo,
+				Key:         "systemd",
+				Description: fmt.Sprintf("Não foi possível consultar o systemd (%v); as units foram verificadas pelos arquivos de configuração, sem o estado em execução", connErr),
+			})
+			break
+		}
+		defer client.Close()
+		issues, fixes, err = a.analyzeBus(client)
+	case hasInitScripts(""):
+		// Alternativa para sistemas sem systemd (SysV init ou OpenRC)
+		issues, fixes = a.analyzeInitScripts(false)
+	default:
+		// Se nenhum método estiver disponível, retorna uma mensagem de erro
+		return nil, nil, fmt.Errorf("não foi possível encontrar um método para verificar serviços ativos")
+	}
 
-	// Executa systemctl para listar serviços ativos
-	cmd := exec.Command("systemctl", "list-units", "--type=service", "--state=active", "--no-pager", "--plain", "--no-legend")
-	output, err := cmd.Output()
 	if err != nil {
-		return nil, fmt.Errorf("erro ao executar systemctl: %w", err)
+		return nil, nil, err
 	}
 
-	// Processa a saída
-	services := strings.Split(string(output), "\n")
-	for _, service := range services {
-		if service == "" {
+	// Serviços clássicos costumam ser iniciados pelo inetd ou xinetd, e não como units próprias
+	superIssues, superFixes := a.analyzeSuperServers()
+	return append(issues, superIssues...), append(fixes, superFixes...), nil
+}
+
+// analyzeUnitGraph analisa as units que seriam iniciadas no boot do sistema em mountPoint (ou no
+// sistema em execução, quando o systemd não responde pelo D-Bus), incluindo as puxadas por
+// qualquer target, aliases, presets e ativação por socket
+func (a *Analyzer) analyzeUnitGraph() ([]report.Issue, []remediation) {
+	var issues []report.Issue
+	var fixes []remediation
+
+	graph := newUnitGraph(a.mountPoint)
+	reported := make(map[string]bool)
+
+	for _, bootUnit := range graph.bootUnits() {
+		if !strings.HasSuffix(bootUnit.Name, ".service") && !strings.HasSuffix(bootUnit.Name, ".socket") {
 			continue
 		}
 
-		// Extrai o nome do serviço (primeiro campo)
-		fields := strings.Fields(service)
-		if len(fields) == 0 {
-			continue
+		state := fmt.Sprintf("%s é iniciado no boot por %s", bootUnit.Name, bootUnit.Via)
+		fix := remediation{Unit: bootUnit.Name}
+		if listen := graph.load(bootUnit.Name).Listen; len(listen) > 0 {
+			state += ", escutando em " + strings.Join(listen, ", ")
 		}
 
-		serviceName := fields[0]
-		if strings.HasSuffix(serviceName, ".service") {
-			serviceName = strings.TrimSuffix(serviceName, ".service")
+		// O serviço ativado por um socket é desabilitado pelo próprio socket
+		if bootUnit.Socket {
+			state = fmt.Sprintf("%s é ativado sob demanda por %s", bootUnit.Name, bootUnit.Via)
+			if listen := graph.load(bootUnit.Via).Listen; len(listen) > 0 {
+				state += ", escutando em " + strings.Join(listen, ", ")
+			}
+			fix = remediation{Unit: bootUnit.Via, Units: []string{bootUnit.Name}}
 		}
 
-		// Verifica cada regra
-		for _, rule := range a.rules {
-			if rule.CheckFunc(serviceName) {
-				issues = append(issues, report.Issue{
-					Category:    "services",
-					Severity:    rule.Severity,
-					Description: fmt.Sprintf("%s (%s está ativo)", rule.Description, serviceName),
-					FixCommand:  fmt.Sprintf("systemctl disable %s && systemctl stop %s", serviceName, serviceName),
-				})
+		// Um socket e o serviço que ele ativa são reportados uma única vez
+		baseName := unitBaseName(bootUnit.Name)
+		service := candidate{Type: unitType(bootUnit.Name), Names: []string{baseName}, Path: graph.load(bootUnit.Name).Path}
+		for _, rule := range a.matchingRules(service) {
+			if reported[rule.Name+"/"+baseName] {
+				continue
+			}
+			reported[rule.Name+"/"+baseName] = true
+
+			issue := report.Issue{
+				Category:    "services",
+				Severity:    rule.Severity,
+				Description: fmt.Sprintf("%s (%s)", rule.Description, state),
+			}
+			if a.remediates(rule) {
+				fix.Policy = a.rulePolicy(rule)
+				units := []string{fix.Unit}
+				if fix.Policy == PolicyMask {
+					units = append(units, fix.Units...)
+				}
+				issue.FixCommand = offlineCommand(a.mountPoint, fix.Policy, units...)
+				if a.mountPoint == "" {
+					issue.FixCommand = fix.liveCommand()
+				}
+				fixes = append(fixes, fix)
 			}
+			issues = append(issues, issue)
 		}
 	}
 
-	return issues, nil
+	return issues, fixes
 }
 
-// analyzeServiceCommand analisa os serviços ativos usando o comando service (para sistemas sem systemd)
-func (a *Analyzer) analyzeServiceCommand() ([]report.Issue, error) {
+// analyzeSuperServers analisa os serviços habilitados no inetd e no xinetd
+func (a *Analyzer) analyzeSuperServers() ([]report.Issue, []remediation) {
 	var issues []report.Issue
+	var fixes []remediation
+
+	entries := append(readInetd(a.mountPoint), readXinetd(a.mountPoint)...)
+	for _, entry := range entries {
+		entry := entry
+		service := candidate{Type: rules.UnitTypeInetd, Names: entry.Names(), Path: entry.Server}
+		for _, rule := range a.matchingRules(service) {
+			issue := report.Issue{
+				Category:    "services",
+				Severity:    rule.Severity,
+				Description: fmt.Sprintf("%s (%s)", rule.Description, entry.Describe()),
+			}
+			if a.remediates(rule) {
+				issue.FixCommand = entry.FixCommand(a.mountPoint)
+				fixes = append(fixes, remediation{Entry: &entry})
+			}
+			issues = append(issues, issue)
+		}
+	}
 
-	// Verifica os diretórios de init scripts
-	initDirs := []string{"/etc/init.d", "/etc/rc.d"}
+	return issues, fixes
+}
 
-	for _, dir := range initDirs {
-		if _, err := os.Stat(dir); os.IsNotExist(err) {
+// matchingRules retorna as regras que correspondem ao serviço. Regras restritas a pacotes
+// só valem quando o arquivo do serviço pertence a um dos pacotes listados.
+func (a *Analyzer) matchingRules(service candidate) []ServiceRule {
+	var matched []ServiceRule
+	for _, rule := range a.rules {
+		if !rule.Matches(service.Type, service.Names...) {
 			continue
 		}
-
-		// Lista os arquivos no diretório
-		files, err := os.ReadDir(dir)
-		if err != nil {
-			return nil, fmt.Errorf("erro ao ler diretório de serviços: %w", err)
+		if len(rule.Packages) > 0 {
+			owner := a.packages.Owner(service.Path)
+			if owner == "" || !util.ContainsString(rule.Packages, owner) {
+				continue
+			}
 		}
+		matched = append(matched, rule)
+	}
+	return matched
+}
+
+// unitType retorna o tipo de uma unit pelo sufixo (ex: telnet.socket -> socket)
+func unitType(name string) string {
+	if dot := strings.LastIndex(name, "."); dot >= 0 && isUnitName(name) {
+		return name[dot+1:]
+	}
+	return "service"
+}
 
-		// Verifica cada serviço
-		for _, file := range files {
-			if file.IsDir() {
+// unitBaseName retorna o nome de uma unit sem o tipo e a instância (ex: telnet@1.service -> telnet)
+func unitBaseName(name string) string {
+	if dot := strings.LastIndex(name, "."); dot >= 0 && isUnitName(name) {
+		name = name[:dot]
+	}
+	if at := strings.Index(name, "@"); at >= 0 {
+		name = name[:at]
+	}
+	return name
+}
+
+// analyzeInitScripts analisa os scripts SysV e OpenRC habilitados nos runlevels ou em execução.
+// Com systemd, apenas scripts sem unit nativa de mesmo nome são considerados, como faz o
+// systemd-sysv-generator.
+func (a *Analyzer) analyzeInitScripts(systemd bool) ([]report.Issue, []remediation) {
+	var issues []report.Issue
+	var fixes []remediation
+
+	var graph *unitGraph
+	if systemd {
+		graph = newUnitGraph(a.mountPoint)
+	}
+
+	for _, script := range readInitScripts(a.mountPoint) {
+		script := script
+		if graph != nil {
+			if file, masked, _ := graph.find(script.Name + ".service"); file != "" || masked {
 				continue
 			}
+		}
 
-			serviceName := file.Name()
-
-			// Verifica se o serviço está ativo
-			cmd := exec.Command("service", serviceName, "status")
-			output, _ := cmd.CombinedOutput()
-
-			if !strings.Contains(strings.ToLower(string(output)), "stopped") &&
-			   !strings.Contains(strings.ToLower(string(output)), "not running") {
-				// Verifica cada regra
-				for _, rule := range a.rules {
-					if rule.CheckFunc(serviceName) {
-						issues = append(issues, report.Issue{
-							Category:    "services",
-							Severity:    rule.Severity,
-							Description: fmt.Sprintf("%s (%s está ativo)", rule.Description, serviceName),
-							FixCommand:  fmt.Sprintf("service %s stop && update-rc.d %s disable", serviceName, serviceName),
-						})
-					}
-				}
+		service := candidate{Type: rules.UnitTypeInit, Names: []string{script.Name}, Path: script.Path}
+		for _, rule := range a.matchingRules(service) {
+			issue := report.Issue{
+				Category:    "services",
+				Severity:    rule.Severity,
+				Description: fmt.Sprintf("%s (%s)", rule.Description, script.Describe()),
+			}
+			if a.remediates(rule) {
+				issue.FixCommand = script.FixCommand(a.mountPoint)
+				fixes = append(fixes, remediation{Script: &script})
 			}
+			issues = append(issues, issue)
 		}
 	}
 
-	return issues, nil
+	return issues, fixes
 }
 
-// Fix gera um script para corrigir os problemas encontrados
+// Fix corrige os problemas das regras que aceitam correção (veja remediates). Em um mountPoint, as units e as entradas do
+// inetd/xinetd são corrigidas diretamente nos arquivos do sistema montado; no sistema em
+// execução, as units são paradas e desabilitadas (ou mascaradas) pelo D-Bus do systemd.
 func (a *Analyzer) Fix() error {
 	// Analisa os problemas
-	issues, err := a.Analyze()
+	issues, fixes, err := a.analyze()
 	if err != nil {
 		return err
 	}
@@ -204,168 +368,128 @@ func (a *Analyzer) Fix() error {
 		fmt.Println("Nenhum serviço inseguro encontrado.")
 		return nil
 	}
-
-	// Aplica as correções
-	for _, issue := range issues {
-		cmd := issue.FixCommand
-
-		// Executa o comando
-		fmt.Printf("Aplicando correção: %s\n", cmd)
-
-		// Aqui você pode implementar a execução do comando
-		// Por enquanto, apenas simula a execução
-		fmt.Printf("  [Simulando] %s\n", cmd)
+	if len(fixes) == 0 {
+		fmt.Println("Nenhum serviço com correção automática; os demais são apenas reportados.")
+		return nil
 	}
 
-	return nil
+	if a.mountPoint != "" {
+		return a.applyOffline(fixes)
+	}
+	return a.applyLive(fixes)
 }
 
-// hasCommand verifica se um comando está disponível no sistema
-func hasCommand(cmd string) bool {
-	_, err := exec.LookPath(cmd)
-	return err == nil
+// systemdBooted verifica se o sistema em execução foi iniciado pelo systemd, como o sd_booted()
+func systemdBooted() bool {
+	info, err := os.Stat("/run/systemd/system")
+	return err == nil && info.IsDir()
 }
 
 // getDefaultRules retorna as regras padrão para verificação de serviços
 func getDefaultRules() []ServiceRule {
 	return []ServiceRule{
 		{
-			Name:        "telnet",
-			Description: "Serviço Telnet oferece comunicação não criptografada",
-			Severity:    report.SeverityCritical,
-			CheckFunc: func(service string) bool {
-				return service == "telnet" || service == "telnetd" || service == "inetd"
-			},
+			Name:         "telnet",
+			Description:  "Serviço Telnet oferece comunicação não criptografada",
+			Severity:     report.SeverityCritical,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"telnet", "telnetd"}},
 		},
 		{
-			Name:        "rsh",
-			Description: "Serviço RSH é inseguro e deve ser desabilitado",
-			Severity:    report.SeverityCritical,
-			CheckFunc: func(service string) bool {
-				return service == "rsh" || service == "rsh-server" || service == "rshd"
-			},
+			Name:         "rsh",
+			Description:  "Serviço RSH é inseguro e deve ser desabilitado",
+			Severity:     report.SeverityCritical,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"rsh", "rsh-server", "rshd"}},
 		},
 		{
-			Name:        "rlogin",
-			Description: "Serviço RLogin é inseguro e deve ser desabilitado",
-			Severity:    report.SeverityCritical,
-			CheckFunc: func(service string) bool {
-				return service == "rlogin" || service == "rlogind"
-			},
+			Name:         "rlogin",
+			Description:  "Serviço RLogin é inseguro e deve ser desabilitado",
+			Severity:     report.SeverityCritical,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"rlogin", "rlogind"}},
 		},
 		{
-			Name:        "rexec",
-			Description: "Serviço RExec é inseguro e deve ser desabilitado",
-			Severity:    report.SeverityCritical,
-			CheckFunc: func(service string) bool {
-				return service == "rexec" || service == "rexecd"
-			},
+			Name:         "rexec",
+			Description:  "Serviço RExec é inseguro e deve ser desabilitado",
+			Severity:     report.SeverityCritical,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"rexec", "rexecd"}},
 		},
 		{
 			Name:        "ftp",
 			Description: "Serviço FTP transfere credenciais sem criptografia",
 			Severity:    report.SeverityWarning,
-			CheckFunc: func(service string) bool {
-				return service == "ftp" || service == "ftpd" ||
-				       service == "vsftpd" || service == "proftpd" ||
-				       strings.Contains(service, "ftp")
+			ServiceMatch: rules.ServiceMatch{
+				Match:   []string{"ftp", "ftpd", "vsftpd", "proftpd", "pure-ftpd", "pure-ftpd-*", "wu-ftpd", "*-ftpd"},
+				Exclude: []string{"sftp*", "*-sftp*", "*tftp*"},
 			},
 		},
 		{
-			Name:        "tftp",
-			Description: "Serviço TFTP é inseguro e não deve ser usado em produção",
-			Severity:    report.SeverityCritical,
-			CheckFunc: func(service string) bool {
-				return service == "tftp" || service == "tftpd" ||
-				       service == "atftpd" || service == "tftpd-hpa"
-			},
+			Name:         "tftp",
+			Description:  "Serviço TFTP é inseguro e não deve ser usado em produção",
+			Severity:     report.SeverityCritical,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"tftp", "tftpd", "atftpd", "tftpd-hpa"}},
 		},
 		{
-			Name:        "finger",
-			Description: "Serviço Finger pode revelar informações sobre usuários",
-			Severity:    report.SeverityWarning,
-			CheckFunc: func(service string) bool {
-				return service == "finger" || service == "fingerd"
-			},
+			Name:         "finger",
+			Description:  "Serviço Finger pode revelar informações sobre usuários",
+			Severity:     report.SeverityWarning,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"finger", "fingerd"}},
 		},
 		{
-			Name:        "talk",
-			Description: "Serviço Talk não é criptografado e é raramente usado",
-			Severity:    report.SeverityWarning,
-			CheckFunc: func(service string) bool {
-				return service == "talk" || service == "talkd" ||
-				       service == "ntalk" || service == "ntalkd"
-			},
+			Name:         "talk",
+			Description:  "Serviço Talk não é criptografado e é raramente usado",
+			Severity:     report.SeverityWarning,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"talk", "talkd", "ntalk", "ntalkd"}},
 		},
 		{
-			Name:        "nis",
-			Description: "NIS é considerado inseguro para autenticação",
-			Severity:    report.SeverityWarning,
-			CheckFunc: func(service string) bool {
-				return service == "nis" || service == "yp" ||
-				       strings.Contains(service, "ypserv") ||
-				       strings.Contains(service, "ypbind")
-			},
+			Name:         "nis",
+			Description:  "NIS é considerado inseguro para autenticação",
+			Severity:     report.SeverityWarning,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"nis", "yp", "ypserv*", "ypbind*", "*-ypserv*", "*-ypbind*"}},
 		},
 		{
-			Name:        "snmpd",
-			Description: "SNMP pode estar configurado com community strings padrão",
-			Severity:    report.SeverityWarning,
-			CheckFunc: func(service string) bool {
-				return service == "snmpd" || service == "snmp"
-			},
+			Name:         "snmpd",
+			Description:  "SNMP expõe informações do sistema; a configuração é verificada pelo comando snmp",
+			Severity:     report.SeverityWarning,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"snmpd", "snmp"}},
+			Action:       ActionReport,
 		},
 		{
-			Name:        "portmap",
-			Description: "Portmap/RPC pode expor serviços desnecessários",
-			Severity:    report.SeverityWarning,
-			CheckFunc: func(service string) bool {
-				return service == "portmap" || service == "rpcbind"
-			},
+			Name:         "portmap",
+			Description:  "Portmap/RPC pode expor serviços desnecessários",
+			Severity:     report.SeverityWarning,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"portmap", "rpcbind"}},
 		},
 		{
-			Name:        "sendmail",
-			Description: "Servidores de email podem precisar de configuração adicional de segurança",
-			Severity:    report.SeverityInfo,
-			CheckFunc: func(service string) bool {
-				return service == "sendmail" || service == "postfix" ||
-				       service == "exim" || service == "exim4"
-			},
+			Name:         "sendmail",
+			Description:  "Servidores de email aceitam conexões da rede; a configuração é verificada pelo comando mail",
+			Severity:     report.SeverityInfo,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"sendmail", "postfix", "exim", "exim4"}},
+			Action:       ActionReport,
 		},
 		{
-			Name:        "xserver",
-			Description: "Servidor X não deve estar executando em servidores",
-			Severity:    report.SeverityWarning,
-			CheckFunc: func(service string) bool {
-				return service == "xorg" || service == "xserver" ||
-				       service == "xorg-server" || service == "gdm" ||
-				       service == "lightdm" || service == "kdm" ||
-				       service == "sddm"
-			},
+			Name:         "xserver",
+			Description:  "Servidor X não deve estar executando em servidores",
+			Severity:     report.SeverityWarning,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"xorg", "xserver", "xorg-server", "gdm", "lightdm", "kdm", "sddm"}},
+			// Parar o gerenciador de login encerra a sessão gráfica; a remoção fica a cargo do administrador
+			Action: ActionReport,
 		},
 		{
-			Name:        "avahi",
-			Description: "Avahi (mDNS) não é necessário em servidores",
-			Severity:    report.SeverityInfo,
-			CheckFunc: func(service string) bool {
-				return service == "avahi" || service == "avahi-daemon"
-			},
+			Name:         "avahi",
+			Description:  "Avahi (mDNS) não é necessário em servidores",
+			Severity:     report.SeverityInfo,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"avahi", "avahi-daemon"}},
 		},
 		{
-			Name:        "cups",
-			Description: "CUPS não é necessário em servidores sem impressoras",
-			Severity:    report.SeverityInfo,
-			CheckFunc: func(service string) bool {
-				return service == "cups" || service == "cupsd"
-			},
+			Name:         "cups",
+			Description:  "CUPS não é necessário em servidores sem impressoras",
+			Severity:     report.SeverityInfo,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"cups", "cupsd"}},
 		},
 		{
-			Name:        "dhcpd",
-			Description: "Servidor DHCP pode precisar de revisão de segurança",
-			Severity:    report.SeverityInfo,
-			CheckFunc: func(service string) bool {
-				return service == "dhcpd" || service == "isc-dhcp-server"
-			},
+			Name:         "dhcpd",
+			Description:  "Servidor DHCP pode precisar de revisão de segurança",
+			Severity:     report.SeverityInfo,
+			ServiceMatch: rules.ServiceMatch{Match: []string{"dhcpd", "isc-dhcp-server"}},
 		},
 	}
 }
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/systemd"
+)
+
+func TestAnalyzeWithoutBus(t *testing.T) {
+	// Um endereço sem servidor faz a conexão ao D-Bus falhar
+	t.Setenv(systemd.AddressEnv, "unix:path="+filepath.Join(t.TempDir(), "bus"))
+
+	issues, _, err := NewAnalyzer("").analyze()
+	if err != nil {
+		t.Fatalf("a falha de conexão não deveria interromper a análise: %v", err)
+	}
+
+	for _, issue := range issues {
+		if issue.Key == "systemd" && issue.Severity == report.SeverityInfo {
+			return
+		}
+	}
+	t.Errorf("issues = %+v, esperado o aviso de análise pelos arquivos de units", issues)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/systemd"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// analyzeBus analisa, pelo D-Bus do systemd, os serviços e sockets ativos ou habilitados
+// no sistema em execução, com o estado de cada unit
+func (a *Analyzer) analyzeBus(client *systemd.Client) ([]report.Issue, []remediation, error) {
+	var issues []report.Issue
+	var fixes []remediation
+
+	units, err := client.ListUnits("*.service", "*.socket")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	graph := newUnitGraph("")
+	for _, unit := range units {
+		if !unit.Active() && !unit.Enabled() {
+			continue
+		}
+
+		state := fmt.Sprintf("%s: %s", unit.Name, unit.State())
+		names := []string{unitBaseName(unit.Name)}
+		fix := remediation{Unit: unit.Name}
+
+		// O serviço ativado por um socket é parado junto e identifica o socket nas regras
+		if unitType(unit.Name) == "socket" {
+			listen, err := client.SocketListen(unit)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(listen) > 0 {
+				state += ", escutando em " + strings.Join(listen, ", ")
+			}
+
+			triggers, err := client.Triggers(unit)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, trigger := range triggers {
+				names = util.AppendUnique(names, unitBaseName(trigger))
+			}
+			fix.Units = triggers
+		}
+
+		service := candidate{Type: unitType(unit.Name), Names: names, Path: graph.load(unit.Name).Path}
+		for _, rule := range a.matchingRules(service) {
+			issue := report.Issue{
+				Category:    "services",
+				Severity:    rule.Severity,
+				Description: fmt.Sprintf("%s (%s)", rule.Description, state),
+			}
+			if a.remediates(rule) {
+				fix.Policy = a.rulePolicy(rule)
+				issue.FixCommand = fix.liveCommand()
+				fixes = append(fixes, fix)
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, fixes, nil
+}
+
+// liveCommand gera o comando do systemctl equivalente à correção pelo D-Bus
+func (r remediation) liveCommand() string {
+	if r.Policy == PolicyMask {
+		return fmt.Sprintf("systemctl mask --now %s", strings.Join(append([]string{r.Unit}, r.Units...), " "))
+	}
+	cmd := fmt.Sprintf("systemctl disable --now %s", r.Unit)
+	if len(r.Units) > 0 {
+		var patterns []string
+		for _, unit := range r.Units {
+			patterns = append(patterns, fmt.Sprintf("'%s'", instancePattern(unit)))
+		}
+		cmd += fmt.Sprintf(" && systemctl stop %s", strings.Join(patterns, " "))
+	}
+	return cmd
+}
+
+// instancePattern retorna o padrão das instâncias de um template (ex: telnet@*.service) ou o próprio nome
+func instancePattern(name string) string {
+	return strings.Replace(name, "@.", "@*.", 1)
+}
+
+// applyLive para e desabilita ou mascara as units pelo D-Bus do systemd. Entradas do
+// inetd/xinetd e scripts de inicialização continuam apenas simulados.
+func (a *Analyzer) applyLive(fixes []remediation) error {
+	var client *systemd.Client
+	done := make(map[string]bool)
+
+	for _, fix := range fixes {
+		if fix.Entry != nil || fix.Script != nil {
+			var cmd string
+			if fix.Entry != nil {
+				cmd = fix.Entry.FixCommand("")
+			} else {
+				cmd = fix.Script.FixCommand("")
+			}
+			fmt.Printf("Aplicando correção: %s\n", cmd)
+			fmt.Printf("  [Simulando] %s\n", cmd)
+			continue
+		}
+		if done[fix.Unit] {
+			continue
+		}
+		done[fix.Unit] = true
+
+		if client == nil {
+			var err error
+			if client, err = systemd.Connect(); err != nil {
+				return err
+			}
+			defer client.Close()
+		}
+
+		fmt.Printf("Aplicando correção: %s\n", fix.liveCommand())
+		if err := client.StopUnit(fix.Unit); err != nil {
+			return err
+		}
+		if err := stopTriggered(client, fix.Units); err != nil {
+			return err
+		}
+
+		var changes []systemd.Change
+		var err error
+		if fix.Policy == PolicyMask {
+			changes, err = client.MaskUnits(append([]string{fix.Unit}, fix.Units...)...)
+		} else {
+			changes, err = client.DisableUnits(fix.Unit)
+		}
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+	return nil
+}
+
+// stopTriggered para os serviços ativados por um socket. Templates (ex: telnet@.service)
+// não podem ser parados; apenas suas instâncias em execução são paradas.
+func stopTriggered(client *systemd.Client, units []string) error {
+	var patterns []string
+	for _, unit := range units {
+		patterns = append(patterns, instancePattern(unit))
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	loaded, err := client.ListUnits(patterns...)
+	if err != nil {
+		return err
+	}
+	for _, unit := range loaded {
+		if !unit.Active() {
+			continue
+		}
+		if err := client.StopUnit(unit.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
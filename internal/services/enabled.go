@@ -0,0 +1,42 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// Enabled verifica se algum dos serviços informados (ex: snmpd) é iniciado no boot pelo
+// systemd, diretamente ou por um socket, ou por um script SysV/OpenRC. No sistema em
+// execução, scripts em execução também contam. Retorna a unit ou o script encontrado.
+func Enabled(mountPoint string, names ...string) (string, bool) {
+	var graph *unitGraph
+	if hasUnitDirs(mountPoint) {
+		graph = newUnitGraph(mountPoint)
+		for _, bootUnit := range graph.bootUnits() {
+			if !strings.HasSuffix(bootUnit.Name, ".service") && !strings.HasSuffix(bootUnit.Name, ".socket") {
+				continue
+			}
+			if util.ContainsString(names, unitBaseName(bootUnit.Name)) {
+				return bootUnit.Name, true
+			}
+		}
+	}
+
+	if !hasInitScripts(mountPoint) {
+		return "", false
+	}
+	for _, script := range readInitScripts(mountPoint) {
+		if !util.ContainsString(names, script.Name) {
+			continue
+		}
+		// Com systemd, o script só é usado quando não há unit nativa de mesmo nome
+		if graph != nil {
+			if file, masked, _ := graph.find(script.Name + ".service"); file != "" || masked {
+				continue
+			}
+		}
+		return script.Path, true
+	}
+	return "", false
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+const (
+	// inetdConfPath é a configuração do inetd
+	inetdConfPath = "/etc/inetd.conf"
+
+	// xinetdConfPath é a configuração principal do xinetd
+	xinetdConfPath = "/etc/xinetd.conf"
+
+	// xinetdDir é o diretório padrão de serviços do xinetd
+	xinetdDir = "/etc/xinetd.d"
+)
+
+// inetdAliases traduz os nomes de /etc/services usados pelo inetd para o nome do serviço
+var inetdAliases = map[string]string{
+	"shell":   "rsh",
+	"login":   "rlogin",
+	"exec":    "rexec",
+	"ntalk":   "talk",
+	"telnets": "telnet",
+}
+
+// inetdEntry é um serviço habilitado no inetd ou no xinetd
+type inetdEntry struct {
+	Service string
+	Server  string
+	File    string
+	Line    int
+
+	// Xinetd indica uma entrada do xinetd. OpenLine é a linha com "{" do bloco e
+	// DisableLine, a linha do atributo disable, se houver.
+	Xinetd      bool
+	OpenLine    int
+	DisableLine int
+}
+
+// Names retorna os nomes usados para comparar a entrada com as regras: o nome do serviço,
+// seu equivalente e o programa executado (ex: in.rshd -> rshd)
+func (e inetdEntry) Names() []string {
+	names := []string{e.Service}
+	if alias, ok := inetdAliases[e.Service]; ok {
+		names = append(names, alias)
+	}
+	if e.Server != "" {
+		names = append(names, strings.TrimPrefix(filepath.Base(e.Server), "in."))
+	}
+	return names
+}
+
+// Describe descreve onde a entrada está habilitada
+func (e inetdEntry) Describe() string {
+	daemon := "inetd"
+	if e.Xinetd {
+		daemon = "xinetd"
+	}
+	return fmt.Sprintf("%s está habilitado no %s em %s:%d", e.Service, daemon, e.File, e.Line)
+}
+
+// xinetdDisableLine é a linha gravada para desabilitar um serviço do xinetd
+const xinetdDisableLine = "        disable         = yes"
+
+// FixCommand gera o comando que desabilita apenas esta entrada no sistema em mountPoint.
+// Comentar uma linha do inetd.conf não desloca as demais, mas no xinetd uma linha disable
+// pode ser inserida; por isso os comandos do xinetd localizam o bloco "service <nome>" em
+// vez de usar números de linha, que mudam quando há vários serviços no mesmo arquivo.
+func (e inetdEntry) FixCommand(mountPoint string) string {
+	file := util.JoinMount(mountPoint, e.File)
+	if !e.Xinetd {
+		return fmt.Sprintf("sed -i '%ds/^/#/' %s", e.Line, file)
+	}
+
+	block := fmt.Sprintf(`/^[[:space:]]*service[[:space:]]+%s([[:space:]{]|$)/,/^[[:space:]]*\}/`, regexp.QuoteMeta(e.Service))
+	if e.DisableLine > 0 {
+		return fmt.Sprintf("sed -i -E '%s s/^[[:space:]]*disable[[:space:]]*=.*/%s/' %s", block, xinetdDisableLine, file)
+	}
+	return fmt.Sprintf("sed -i -E -e '%s{' -e '/\\{/a\\%s' -e '}' %s", block, xinetdDisableLine, file)
+}
+
+// editLine retorna a linha alterada pela correção da entrada
+func (e inetdEntry) editLine() int {
+	switch {
+	case !e.Xinetd:
+		return e.Line
+	case e.DisableLine > 0:
+		return e.DisableLine
+	}
+	return e.OpenLine
+}
+
+// disable aplica às linhas do arquivo a mesma alteração de FixCommand
+func (e inetdEntry) disable(lines []string) []string {
+	idx := e.editLine() - 1
+	if idx < 0 || idx >= len(lines) {
+		return lines
+	}
+
+	switch {
+	case !e.Xinetd:
+		lines[idx] = "#" + lines[idx]
+	case e.DisableLine > 0:
+		lines[idx] = xinetdDisableLine
+	default:
+		lines = append(lines[:idx+1], append([]string{xinetdDisableLine}, lines[idx+1:]...)...)
+	}
+	return lines
+}
+
+// hasSuperServer verifica se o sistema analisado tem configuração do inetd ou do xinetd
+func hasSuperServer(mountPoint string) bool {
+	for _, path := range []string{inetdConfPath, xinetdConfPath, xinetdDir} {
+		if _, err := os.Stat(util.JoinMount(mountPoint, path)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// readInetd lê os serviços habilitados (linhas não comentadas) do inetd.conf
+func readInetd(mountPoint string) []inetdEntry {
+	file, err := os.Open(util.JoinMount(mountPoint, inetdConfPath))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var entries []inetdEntry
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// serviço tipo-socket protocolo wait usuário programa argumentos
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+
+		// Serviços RPC usam o formato nome/versão
+		service := strings.SplitN(fields[0], "/", 2)[0]
+		if colon := strings.LastIndex(service, ":"); colon >= 0 {
+			service = service[colon+1:]
+		}
+
+		server := fields[5]
+		if (server == "/usr/sbin/tcpd" || server == "/usr/sbin/tcpd.real") && len(fields) > 6 {
+			server = fields[6]
+		}
+
+		entries = append(entries, inetdEntry{Service: service, Server: server, File: inetdConfPath, Line: lineNumber})
+	}
+
+	return entries
+}
+
+// xinetdService é um bloco "service" de uma configuração do xinetd
+type xinetdService struct {
+	inetdEntry
+	disabled bool
+}
+
+// readXinetd lê os serviços habilitados no xinetd.conf e nos diretórios incluídos.
+// Um serviço está habilitado se não tem "disable = yes" nem aparece em "disabled" da seção defaults.
+func readXinetd(mountPoint string) []inetdEntry {
+	var services []xinetdService
+	globalDisabled := make(map[string]bool)
+
+	// Sem o arquivo principal, apenas o diretório padrão é considerado
+	queue := []string{xinetdConfPath}
+	if _, err := os.Stat(util.JoinMount(mountPoint, xinetdConfPath)); err != nil {
+		queue = xinetdDirFiles(mountPoint, xinetdDir)
+	}
+
+	for i := 0; i < len(queue); i++ {
+		parsed, disabled, includes := parseXinetdFile(mountPoint, queue[i])
+		services = append(services, parsed...)
+		for name := range disabled {
+			globalDisabled[name] = true
+		}
+		for _, dir := range includes {
+			queue = append(queue, xinetdDirFiles(mountPoint, dir)...)
+		}
+	}
+
+	var entries []inetdEntry
+	for _, service := range services {
+		if !service.disabled && !globalDisabled[service.Service] {
+			entries = append(entries, service.inetdEntry)
+		}
+	}
+	return entries
+}
+
+// xinetdDirFiles lista os arquivos de um includedir. Como no xinetd, arquivos com "." ou
+// terminados em "~" são ignorados.
+func xinetdDirFiles(mountPoint, dir string) []string {
+	entries, err := os.ReadDir(util.JoinMount(mountPoint, dir))
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.Contains(name, ".") || strings.HasSuffix(name, "~") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	sort.Strings(files)
+	return files
+}
+
+// parseXinetdFile lê os blocos de um arquivo do xinetd. Retorna os serviços, os nomes
+// desabilitados na seção defaults e os diretórios incluídos.
+func parseXinetdFile(mountPoint, path string) ([]xinetdService, map[string]bool, []string) {
+	disabled := make(map[string]bool)
+
+	file, err := os.Open(util.JoinMount(mountPoint, path))
+	if err != nil {
+		return nil, disabled, nil
+	}
+	defer file.Close()
+
+	var services []xinetdService
+	var includes []string
+	var current *xinetdService
+	inDefaults, inBlock := false, false
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch {
+		case !inBlock && fields[0] == "includedir" && len(fields) > 1:
+			includes = append(includes, fields[1])
+		case !inBlock && fields[0] == "service" && len(fields) > 1:
+			current = &xinetdService{inetdEntry: inetdEntry{Service: fields[1], File: path, Line: lineNumber, Xinetd: true}}
+			inBlock = strings.HasSuffix(line, "{")
+			if inBlock {
+				current.OpenLine = lineNumber
+			}
+		case !inBlock && fields[0] == "defaults":
+			inDefaults = true
+			inBlock = strings.HasSuffix(line, "{")
+		case !inBlock && line == "{":
+			inBlock = true
+			if current != nil {
+				current.OpenLine = lineNumber
+			}
+		case inBlock && line == "}":
+			if current != nil {
+				services = append(services, *current)
+			}
+			current, inDefaults, inBlock = nil, false, false
+		case inBlock:
+			key, value, ok := xinetdAttribute(line)
+			if !ok {
+				continue
+			}
+			switch {
+			case inDefaults && key == "disabled":
+				for _, name := range strings.Fields(value) {
+					disabled[name] = true
+				}
+			case current != nil && key == "disable":
+				current.disabled = strings.EqualFold(value, "yes")
+				current.DisableLine = lineNumber
+			case current != nil && key == "server":
+				current.Server = value
+			}
+		}
+	}
+
+	return services, disabled, includes
+}
+
+// xinetdAttribute separa um atributo "nome = valor" (ou "+=", "-=") de um bloco do xinetd
+func xinetdAttribute(line string) (string, string, bool) {
+	idx := strings.Index(line, "=")
+	if idx <= 0 {
+		return "", "", false
+	}
+	key := strings.TrimRight(strings.TrimSpace(line[:idx]), "+-")
+	return strings.TrimSpace(key), strings.TrimSpace(line[idx+1:]), true
+}
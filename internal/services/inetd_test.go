@@ -0,0 +1,105 @@
+package services
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// xinetdConf tem vários serviços no mesmo arquivo: a inserção da linha disable no primeiro
+// desloca as linhas dos seguintes
+const xinetdConf = `defaults
+{
+        instances       = 60
+}
+
+service telnet
+{
+        socket_type     = stream
+        server          = /usr/sbin/in.telnetd
+}
+
+service tftp {
+        socket_type     = dgram
+        server          = /usr/sbin/in.tftpd
+}
+
+service rsh
+{
+        disable         = no
+        server          = /usr/sbin/in.rshd
+}
+
+service ftp
+{
+        disable         = yes
+        server          = /usr/sbin/vsftpd
+}
+`
+
+func TestXinetdFixCommandsMatchDisable(t *testing.T) {
+	if _, err := exec.LookPath("sed"); err != nil {
+		t.Skip("sed não encontrado")
+	}
+
+	// Os comandos gerados, executados em sequência, devem produzir o mesmo arquivo que a correção direta
+	scripted, direct := t.TempDir(), t.TempDir()
+	for _, root := range []string{scripted, direct} {
+		path := filepath.Join(root, xinetdConfPath)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(xinetdConf), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := readXinetd(scripted)
+	if len(entries) != 3 {
+		t.Fatalf("readXinetd = %+v, esperado telnet, tftp e rsh", entries)
+	}
+	for _, entry := range entries {
+		output, err := exec.Command("sh", "-c", entry.FixCommand(scripted)).CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s: %v\n%s", entry.FixCommand(scripted), err, output)
+		}
+	}
+	if err := disableEntries(direct, readXinetd(direct)); err != nil {
+		t.Fatalf("disableEntries: %v", err)
+	}
+
+	got, _ := os.ReadFile(filepath.Join(scripted, xinetdConfPath))
+	want, _ := os.ReadFile(filepath.Join(direct, xinetdConfPath))
+	if string(got) != string(want) {
+		t.Errorf("comandos gerados =\n%s\nesperado\n%s", got, want)
+	}
+	if remaining := readXinetd(scripted); len(remaining) != 0 {
+		t.Errorf("serviços ainda habilitados: %+v", remaining)
+	}
+}
+
+func TestTelnetRuleIgnoresInetd(t *testing.T) {
+	tests := []struct {
+		unitType string
+		names    []string
+		want     bool
+	}{
+		// O inetd só é reportado pela entrada telnet habilitada no inetd.conf
+		{"service", []string{"inetd"}, false},
+		{"service", []string{"openbsd-inetd"}, false},
+		{"inetd", []string{"telnet", "telnetd"}, true},
+		{"socket", []string{"telnet"}, true},
+	}
+
+	for _, rule := range getDefaultRules() {
+		if rule.Name != "telnet" {
+			continue
+		}
+		for _, tt := range tests {
+			if got := rule.Matches(tt.unitType, tt.names...); got != tt.want {
+				t.Errorf("telnet.Matches(%s, %v) = %v, esperado %v", tt.unitType, tt.names, got, tt.want)
+			}
+		}
+	}
+}
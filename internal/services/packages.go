@@ -0,0 +1,191 @@
+package services
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// Bases de pacotes lidas para descobrir o pacote dono de um arquivo
+const (
+	dpkgInfoDir    = "/var/lib/dpkg/info"
+	apkInstalled   = "/lib/apk/db/installed"
+	pacmanLocalDir = "/var/lib/pacman/local"
+)
+
+// rpmDBDirs são os diretórios possíveis da base do rpm
+var rpmDBDirs = []string{"/var/lib/rpm", "/usr/lib/sysimage/rpm"}
+
+// packageIndex associa os arquivos instalados aos pacotes que os instalaram. As bases do
+// dpkg, do apk e do pacman são lidas do sistema de arquivos; para o rpm, cuja base é
+// binária, o comando rpm é consultado quando disponível.
+type packageIndex struct {
+	mountPoint string
+	owners     map[string]string
+	loaded     bool
+}
+
+// newPackageIndex cria o índice de pacotes do sistema em mountPoint. As bases só são lidas
+// na primeira consulta, pois apenas regras com "packages" precisam delas.
+func newPackageIndex(mountPoint string) *packageIndex {
+	return &packageIndex{mountPoint: mountPoint}
+}
+
+// Owner retorna o pacote que instalou o arquivo, ou vazio se não for possível determiná-lo
+func (p *packageIndex) Owner(file string) string {
+	if file == "" {
+		return ""
+	}
+	if !p.loaded {
+		p.load()
+	}
+
+	for _, candidate := range usrMergeVariants(file) {
+		if owner, ok := p.owners[candidate]; ok {
+			return owner
+		}
+	}
+	return p.rpmOwner(file)
+}
+
+// load lê as bases de pacotes encontradas
+func (p *packageIndex) load() {
+	p.owners = make(map[string]string)
+	p.loaded = true
+	p.loadDpkg()
+	p.loadApk()
+	p.loadPacman()
+}
+
+// loadDpkg lê as listas de arquivos do dpkg (/var/lib/dpkg/info/<pacote>[:arquitetura].list)
+func (p *packageIndex) loadDpkg() {
+	lists, _ := filepath.Glob(filepath.Join(util.JoinMount(p.mountPoint, dpkgInfoDir), "*.list"))
+	for _, list := range lists {
+		name := strings.TrimSuffix(filepath.Base(list), ".list")
+		name = strings.SplitN(name, ":", 2)[0]
+		p.readFileList(list, name)
+	}
+}
+
+// loadApk lê a base do apk, em que P: é o pacote, F: um diretório e R: um arquivo do diretório
+func (p *packageIndex) loadApk() {
+	file, err := os.Open(util.JoinMount(p.mountPoint, apkInstalled))
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	name, dir := "", ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "P:"):
+			name, dir = line[2:], ""
+		case strings.HasPrefix(line, "F:"):
+			dir = line[2:]
+		case strings.HasPrefix(line, "R:") && name != "":
+			p.owners["/"+filepath.Join(dir, line[2:])] = name
+		}
+	}
+}
+
+// loadPacman lê a base do pacman, com o nome em desc (%NAME%) e os arquivos em files (%FILES%)
+func (p *packageIndex) loadPacman() {
+	dirs, _ := filepath.Glob(filepath.Join(util.JoinMount(p.mountPoint, pacmanLocalDir), "*"))
+	for _, dir := range dirs {
+		name := pacmanSection(filepath.Join(dir, "desc"), "%NAME%")
+		if len(name) == 0 {
+			continue
+		}
+		for _, file := range pacmanSection(filepath.Join(dir, "files"), "%FILES%") {
+			if !strings.HasSuffix(file, "/") {
+				p.owners["/"+file] = name[0]
+			}
+		}
+	}
+}
+
+// pacmanSection retorna as linhas de uma seção (%NOME%) de um arquivo da base do pacman
+func pacmanSection(path, section string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var values []string
+	inSection := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == section:
+			inSection = true
+		case inSection && line == "":
+			return values
+		case inSection:
+			values = append(values, line)
+		}
+	}
+	return values
+}
+
+// readFileList associa ao pacote cada arquivo listado, um por linha
+func (p *packageIndex) readFileList(path, name string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			p.owners[line] = name
+		}
+	}
+}
+
+// rpmOwner consulta o comando rpm quando o sistema analisado tem uma base do rpm
+func (p *packageIndex) rpmOwner(file string) string {
+	found := false
+	for _, dir := range rpmDBDirs {
+		if _, err := os.Stat(util.JoinMount(p.mountPoint, dir)); err == nil {
+			found = true
+			break
+		}
+	}
+	if !found || !util.HasCommand("rpm") {
+		return ""
+	}
+
+	args := []string{"-qf", "--queryformat", "%{NAME}", file}
+	if p.mountPoint != "" {
+		args = append([]string{"--root", p.mountPoint}, args...)
+	}
+	output, err := exec.Command("rpm", args...).Output()
+	if err != nil {
+		return ""
+	}
+
+	owner := strings.TrimSpace(string(output))
+	p.owners[file] = owner
+	return owner
+}
+
+// usrMergeVariants retorna o caminho e seu equivalente com ou sem /usr, pois em sistemas
+// com /usr unificado o pacote pode ter registrado qualquer um dos dois
+func usrMergeVariants(file string) []string {
+	for _, dir := range []string{"/bin/", "/sbin/", "/lib/", "/lib64/"} {
+		if strings.HasPrefix(file, "/usr"+dir) {
+			return []string{file, strings.TrimPrefix(file, "/usr")}
+		}
+		if strings.HasPrefix(file, dir) {
+			return []string{file, "/usr" + file}
+		}
+	}
+	return []string{file}
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/atomicfile"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// Políticas de correção dos serviços do systemd
+const (
+	// PolicyDisable remove os links que habilitam a unit, como o systemctl disable
+	PolicyDisable = "disable"
+
+	// PolicyMask cria um link da unit para /dev/null, como o systemctl mask
+	PolicyMask = "mask"
+
+	// ActionReport apenas reporta o serviço, sem correção automática
+	ActionReport = "report"
+)
+
+const (
+	// adminUnitDir é o diretório de units do administrador, onde ficam os links de habilitação
+	adminUnitDir = "/etc/systemd/system"
+
+	// presetPath é o preset gravado para que o primeiro boot não habilite de novo as units desabilitadas
+	presetPath = "/etc/systemd/system-preset/00-hardshell.preset"
+
+	// initDir é o diretório dos scripts de inicialização SysV
+	initDir = "/etc/init.d"
+)
+
+// remediation é a correção de um serviço encontrado em um sistema de arquivos montado
+type remediation struct {
+	// Unit é a unit do systemd que será desabilitada ou mascarada
+	Unit string
+
+	// Units são as units mascaradas junto (ex: o serviço ativado por um socket)
+	Units []string
+
+	// Entry é a entrada do inetd ou do xinetd que será desabilitada
+	Entry *inetdEntry
+
+	// Script é o script SysV ou OpenRC que será desabilitado
+	Script *initScript
+
+	Policy string
+}
+
+// remediator aplica as correções diretamente nos arquivos do sistema em mountPoint,
+// sem executar o systemctl
+type remediator struct {
+	mountPoint string
+	graph      *unitGraph
+	done       map[string]bool
+}
+
+// validPolicy verifica se a política de correção é conhecida
+func validPolicy(policy string) bool {
+	return policy == PolicyDisable || policy == PolicyMask
+}
+
+// offlineCommand gera o comando do systemctl equivalente à correção offline
+func offlineCommand(mountPoint, policy string, units ...string) string {
+	return fmt.Sprintf("systemctl --root=%s %s %s", mountPoint, policy, strings.Join(units, " "))
+}
+
+// applyOffline aplica as correções. Units são tratadas uma única vez, mesmo que reportadas por várias regras.
+func (a *Analyzer) applyOffline(fixes []remediation) error {
+	r := &remediator{mountPoint: a.mountPoint, graph: newUnitGraph(a.mountPoint), done: make(map[string]bool)}
+
+	var entries []inetdEntry
+	for _, fix := range fixes {
+		if fix.Entry != nil {
+			key := fmt.Sprintf("%s:%d", fix.Entry.File, fix.Entry.Line)
+			if !r.done[key] {
+				r.done[key] = true
+				entries = append(entries, *fix.Entry)
+			}
+			continue
+		}
+		if fix.Script != nil {
+			if !r.done[fix.Script.Path] {
+				r.done[fix.Script.Path] = true
+				if err := r.disableScript(*fix.Script); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if r.done[fix.Unit] {
+			continue
+		}
+		r.done[fix.Unit] = true
+
+		var err error
+		if fix.Policy == PolicyMask {
+			err = r.mask(append([]string{fix.Unit}, fix.Units...)...)
+		} else {
+			err = r.disable(fix.Unit)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return disableEntries(a.mountPoint, entries)
+}
+
+// disable desabilita uma unit e as listadas em seu Also=, removendo os links em
+// /etc/systemd/system e desabilitando o script SysV de mesmo nome. Se a unit ainda for
+// iniciada no boot (ex: link em um diretório .wants do pacote), ela é mascarada.
+func (r *remediator) disable(name string) error {
+	names := []string{name}
+	for i := 0; i < len(names); i++ {
+		for _, also := range r.graph.load(names[i]).Also {
+			names = util.AppendUnique(names, also)
+		}
+	}
+
+	for _, unitName := range names {
+		if err := r.removeLinks(unitName); err != nil {
+			return err
+		}
+		if err := r.disableSysV(unitBaseName(unitName)); err != nil {
+			return err
+		}
+	}
+
+	if r.graph.firstBoot() {
+		if err := r.addPreset(names); err != nil {
+			return err
+		}
+	}
+
+	// O grafo é refeito para conferir o resultado
+	r.graph = newUnitGraph(r.mountPoint)
+	for _, bootUnit := range r.graph.bootUnits() {
+		if bootUnit.Name == name {
+			fmt.Printf("%s continua habilitado por %s; a unit será mascarada\n", name, bootUnit.Via)
+			return r.mask(name)
+		}
+	}
+	return nil
+}
+
+// removeLinks remove os links de /etc/systemd/system que habilitam a unit: entradas
+// de diretórios .wants/.requires/.upholds com o nome da unit (ou de uma instância do
+// template) e aliases que apontam para o arquivo da unit
+func (r *remediator) removeLinks(name string) error {
+	unitFile := r.graph.load(name).Path
+	root := util.JoinMount(r.mountPoint, adminUnitDir)
+
+	var links []string
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink == 0 {
+			return nil
+		}
+
+		linkName := filepath.Base(file)
+		rel := r.graph.rootPath(file)
+		dir := filepath.Base(filepath.Dir(file))
+		inDeps := strings.HasSuffix(dir, ".wants") || strings.HasSuffix(dir, ".requires") || strings.HasSuffix(dir, ".upholds")
+
+		switch {
+		case inDeps && (linkName == name || isInstanceOf(linkName, name)):
+			links = append(links, file)
+		case linkName == name || linkName == "default.target":
+			// O próprio arquivo da unit e o target padrão não são links de habilitação
+		case unitFile != "":
+			if _, resolved, ok := r.graph.followLink(rel); ok && resolved == unitFile {
+				links = append(links, file)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao percorrer %s: %w", root, err)
+	}
+
+	for _, link := range links {
+		target, _ := os.Readlink(link)
+		if err := os.Remove(link); err != nil {
+			return fmt.Errorf("erro ao remover %s: %w", link, err)
+		}
+		fmt.Printf("Removido link %s -> %s\n", link, target)
+	}
+	return nil
+}
+
+// isInstanceOf verifica se um nome é uma instância do template (ex: telnet@1.service de telnet@.service)
+func isInstanceOf(name, template string) bool {
+	return templateName(name) == template && name != template
+}
+
+// mask cria links para /dev/null em /etc/systemd/system, que impedem as units de serem
+// iniciadas mesmo quando outra unit depende delas
+func (r *remediator) mask(names ...string) error {
+	for _, name := range names {
+		link := util.JoinMount(r.mountPoint, filepath.Join(adminUnitDir, name))
+
+		info, err := os.Lstat(link)
+		switch {
+		case err == nil && info.Mode()&os.ModeSymlink == 0:
+			// Como o systemctl mask, não sobrescreve a unit do administrador e segue com as demais
+			fmt.Printf("Aviso: não é possível mascarar %s: %s é um arquivo de unit do administrador; revise-o manualmente\n", name, link)
+			continue
+		case err == nil:
+			if target, _ := os.Readlink(link); target == "/dev/null" {
+				continue
+			}
+			if err := os.Remove(link); err != nil {
+				return fmt.Errorf("erro ao remover %s: %w", link, err)
+			}
+		}
+
+		if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+			return fmt.Errorf("erro ao criar diretório %s: %w", filepath.Dir(link), err)
+		}
+		if err := os.Symlink("/dev/null", link); err != nil {
+			return fmt.Errorf("erro ao mascarar %s: %w", name, err)
+		}
+		fmt.Printf("Mascarado %s (%s -> /dev/null)\n", name, link)
+	}
+	return nil
+}
+
+// addPreset grava regras "disable" em um preset lido antes dos demais, para que o
+// systemctl preset-all do primeiro boot não habilite as units de novo
+func (r *remediator) addPreset(names []string) error {
+	file := util.JoinMount(r.mountPoint, presetPath)
+
+	data, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("erro ao ler %s: %w", file, err)
+	}
+	content := string(data)
+	if content == "" {
+		content = atomicfile.Header
+	}
+
+	changed := false
+	for _, name := range names {
+		line := "disable " + name
+		if !util.ContainsString(strings.Split(content, "\n"), line) {
+			content += line + "\n"
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório %s: %w", filepath.Dir(file), err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		return fmt.Errorf("erro ao gravar %s: %w", file, err)
+	}
+	fmt.Printf("Preset gravado em %s: %s\n", file, strings.Join(names, ", "))
+	return nil
+}
+
+// disableSysV desabilita um script SysV como o update-rc.d disable: os links S<NN><nome>
+// dos diretórios rcN.d passam a K<100-NN><nome>
+func (r *remediator) disableSysV(name string) error {
+	if findInitScript(r.mountPoint, name) == "" {
+		return nil
+	}
+
+	for _, dir := range runlevelDirs(r.mountPoint, []string{"0", "1", "2", "3", "4", "5", "6", "S"}) {
+		links, _ := filepath.Glob(filepath.Join(util.JoinMount(r.mountPoint, dir), "S[0-9][0-9]"+name))
+		sort.Strings(links)
+		for _, link := range links {
+			if err := renameLink(link, sysvDisabledLink(link)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// disableScript desabilita um script de inicialização: no OpenRC, remove os links dos
+// runlevels, como o rc-update del; no SysV, desabilita os links de todos os runlevels
+func (r *remediator) disableScript(script initScript) error {
+	if !script.OpenRC {
+		return r.disableSysV(script.Name)
+	}
+
+	for _, link := range script.Links {
+		file := util.JoinMount(r.mountPoint, link)
+		target, _ := os.Readlink(file)
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("erro ao remover %s: %w", file, err)
+		}
+		fmt.Printf("Removido link %s -> %s\n", file, target)
+	}
+	return nil
+}
+
+// renameLink renomeia um link de runlevel
+func renameLink(link, disabled string) error {
+	if err := os.Rename(link, disabled); err != nil {
+		return fmt.Errorf("erro ao desabilitar %s: %w", link, err)
+	}
+	fmt.Printf("Desabilitado %s -> %s\n", link, filepath.Base(disabled))
+	return nil
+}
+
+// disableEntries desabilita as entradas do inetd e do xinetd. As alterações de cada arquivo
+// são feitas da última para a primeira linha, para que os números de linha continuem válidos.
+func disableEntries(mountPoint string, entries []inetdEntry) error {
+	byFile := make(map[string][]inetdEntry)
+	var files []string
+	for _, entry := range entries {
+		if _, ok := byFile[entry.File]; !ok {
+			files = append(files, entry.File)
+		}
+		byFile[entry.File] = append(byFile[entry.File], entry)
+	}
+
+	for _, file := range files {
+		path := util.JoinMount(mountPoint, file)
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("erro ao verificar %s: %w", path, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("erro ao ler %s: %w", path, err)
+		}
+		lines := strings.Split(string(data), "\n")
+
+		fileEntries := byFile[file]
+		sort.Slice(fileEntries, func(i, j int) bool { return fileEntries[i].editLine() > fileEntries[j].editLine() })
+
+		for _, entry := range fileEntries {
+			lines = entry.disable(lines)
+		}
+
+		if err := os.WriteFile(path+".bak", data, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("erro ao criar backup de %s: %w", path, err)
+		}
+		fmt.Printf("Backup criado em %s.bak\n", path)
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("erro ao gravar %s: %w", path, err)
+		}
+		for _, entry := range fileEntries {
+			fmt.Printf("Desabilitado %s em %s\n", entry.Service, path)
+		}
+	}
+	return nil
+}
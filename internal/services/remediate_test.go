@@ -0,0 +1,198 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// machineID marca o sistema como já iniciado, para que os presets não sejam aplicados
+const machineID = "0123456789abcdef0123456789abcdef\n"
+
+// bootsUnit verifica se a unit é iniciada no boot do sistema em root
+func bootsUnit(root, name string) bool {
+	_, found := bootMap(newUnitGraph(root).bootUnits())[name]
+	return found
+}
+
+// linkTarget retorna o destino de um link do sistema em root, ou vazio se não for um link
+func linkTarget(root, path string) string {
+	target, _ := os.Readlink(filepath.Join(root, path))
+	return target
+}
+
+func TestApplyOfflineDisable(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/machine-id":                                           machineID,
+		"/lib/systemd/system/multi-user.target":                     "[Unit]\nDescription=Multi-User\n",
+		"/lib/systemd/system/rsync.service":                         "[Service]\nExecStart=/usr/bin/rsync --daemon\n[Install]\nWantedBy=multi-user.target\nAlias=rsyncd.service\n",
+		"/etc/systemd/system/rsyncd.service":                        "->/lib/systemd/system/rsync.service",
+		"/etc/systemd/system/multi-user.target.wants/rsync.service": "->/lib/systemd/system/rsync.service",
+		"/etc/init.d/rsync":                                         "#!/bin/sh\n",
+		"/etc/rc2.d/S01rsync":                                       "->../init.d/rsync",
+		"/etc/rc3.d/S20rsync":                                       "->../init.d/rsync",
+	})
+
+	a := NewAnalyzer(root)
+	if err := a.applyOffline([]remediation{{Unit: "rsync.service", Policy: PolicyDisable}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, link := range []string{"/etc/systemd/system/multi-user.target.wants/rsync.service", "/etc/systemd/system/rsyncd.service"} {
+		if _, err := os.Lstat(filepath.Join(root, link)); !os.IsNotExist(err) {
+			t.Errorf("%s deveria ser removido: %v", link, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "/lib/systemd/system/rsync.service")); err != nil {
+		t.Errorf("o arquivo da unit não deveria ser removido: %v", err)
+	}
+	if bootsUnit(root, "rsync.service") {
+		t.Error("rsync.service continua no boot")
+	}
+
+	// O script SysV de mesmo nome é desabilitado como pelo update-rc.d disable
+	for _, link := range []string{"/etc/rc2.d/K99rsync", "/etc/rc3.d/K80rsync"} {
+		if target := linkTarget(root, link); target != "../init.d/rsync" {
+			t.Errorf("%s -> %q, esperado ../init.d/rsync", link, target)
+		}
+	}
+}
+
+func TestApplyOfflineMasksVendorEnabled(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/machine-id":                                           machineID,
+		"/lib/systemd/system/multi-user.target":                     "[Unit]\nDescription=Multi-User\n",
+		"/lib/systemd/system/telnet.socket":                         "[Socket]\nListenStream=23\nAccept=yes\n",
+		"/lib/systemd/system/multi-user.target.wants/telnet.socket": "->../telnet.socket",
+	})
+
+	// O link do pacote não pode ser removido, então a unit é mascarada
+	a := NewAnalyzer(root)
+	if err := a.applyOffline([]remediation{{Unit: "telnet.socket", Policy: PolicyDisable}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if target := linkTarget(root, "/etc/systemd/system/telnet.socket"); target != "/dev/null" {
+		t.Errorf("telnet.socket -> %q, esperado /dev/null", target)
+	}
+	if bootsUnit(root, "telnet.socket") {
+		t.Error("telnet.socket continua no boot")
+	}
+}
+
+func TestApplyOfflineFirstBootPreset(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/lib/systemd/system/multi-user.target":        "[Unit]\nWants=sockets.target\n",
+		"/lib/systemd/system/sockets.target":           "[Unit]\nDescription=Sockets\n",
+		"/lib/systemd/system/rsh.service":              "[Service]\nExecStart=/usr/sbin/in.rshd\n[Install]\nWantedBy=multi-user.target\nAlso=rsh.socket\n",
+		"/lib/systemd/system/rsh.socket":               "[Socket]\nListenStream=514\n[Install]\nWantedBy=sockets.target\n",
+		"/lib/systemd/system-preset/90-default.preset": "enable *\n",
+	})
+
+	if !bootsUnit(root, "rsh.socket") {
+		t.Fatal("os presets deveriam habilitar rsh.socket no primeiro boot")
+	}
+
+	a := NewAnalyzer(root)
+	if err := a.applyOffline([]remediation{{Unit: "rsh.service", Policy: PolicyDisable}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, presetPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"disable rsh.service", "disable rsh.socket"} {
+		if !strings.Contains(string(data), line+"\n") {
+			t.Errorf("preset sem %q:\n%s", line, data)
+		}
+	}
+	for _, name := range []string{"rsh.service", "rsh.socket"} {
+		if bootsUnit(root, name) {
+			t.Errorf("%s continua no boot", name)
+		}
+	}
+	if _, err := os.Lstat(filepath.Join(root, "/etc/systemd/system/rsh.service")); !os.IsNotExist(err) {
+		t.Errorf("rsh.service não deveria ser mascarada quando o preset basta: %v", err)
+	}
+}
+
+func TestApplyOfflineMask(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/machine-id":                                           machineID,
+		"/lib/systemd/system/multi-user.target":                     "[Unit]\nDescription=Multi-User\n",
+		"/lib/systemd/system/telnet.socket":                         "[Socket]\nListenStream=23\nAccept=yes\n",
+		"/lib/systemd/system/telnet@.service":                       "[Service]\nExecStart=-/usr/sbin/in.telnetd\n",
+		"/etc/systemd/system/multi-user.target.wants/telnet.socket": "->/lib/systemd/system/telnet.socket",
+		"/etc/systemd/system/telnet@.service":                       "->/lib/systemd/system/telnet@.service",
+	})
+
+	// Duas regras que reportam a mesma unit geram uma única correção
+	fix := remediation{Unit: "telnet.socket", Units: []string{"telnet@.service"}, Policy: PolicyMask}
+	a := NewAnalyzer(root)
+	if err := a.applyOffline([]remediation{fix, fix}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"telnet.socket", "telnet@.service"} {
+		if target := linkTarget(root, "/etc/systemd/system/"+name); target != "/dev/null" {
+			t.Errorf("%s -> %q, esperado /dev/null", name, target)
+		}
+	}
+	if bootsUnit(root, "telnet.socket") {
+		t.Error("telnet.socket continua no boot")
+	}
+}
+
+func TestApplyOfflineMaskSkipsAdminUnit(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/machine-id":                     machineID,
+		"/etc/systemd/system/rsh.service":     "[Service]\nExecStart=/usr/sbin/in.rshd\n",
+		"/lib/systemd/system/telnet.socket":   "[Socket]\nListenStream=23\nAccept=yes\n",
+		"/lib/systemd/system/telnet@.service": "[Service]\nExecStart=-/usr/sbin/in.telnetd\n",
+	})
+
+	// A unit do administrador não é sobrescrita, e as demais correções continuam
+	a := NewAnalyzer(root)
+	fixes := []remediation{
+		{Unit: "rsh.service", Policy: PolicyMask},
+		{Unit: "telnet.socket", Units: []string{"telnet@.service"}, Policy: PolicyMask},
+	}
+	if err := a.applyOffline(fixes); err != nil {
+		t.Fatal(err)
+	}
+
+	if target := linkTarget(root, "/etc/systemd/system/rsh.service"); target != "" {
+		t.Errorf("rsh.service -> %q, esperado o arquivo do administrador", target)
+	}
+	for _, name := range []string{"telnet.socket", "telnet@.service"} {
+		if target := linkTarget(root, "/etc/systemd/system/"+name); target != "/dev/null" {
+			t.Errorf("%s -> %q, esperado /dev/null", name, target)
+		}
+	}
+}
+
+func TestApplyOfflineOpenRC(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/init.d/telnetd":            "#!/sbin/openrc-run\n",
+		"/etc/runlevels/default/telnetd": "->/etc/init.d/telnetd",
+		"/etc/runlevels/boot/telnetd":    "->/etc/init.d/telnetd",
+	})
+
+	scripts := readInitScripts(root)
+	if len(scripts) != 1 || !scripts[0].OpenRC {
+		t.Fatalf("scripts = %+v, esperado telnetd do OpenRC", scripts)
+	}
+
+	a := NewAnalyzer(root)
+	if err := a.applyOffline([]remediation{{Script: &scripts[0]}}); err != nil {
+		t.Fatal(err)
+	}
+	if scripts := readInitScripts(root); len(scripts) != 0 {
+		t.Errorf("scripts habilitados = %+v, esperado nenhum", scripts)
+	}
+	if _, err := os.Stat(filepath.Join(root, "/etc/init.d/telnetd")); err != nil {
+		t.Errorf("o script não deveria ser removido: %v", err)
+	}
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// sandboxDropIn é o nome do drop-in de endurecimento sugerido para cada serviço
+const sandboxDropIn = "50-hardshell-sandbox.conf"
+
+// dangerousCapabilities são as capabilities que permitem escapar do sandbox ou comprometer o kernel
+var dangerousCapabilities = []string{
+	"CAP_SYS_ADMIN", "CAP_SYS_MODULE", "CAP_SYS_PTRACE", "CAP_SYS_RAWIO", "CAP_SYS_BOOT",
+	"CAP_SYS_TIME", "CAP_MAC_ADMIN", "CAP_MAC_OVERRIDE", "CAP_BPF", "CAP_DAC_READ_SEARCH",
+}
+
+// exposureLevels são as faixas de exposição usadas pelo systemd-analyze security
+var exposureLevels = []struct {
+	Max      float64
+	Name     string
+	Severity report.Severity
+}{
+	{1.0, "SAFE", report.SeverityInfo},
+	{2.9, "OK", report.SeverityInfo},
+	{4.9, "MEDIUM", report.SeverityInfo},
+	{7.9, "EXPOSED", report.SeverityWarning},
+	{10.0, "UNSAFE", report.SeverityWarning},
+}
+
+// SandboxAnalyzer é o analisador do isolamento (sandboxing) dos serviços do systemd
+type SandboxAnalyzer struct {
+	mountPoint string
+	checks     []sandboxCheck
+}
+
+// sandboxCheck é uma diretiva de isolamento avaliada em cada serviço
+type sandboxCheck struct {
+	Directive string
+
+	// Weight é o peso da diretiva na exposição; a soma dos pesos é 100
+	Weight int
+
+	// Evaluate retorna a fração da proteção obtida (0 a 1) e a descrição da configuração atual
+	Evaluate func(settings serviceSettings) (float64, string)
+
+	// Suggestion são as linhas sugeridas no drop-in quando a proteção não é completa
+	Suggestion []string
+}
+
+// serviceSettings são as diretivas da seção [Service] de uma unit, após os drop-ins
+type serviceSettings map[string][]string
+
+// value retorna o último valor atribuído a uma diretiva
+func (s serviceSettings) value(key string) string {
+	values := s[key]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// enabled verifica se uma diretiva booleana está habilitada
+func (s serviceSettings) enabled(key string) bool {
+	return parseBool(s.value(key))
+}
+
+// sandboxResult é a avaliação do isolamento de um serviço
+type sandboxResult struct {
+	Unit     string
+	Files    []string
+	Exposure float64
+	Failed   []string
+	Lines    []string
+}
+
+// NewSandboxAnalyzer cria um novo analisador de isolamento dos serviços
+func NewSandboxAnalyzer(mountPoint string) *SandboxAnalyzer {
+	return &SandboxAnalyzer{
+		mountPoint: mountPoint,
+		checks:     getDefaultSandboxChecks(),
+	}
+}
+
+// Analyze calcula a exposição de cada serviço iniciado no boot, de 0 (isolado) a 10 (sem isolamento)
+func (a *SandboxAnalyzer) Analyze() ([]report.Issue, error) {
+	results, err := a.evaluate()
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []report.Issue
+	for _, result := range results {
+		// Serviços que passam em todas as verificações não são reportados
+		if len(result.Failed) == 0 {
+			continue
+		}
+		level, severity := exposureLevel(result.Exposure)
+		issue := report.Issue{
+			Category:     "sandbox",
+			Severity:     severity,
+			Key:          result.Unit,
+			Description:  fmt.Sprintf("%s tem exposição %.1f (%s)", result.Unit, result.Exposure, level),
+			CurrentValue: fmt.Sprintf("%.1f %s", result.Exposure, level),
+			Source:       strings.Join(result.Files, ", "),
+		}
+		issue.Description += ": " + strings.Join(result.Failed, ", ")
+		var directives []string
+		for _, line := range result.Lines {
+			if !strings.HasPrefix(line, "#") {
+				directives = append(directives, line)
+			}
+		}
+		issue.RecommendedValue = strings.Join(directives, "; ")
+		issue.FixCommand = a.fixCommand(result)
+		issues = append(issues, issue)
+	}
+
+	return issues, nil
+}
+
+// evaluate avalia os serviços iniciados no boot, diretamente ou por um socket
+func (a *SandboxAnalyzer) evaluate() ([]sandboxResult, error) {
+	if !hasUnitDirs(a.mountPoint) {
+		return nil, fmt.Errorf("nenhum diretório de units do systemd encontrado")
+	}
+
+	var results []sandboxResult
+	graph := newUnitGraph(a.mountPoint)
+	for _, bootUnit := range graph.bootUnits() {
+		u := graph.load(bootUnit.Name)
+		if !strings.HasSuffix(u.Name, ".service") || u.Path == "" || u.Masked {
+			continue
+		}
+
+		files := append([]string{u.Path}, u.DropIns...)
+		settings := readServiceSettings(a.mountPoint, files)
+		if len(settings["ExecStart"]) == 0 {
+			continue
+		}
+
+		result := sandboxResult{Unit: bootUnit.Name, Files: files}
+		missing := 0.0
+		for _, check := range a.checks {
+			score, current := check.Evaluate(settings)
+			if score >= 1 {
+				continue
+			}
+			missing += float64(check.Weight) * (1 - score)
+			result.Failed = append(result.Failed, fmt.Sprintf("%s %s", check.Directive, current))
+			result.Lines = append(result.Lines, check.Suggestion...)
+		}
+		result.Exposure = missing / 10
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// HasSystemd verifica se o sistema em mountPoint tem units do systemd para avaliar
+func HasSystemd(mountPoint string) bool {
+	return hasUnitDirs(mountPoint)
+}
+
+// exposureLevel retorna a faixa e a severidade de uma exposição
+func exposureLevel(exposure float64) (string, report.Severity) {
+	for _, level := range exposureLevels {
+		if exposure <= level.Max {
+			return level.Name, level.Severity
+		}
+	}
+	last := exposureLevels[len(exposureLevels)-1]
+	return last.Name, last.Severity
+}
+
+// dropInPath retorna o drop-in de endurecimento sugerido para uma unit
+func dropInPath(unit string) string {
+	return filepath.Join(adminUnitDir, unit+".d", sandboxDropIn)
+}
+
+// dropInLines gera as linhas do drop-in de endurecimento
+func dropInLines(result sandboxResult) []string {
+	header := []string{"# Gerado pelo Hardshell. Teste o serviço antes de aplicar em produção.", "[Service]"}
+	return append(header, result.Lines...)
+}
+
+// fixCommand gera o comando que grava o drop-in sugerido e reinicia o serviço
+func (a *SandboxAnalyzer) fixCommand(result sandboxResult) string {
+	file := util.JoinMount(a.mountPoint, dropInPath(result.Unit))
+	var quoted []string
+	for _, line := range dropInLines(result) {
+		quoted = append(quoted, "'"+strings.ReplaceAll(line, "'", `'\''`)+"'")
+	}
+	cmd := fmt.Sprintf("mkdir -p %s && printf '%%s\\n' %s > %s", filepath.Dir(file), strings.Join(quoted, " "), file)
+	if a.mountPoint == "" {
+		cmd += fmt.Sprintf(" && systemctl daemon-reload && systemctl restart %s", result.Unit)
+	}
+	return cmd
+}
+
+// Fix não aplica os drop-ins: restrições de isolamento podem impedir o serviço de iniciar e
+// precisam ser testadas em cada sistema. O conteúdo sugerido para cada serviço é exibido.
+func (a *SandboxAnalyzer) Fix() error {
+	results, err := a.evaluate()
+	if err != nil {
+		return err
+	}
+
+	suggested := 0
+	for _, result := range results {
+		if len(result.Lines) == 0 {
+			continue
+		}
+		suggested++
+		fmt.Printf("Drop-in sugerido para %s (%s):\n", result.Unit, util.JoinMount(a.mountPoint, dropInPath(result.Unit)))
+		for _, line := range dropInLines(result) {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+
+	if suggested == 0 {
+		fmt.Println("Nenhum serviço precisa de endurecimento adicional.")
+		return nil
+	}
+	fmt.Println("Os drop-ins não são aplicados automaticamente; revise e teste cada serviço antes de gravá-los.")
+	return nil
+}
+
+// readServiceSettings lê as diretivas da seção [Service] do arquivo da unit e dos drop-ins,
+// em ordem. Uma atribuição vazia limpa os valores anteriores, como no systemd.
+func readServiceSettings(mountPoint string, files []string) serviceSettings {
+	settings := make(serviceSettings)
+	for _, file := range files {
+		f, err := os.Open(util.JoinMount(mountPoint, file))
+		if err != nil {
+			continue
+		}
+
+		section := ""
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+				section = line[1 : len(line)-1]
+				continue
+			}
+
+			parts := strings.SplitN(line, "=", 2)
+			if section != "Service" || len(parts) != 2 {
+				continue
+			}
+			key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+			if value == "" {
+				settings[key] = nil
+				continue
+			}
+			settings[key] = append(settings[key], value)
+		}
+		f.Close()
+	}
+	return settings
+}
+
+// listSetting interpreta uma diretiva de lista que pode ser uma lista de permissões ou, com
+// o prefixo ~, de negações. Como no systemd, a primeira atribuição define o tipo da lista.
+func listSetting(values []string) ([]string, bool) {
+	var items []string
+	deny := len(values) > 0 && strings.HasPrefix(values[0], "~")
+	for _, value := range values {
+		items = append(items, strings.Fields(strings.TrimPrefix(value, "~"))...)
+	}
+	return items, deny
+}
+
+// containsAll verifica se a lista contém todos os valores, sem diferenciar maiúsculas
+func containsAll(list, values []string) bool {
+	for _, value := range values {
+		found := false
+		for _, item := range list {
+			if strings.EqualFold(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// runsAsRoot verifica se o serviço é executado como root
+func runsAsRoot(settings serviceSettings) bool {
+	if settings.enabled("DynamicUser") {
+		return false
+	}
+	user := settings.value("User")
+	return user == "" || user == "root" || user == "0"
+}
+
+// getDefaultSandboxChecks retorna as diretivas avaliadas, com pesos inspirados no systemd-analyze security
+func getDefaultSandboxChecks() []sandboxCheck {
+	return []sandboxCheck{
+		{
+			Directive: "User=",
+			Weight:    25,
+			Evaluate: func(s serviceSettings) (float64, string) {
+				if runsAsRoot(s) {
+					return 0, "(executa como root)"
+				}
+				return 1, ""
+			},
+			Suggestion: []string{"# User=<usuário dedicado> ou DynamicUser=yes"},
+		},
+		{
+			Directive: "NoNewPrivileges=",
+			Weight:    15,
+			Evaluate: func(s serviceSettings) (float64, string) {
+				if s.enabled("NoNewPrivileges") {
+					return 1, ""
+				}
+				// O systemd força NoNewPrivileges quando filtros são usados por serviços sem root
+				if !runsAsRoot(s) && (len(s["SystemCallFilter"]) > 0 || len(s["RestrictAddressFamilies"]) > 0) {
+					return 1, ""
+				}
+				return 0, "(ausente)"
+			},
+			Suggestion: []string{"NoNewPrivileges=yes"},
+		},
+		{
+			Directive: "ProtectSystem=",
+			Weight:    15,
+			Evaluate: func(s serviceSettings) (float64, string) {
+				value := strings.ToLower(s.value("ProtectSystem"))
+				switch {
+				case value == "strict" || s.enabled("DynamicUser"):
+					return 1, ""
+				case value == "full":
+					return 0.7, "(full)"
+				case parseBool(value):
+					return 0.4, "(yes)"
+				}
+				return 0, "(ausente)"
+			},
+			Suggestion: []string{"ProtectSystem=strict", "# ReadWritePaths=<diretórios gravados pelo serviço>"},
+		},
+		{
+			Directive: "PrivateTmp=",
+			Weight:    10,
+			Evaluate: func(s serviceSettings) (float64, string) {
+				if s.enabled("PrivateTmp") || s.enabled("DynamicUser") {
+					return 1, ""
+				}
+				return 0, "(ausente)"
+			},
+			Suggestion: []string{"PrivateTmp=yes"},
+		},
+		{
+			Directive: "CapabilityBoundingSet=",
+			Weight:    15,
+			Evaluate: func(s serviceSettings) (float64, string) {
+				values, set := s["CapabilityBoundingSet"]
+				caps, deny := listSetting(values)
+				switch {
+				case !set:
+					return 0, "(todas as capabilities)"
+				case len(values) == 0:
+					// Uma atribuição vazia remove todas as capabilities
+					return 1, ""
+				case deny && containsAll(caps, dangerousCapabilities):
+					// A negação de todas as capabilities perigosas equivale à sugestão
+					return 1, ""
+				case deny && util.ContainsString(caps, "CAP_SYS_ADMIN"):
+					return 0.6, "(lista de negações incompleta)"
+				case deny:
+					return 0.2, "(CAP_SYS_ADMIN permitida)"
+				case util.ContainsString(caps, "CAP_SYS_ADMIN"):
+					return 0.3, "(CAP_SYS_ADMIN permitida)"
+				}
+				return 1, ""
+			},
+			Suggestion: []string{"CapabilityBoundingSet=~" + strings.Join(dangerousCapabilities, " ")},
+		},
+		{
+			Directive: "RestrictAddressFamilies=",
+			Weight:    10,
+			Evaluate: func(s serviceSettings) (float64, string) {
+				families, deny := listSetting(s["RestrictAddressFamilies"])
+				switch {
+				case len(families) == 0:
+					return 0, "(ausente)"
+				case deny:
+					return 0.5, "(lista de negações)"
+				case util.ContainsString(families, "AF_PACKET"):
+					return 0.5, "(AF_PACKET permitida)"
+				}
+				return 1, ""
+			},
+			Suggestion: []string{"RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6"},
+		},
+		{
+			Directive: "SystemCallFilter=",
+			Weight:    10,
+			Evaluate: func(s serviceSettings) (float64, string) {
+				_, deny := listSetting(s["SystemCallFilter"])
+				switch {
+				case len(s["SystemCallFilter"]) == 0:
+					return 0, "(ausente)"
+				case deny:
+					return 0.5, "(lista de negações)"
+				}
+				return 1, ""
+			},
+			Suggestion: []string{"SystemCallFilter=@system-service", "SystemCallErrorNumber=EPERM"},
+		},
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// hardenedUnit é um serviço que passa em todas as verificações de isolamento
+const hardenedUnit = `[Service]
+ExecStart=/usr/bin/daemon
+DynamicUser=yes
+NoNewPrivileges=yes
+CapabilityBoundingSet=
+RestrictAddressFamilies=AF_UNIX AF_INET AF_INET6
+SystemCallFilter=@system-service
+`
+
+// writeUnits cria um sistema com as units informadas habilitadas no multi-user.target
+func writeUnits(t *testing.T, units map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	wants := filepath.Join(root, "etc/systemd/system/multi-user.target.wants")
+	files := map[string]string{
+		"lib/systemd/system/multi-user.target": "[Unit]\nDescription=Multi-User\n",
+	}
+	for name, content := range units {
+		files["lib/systemd/system/"+name] = content
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(wants, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name := range units {
+		if err := os.Symlink("/lib/systemd/system/"+name, filepath.Join(wants, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestCapabilityBoundingSetScore(t *testing.T) {
+	var check sandboxCheck
+	for _, c := range getDefaultSandboxChecks() {
+		if c.Directive == "CapabilityBoundingSet=" {
+			check = c
+		}
+	}
+
+	tests := []struct {
+		values []string
+		want   float64
+	}{
+		{nil, 0},
+		{[]string{}, 1},
+		{[]string{"CAP_NET_BIND_SERVICE"}, 1},
+		{[]string{"CAP_NET_BIND_SERVICE CAP_SYS_ADMIN"}, 0.3},
+		{[]string{"~CAP_SYS_ADMIN"}, 0.6},
+		{[]string{"~CAP_SYS_MODULE"}, 0.2},
+		// A própria sugestão do drop-in precisa passar na verificação
+		{[]string{strings.TrimPrefix(check.Suggestion[0], "CapabilityBoundingSet=")}, 1},
+		{[]string{"~" + strings.Join(dangerousCapabilities[:5], " "), "~" + strings.Join(dangerousCapabilities[5:], " ")}, 1},
+	}
+
+	for _, tt := range tests {
+		settings := serviceSettings{}
+		if tt.values != nil {
+			settings["CapabilityBoundingSet"] = tt.values
+		}
+		if got, _ := check.Evaluate(settings); got != tt.want {
+			t.Errorf("CapabilityBoundingSet=%q = %v, esperado %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestSandboxSkipsSafeUnits(t *testing.T) {
+	root := writeUnits(t, map[string]string{
+		"safe.service":    hardenedUnit,
+		"exposed.service": "[Service]\nExecStart=/usr/bin/daemon\n",
+	})
+
+	issues, err := NewSandboxAnalyzer(root).Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Key != "exposed.service" {
+		var keys []string
+		for _, issue := range issues {
+			keys = append(keys, issue.Key)
+		}
+		t.Errorf("units reportadas = %v, esperado apenas exposed.service", keys)
+	}
+}
+
+func TestSandboxSuggestionPasses(t *testing.T) {
+	root := writeUnits(t, map[string]string{
+		"exposed.service": "[Service]\nExecStart=/usr/bin/daemon\nUser=daemon\n",
+	})
+
+	a := NewSandboxAnalyzer(root)
+	results, err := a.evaluate()
+	if err != nil || len(results) != 1 {
+		t.Fatalf("evaluate = %v, %v", results, err)
+	}
+
+	// Depois de gravar o drop-in sugerido, o serviço não deve ser reportado de novo
+	dropIn := filepath.Join(root, dropInPath("exposed.service"))
+	if err := os.MkdirAll(filepath.Dir(dropIn), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dropIn, []byte(strings.Join(dropInLines(results[0]), "\n")+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := a.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("o drop-in sugerido não resolveu os problemas: %+v", issues)
+	}
+}
@@ -0,0 +1,597 @@
+package services
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// unitDirs são os diretórios de units do systemd, do mais para o menos prioritário
+var unitDirs = []string{
+	"/etc/systemd/system",
+	"/run/systemd/system",
+	"/usr/local/lib/systemd/system",
+	"/usr/lib/systemd/system",
+	"/lib/systemd/system",
+}
+
+// presetDirs são os diretórios de presets, usados pelo systemd no primeiro boot
+var presetDirs = []string{
+	"/etc/systemd/system-preset",
+	"/run/systemd/system-preset",
+	"/usr/local/lib/systemd/system-preset",
+	"/usr/lib/systemd/system-preset",
+	"/lib/systemd/system-preset",
+}
+
+// fallbackTarget é o target usado quando default.target não está definido
+const fallbackTarget = "multi-user.target"
+
+// unit é uma unit do systemd lida do sistema de arquivos
+type unit struct {
+	Name string
+
+	// Path é o arquivo da unit no sistema analisado; vazio quando não foi encontrado
+	Path string
+
+	// DropIns são os drop-ins (<nome>.d/*.conf) da unit, na ordem em que o systemd os aplica
+	DropIns []string
+
+	// Masked indica uma unit mascarada (link para /dev/null), que nunca é iniciada
+	Masked bool
+
+	// Dependencies são as units iniciadas junto com esta (Wants, Requires, BindsTo e diretórios .wants/.requires)
+	Dependencies []string
+
+	// WantedBy e Also vêm da seção [Install], usada para habilitar a unit por preset
+	WantedBy []string
+	Also     []string
+
+	// Service é a unit ativada por um socket (Service=, ou o mesmo nome com .service)
+	Service string
+	Accept  bool
+
+	// Listen são os endereços em que um socket escuta
+	Listen []string
+}
+
+// bootUnit é uma unit que será iniciada no boot, direta ou indiretamente
+type bootUnit struct {
+	Name string
+
+	// Via é a unit que puxou esta para o boot
+	Via string
+
+	// Socket indica um serviço que não é iniciado no boot, mas é ativado sob demanda por um socket
+	Socket bool
+}
+
+// unitGraph resolve as dependências das units de um sistema de arquivos sem consultar o systemd
+type unitGraph struct {
+	mountPoint string
+	units      map[string]*unit
+
+	// presetWants são as dependências criadas pelos presets no primeiro boot (target -> units)
+	presetWants map[string][]string
+
+	// linked são os arquivos apontados pelos links em diretórios .wants/.requires, usados
+	// quando a unit não está em nenhum diretório de units (ex: link para /opt/app/app.service)
+	linked map[string]string
+}
+
+// newUnitGraph cria um resolvedor de units para o sistema em mountPoint
+func newUnitGraph(mountPoint string) *unitGraph {
+	g := &unitGraph{
+		mountPoint:  mountPoint,
+		units:       make(map[string]*unit),
+		presetWants: make(map[string][]string),
+		linked:      make(map[string]string),
+	}
+
+	if g.firstBoot() {
+		g.applyPresets()
+	}
+
+	return g
+}
+
+// hasUnitDirs verifica se algum diretório de units existe no sistema analisado
+func hasUnitDirs(mountPoint string) bool {
+	for _, dir := range unitDirs {
+		if info, err := os.Stat(util.JoinMount(mountPoint, dir)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultTarget retorna o target iniciado no boot
+func (g *unitGraph) defaultTarget() string {
+	if target := g.load("default.target"); target.Name != "default.target" {
+		return target.Name
+	}
+	return fallbackTarget
+}
+
+// bootUnits percorre as dependências a partir do target padrão e retorna as units que
+// seriam iniciadas no boot, além dos serviços ativados pelos sockets iniciados
+func (g *unitGraph) bootUnits() []bootUnit {
+	start := g.defaultTarget()
+
+	via := map[string]string{start: ""}
+	socket := make(map[string]bool)
+	queue := []string{start}
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		current := g.load(name)
+		if current.Masked {
+			continue
+		}
+
+		var next []string
+		for _, dep := range current.Dependencies {
+			next = append(next, g.load(dep).Name)
+		}
+
+		for _, dep := range next {
+			if _, seen := via[dep]; seen || g.load(dep).Masked {
+				continue
+			}
+			via[dep] = current.Name
+			queue = append(queue, dep)
+		}
+
+		// O serviço de um socket só é iniciado na primeira conexão
+		if strings.HasSuffix(current.Name, ".socket") && current.Service != "" {
+			service := g.load(current.Service).Name
+			if _, seen := via[service]; !seen && !g.load(service).Masked {
+				via[service] = current.Name
+				socket[service] = true
+			}
+		}
+	}
+
+	var units []bootUnit
+	for name, from := range via {
+		if name == start {
+			continue
+		}
+		units = append(units, bootUnit{Name: name, Via: from, Socket: socket[name]})
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].Name < units[j].Name })
+
+	return units
+}
+
+// load lê uma unit, seguindo aliases, templates, drop-ins e diretórios .wants/.requires
+func (g *unitGraph) load(name string) *unit {
+	if cached, ok := g.units[name]; ok {
+		return cached
+	}
+
+	u := &unit{Name: name}
+	g.units[name] = u
+
+	file, masked, alias := g.find(name)
+	if masked {
+		u.Masked = true
+		return u
+	}
+
+	// Um link com outro nome é um alias: a unit é a de destino
+	if alias != "" && alias != name {
+		target := g.load(alias)
+		g.units[name] = target
+		return target
+	}
+
+	instance := ""
+	template := templateName(name)
+	if file == "" {
+		file = g.linked[name]
+	}
+	if file == "" && template != "" {
+		file, masked, _ = g.find(template)
+		u.Masked = masked
+		instance = unitInstance(name)
+	}
+	u.Path = file
+
+	if file != "" {
+		g.parseUnitFile(u, file, instance)
+	}
+
+	// Drop-ins (<nome>.d/*.conf) podem acrescentar dependências
+	u.DropIns = g.dropIns(name, template)
+	for _, dropIn := range u.DropIns {
+		g.parseUnitFile(u, dropIn, instance)
+	}
+
+	// Units habilitadas são links em <nome>.wants/ e <nome>.requires/
+	for _, dir := range unitDirs {
+		for _, suffix := range []string{".wants", ".requires"} {
+			entries, err := os.ReadDir(util.JoinMount(g.mountPoint, filepath.Join(dir, name+suffix)))
+			if err != nil {
+				continue
+			}
+			for _, entry := range entries {
+				link := filepath.Join(dir, name+suffix, entry.Name())
+				if _, resolved, ok := g.followLink(link); ok && resolved != link && g.linked[entry.Name()] == "" {
+					g.linked[entry.Name()] = resolved
+				}
+				u.Dependencies = util.AppendUnique(u.Dependencies, entry.Name())
+			}
+		}
+	}
+
+	for _, dep := range g.presetWants[name] {
+		u.Dependencies = util.AppendUnique(u.Dependencies, dep)
+	}
+
+	if strings.HasSuffix(name, ".socket") && u.Service == "" {
+		u.Service = strings.TrimSuffix(name, ".socket") + ".service"
+		if u.Accept {
+			u.Service = strings.TrimSuffix(name, ".socket") + "@.service"
+		}
+	}
+
+	return u
+}
+
+// dropIns retorna os drop-ins de uma unit, de sua instância e do tipo (ex: service.d), ordenados
+// pelo nome do arquivo. Um arquivo com o mesmo nome em um diretório mais prioritário prevalece.
+func (g *unitGraph) dropIns(name, template string) []string {
+	bases := []string{name}
+	if template != "" {
+		bases = append(bases, template)
+	}
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		bases = append(bases, name[dot+1:])
+	}
+
+	chosen := make(map[string]string)
+	var names []string
+	for _, dir := range unitDirs {
+		for _, base := range bases {
+			matches, _ := filepath.Glob(util.JoinMount(g.mountPoint, filepath.Join(dir, base+".d", "*.conf")))
+			for _, match := range matches {
+				if _, ok := chosen[filepath.Base(match)]; !ok {
+					chosen[filepath.Base(match)] = g.rootPath(match)
+					names = append(names, filepath.Base(match))
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var files []string
+	for _, file := range names {
+		files = append(files, chosen[file])
+	}
+	return files
+}
+
+// find procura o arquivo de uma unit nos diretórios de units. Retorna o caminho do arquivo,
+// se a unit está mascarada e, quando o arquivo é um link para outra unit, o nome dela.
+func (g *unitGraph) find(name string) (string, bool, string) {
+	for _, dir := range unitDirs {
+		file := filepath.Join(dir, name)
+		info, err := os.Lstat(util.JoinMount(g.mountPoint, file))
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		if info.Mode()&os.ModeSymlink == 0 {
+			return file, false, ""
+		}
+
+		target, resolved, ok := g.followLink(file)
+		if !ok {
+			continue
+		}
+		if target == "/dev/null" {
+			return "", true, ""
+		}
+		return resolved, false, filepath.Base(target)
+	}
+
+	return "", false, ""
+}
+
+// followLink segue um link dentro do sistema analisado. Links absolutos são relativos à
+// raiz do sistema analisado, não à do host. Retorna o destino do primeiro link e o arquivo final.
+func (g *unitGraph) followLink(file string) (string, string, bool) {
+	first := ""
+	current := file
+
+	for i := 0; i < 40; i++ {
+		target, err := os.Readlink(util.JoinMount(g.mountPoint, current))
+		if err != nil {
+			if _, err := os.Stat(util.JoinMount(g.mountPoint, current)); err != nil {
+				return "", "", false
+			}
+			return first, current, true
+		}
+
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(current), target)
+		}
+		if first == "" {
+			first = target
+		}
+		if target == "/dev/null" {
+			return target, target, true
+		}
+		current = target
+	}
+
+	return "", "", false
+}
+
+// parseUnitFile lê as dependências e a seção [Install] de um arquivo de unit
+func (g *unitGraph) parseUnitFile(u *unit, file, instance string) {
+	f, err := os.Open(util.JoinMount(g.mountPoint, file))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	section := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = line[1 : len(line)-1]
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		values := expandSpecifiers(strings.Fields(parts[1]), instance)
+
+		switch section + "." + key {
+		case "Unit.Wants", "Unit.Requires", "Unit.BindsTo", "Unit.Upholds":
+			for _, value := range values {
+				u.Dependencies = util.AppendUnique(u.Dependencies, value)
+			}
+		case "Install.WantedBy", "Install.RequiredBy":
+			u.WantedBy = resetOrAppend(u.WantedBy, values)
+		case "Install.Also":
+			u.Also = resetOrAppend(u.Also, values)
+		case "Socket.Service":
+			if len(values) > 0 {
+				u.Service = values[0]
+			}
+		case "Socket.ListenStream", "Socket.ListenDatagram", "Socket.ListenSequentialPacket":
+			u.Listen = resetOrAppend(u.Listen, values)
+		case "Socket.Accept":
+			u.Accept = len(values) > 0 && parseBool(values[0])
+		}
+	}
+}
+
+// firstBoot verifica se o sistema ainda não foi iniciado, quando o systemd aplica os presets
+func (g *unitGraph) firstBoot() bool {
+	data, err := os.ReadFile(util.JoinMount(g.mountPoint, "/etc/machine-id"))
+	if err != nil {
+		return true
+	}
+	id := strings.TrimSpace(string(data))
+	return id == "" || id == "uninitialized"
+}
+
+// applyPresets habilita, como o systemctl preset-all do primeiro boot, as units permitidas pelos presets
+func (g *unitGraph) applyPresets() {
+	rules := g.presetRules()
+	if len(rules) == 0 {
+		return
+	}
+
+	seen := make(map[string]bool)
+	var enable func(name string)
+	enable = func(name string) {
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+
+		u := g.load(name)
+		for _, target := range u.WantedBy {
+			g.presetWants[target] = util.AppendUnique(g.presetWants[target], u.Name)
+		}
+		for _, also := range u.Also {
+			enable(also)
+		}
+	}
+
+	for _, name := range g.unitNames() {
+		if templateName(name) == "" && presetEnabled(rules, name) {
+			enable(name)
+		}
+	}
+
+	// As dependências criadas precisam ser consideradas nas units já carregadas
+	for target, wants := range g.presetWants {
+		if u, ok := g.units[target]; ok {
+			for _, dep := range wants {
+				u.Dependencies = util.AppendUnique(u.Dependencies, dep)
+			}
+		}
+	}
+}
+
+// presetRule é uma linha "enable <padrão>" ou "disable <padrão>" de um arquivo de preset
+type presetRule struct {
+	Enable  bool
+	Pattern string
+}
+
+// presetRules lê os arquivos de preset, ordenados pelo nome; o primeiro diretório com um nome prevalece
+func (g *unitGraph) presetRules() []presetRule {
+	chosen := make(map[string]string)
+	var names []string
+	for _, dir := range presetDirs {
+		entries, err := os.ReadDir(util.JoinMount(g.mountPoint, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), ".preset") {
+				continue
+			}
+			if _, ok := chosen[entry.Name()]; !ok {
+				chosen[entry.Name()] = filepath.Join(dir, entry.Name())
+				names = append(names, entry.Name())
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var rules []presetRule
+	for _, name := range names {
+		data, err := os.ReadFile(util.JoinMount(g.mountPoint, chosen[name]))
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], ";") {
+				continue
+			}
+			switch fields[0] {
+			case "enable":
+				rules = append(rules, presetRule{Enable: true, Pattern: fields[1]})
+			case "disable":
+				rules = append(rules, presetRule{Enable: false, Pattern: fields[1]})
+			}
+		}
+	}
+
+	return rules
+}
+
+// presetEnabled aplica a primeira regra de preset que corresponde à unit; sem regra, a unit é habilitada
+func presetEnabled(rules []presetRule, name string) bool {
+	for _, rule := range rules {
+		if matched, _ := path.Match(rule.Pattern, name); matched {
+			return rule.Enable
+		}
+	}
+	return true
+}
+
+// unitNames lista os nomes de todas as units instaladas
+func (g *unitGraph) unitNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range unitDirs {
+		entries, err := os.ReadDir(util.JoinMount(g.mountPoint, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() || seen[name] || !isUnitName(name) {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rootPath converte um caminho do host em um caminho do sistema analisado
+func (g *unitGraph) rootPath(hostPath string) string {
+	if g.mountPoint == "" {
+		return hostPath
+	}
+	rel, err := filepath.Rel(g.mountPoint, hostPath)
+	if err != nil {
+		return hostPath
+	}
+	return "/" + rel
+}
+
+// unitTypes são os sufixos das units do systemd
+var unitTypes = []string{".service", ".socket", ".target", ".timer", ".path", ".mount", ".automount", ".swap", ".slice", ".scope", ".device"}
+
+// isUnitName verifica se um nome de arquivo é uma unit
+func isUnitName(name string) bool {
+	for _, suffix := range unitTypes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// templateName retorna o template de uma instância (ex: getty@tty1.service -> getty@.service)
+func templateName(name string) string {
+	at := strings.Index(name, "@")
+	dot := strings.LastIndex(name, ".")
+	if at < 0 || dot < at {
+		return ""
+	}
+	if at+1 == dot {
+		return ""
+	}
+	return name[:at+1] + name[dot:]
+}
+
+// unitInstance retorna a instância de uma unit (ex: getty@tty1.service -> tty1)
+func unitInstance(name string) string {
+	at := strings.Index(name, "@")
+	dot := strings.LastIndex(name, ".")
+	if at < 0 || dot < at {
+		return ""
+	}
+	return name[at+1 : dot]
+}
+
+// expandSpecifiers substitui %i pela instância; nomes com outros especificadores são ignorados
+func expandSpecifiers(values []string, instance string) []string {
+	var expanded []string
+	for _, value := range values {
+		value = strings.ReplaceAll(value, "%i", instance)
+		if !strings.Contains(value, "%") {
+			expanded = append(expanded, value)
+		}
+	}
+	return expanded
+}
+
+// resetOrAppend acrescenta valores a uma lista; uma atribuição vazia limpa a lista, como no systemd
+func resetOrAppend(list, values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	for _, value := range values {
+		list = util.AppendUnique(list, value)
+	}
+	return list
+}
+
+// parseBool interpreta os valores booleanos aceitos pelo systemd
+func parseBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "yes", "true", "on":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree grava os arquivos informados em um mountPoint temporário. Conteúdos iniciados
+// por "->" criam um link simbólico para o destino informado.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for path, content := range files {
+		file := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if len(content) > 2 && content[:2] == "->" {
+			if err := os.Symlink(content[2:], file); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// bootMap indexa as units do boot pelo nome
+func bootMap(units []bootUnit) map[string]bootUnit {
+	byName := make(map[string]bootUnit)
+	for _, u := range units {
+		byName[u.Name] = u
+	}
+	return byName
+}
+
+func TestUnitGraphBootUnits(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/machine-id":                       "0123456789abcdef0123456789abcdef\n",
+		"/lib/systemd/system/multi-user.target": "[Unit]\nWants=getty.target\n",
+		"/lib/systemd/system/getty.target":      "[Unit]\nDescription=Login Prompts\n",
+		"/etc/systemd/system/default.target":    "->/lib/systemd/system/multi-user.target",
+
+		// Alias: o link com outro nome aponta para a unit real
+		"/lib/systemd/system/ssh.service":                                "[Service]\nExecStart=/usr/sbin/sshd -D\n[Install]\nWantedBy=multi-user.target\nAlias=sshd.service\n",
+		"/etc/systemd/system/sshd.service":                               "->/lib/systemd/system/ssh.service",
+		"/etc/systemd/system/multi-user.target.wants/sshd.service":       "->/lib/systemd/system/ssh.service",
+		"/etc/systemd/system/multi-user.target.requires/rpcbind.service": "->/lib/systemd/system/rpcbind.service",
+		"/lib/systemd/system/rpcbind.service":                            "[Unit]\nRequires=rpcbind.socket\n",
+		"/lib/systemd/system/rpcbind.socket":                             "[Socket]\nListenStream=111\n",
+
+		// Socket com Accept=yes ativa instâncias do template
+		"/etc/systemd/system/multi-user.target.wants/telnet.socket": "->/lib/systemd/system/telnet.socket",
+		"/lib/systemd/system/telnet.socket":                         "[Socket]\nListenStream=23\nAccept=yes\n",
+		"/lib/systemd/system/telnet@.service":                       "[Service]\nExecStart=-/usr/sbin/in.telnetd\nStandardInput=socket\n",
+
+		// Unit habilitada, mas mascarada
+		"/etc/systemd/system/multi-user.target.wants/avahi-daemon.service": "->/lib/systemd/system/avahi-daemon.service",
+		"/lib/systemd/system/avahi-daemon.service":                         "[Service]\nExecStart=/usr/sbin/avahi-daemon\n",
+		"/etc/systemd/system/avahi-daemon.service":                         "->/dev/null",
+
+		// Instância de template e dependência acrescentada por drop-in
+		"/etc/systemd/system/getty.target.wants/getty@tty1.service": "->/lib/systemd/system/getty@.service",
+		"/lib/systemd/system/getty@.service":                        "[Service]\nExecStart=-/sbin/agetty %I\n",
+		"/etc/systemd/system/multi-user.target.wants/cups.service":  "->/lib/systemd/system/cups.service",
+		"/lib/systemd/system/cups.service":                          "[Service]\nExecStart=/usr/sbin/cupsd -l\n",
+		"/etc/systemd/system/cups.service.d/browsed.conf":           "[Unit]\nWants=cups-browsed.service\n",
+		"/lib/systemd/system/cups-browsed.service":                  "[Service]\nExecStart=/usr/sbin/cups-browsed\n",
+	})
+
+	g := newUnitGraph(root)
+	if target := g.defaultTarget(); target != "multi-user.target" {
+		t.Errorf("defaultTarget() = %q, esperado multi-user.target", target)
+	}
+
+	units := bootMap(g.bootUnits())
+	tests := []struct {
+		name   string
+		found  bool
+		via    string
+		socket bool
+	}{
+		{name: "ssh.service", found: true, via: "multi-user.target"},
+		{name: "sshd.service"},
+		{name: "rpcbind.service", found: true, via: "multi-user.target"},
+		{name: "rpcbind.socket", found: true, via: "rpcbind.service"},
+		{name: "telnet.socket", found: true, via: "multi-user.target"},
+		{name: "telnet@.service", found: true, via: "telnet.socket", socket: true},
+		{name: "avahi-daemon.service"},
+		{name: "getty@tty1.service", found: true, via: "getty.target"},
+		{name: "cups-browsed.service", found: true, via: "cups.service"},
+	}
+
+	for _, tt := range tests {
+		u, found := units[tt.name]
+		if found != tt.found {
+			t.Errorf("%s no boot = %v, esperado %v", tt.name, found, tt.found)
+			continue
+		}
+		if found && (u.Via != tt.via || u.Socket != tt.socket) {
+			t.Errorf("%s = %+v, esperado Via=%s Socket=%v", tt.name, u, tt.via, tt.socket)
+		}
+	}
+
+	if u := g.load("avahi-daemon.service"); !u.Masked {
+		t.Error("avahi-daemon.service deveria estar mascarada")
+	}
+	if u := g.load("cups.service"); !reflect.DeepEqual(u.DropIns, []string{"/etc/systemd/system/cups.service.d/browsed.conf"}) {
+		t.Errorf("drop-ins = %v", u.DropIns)
+	}
+}
+
+func TestUnitGraphPresets(t *testing.T) {
+	files := map[string]string{
+		"/lib/systemd/system/multi-user.target": "[Unit]\nWants=sockets.target\n",
+		"/lib/systemd/system/sockets.target":    "[Unit]\nDescription=Sockets\n",
+		"/lib/systemd/system/ssh.service":       "[Service]\nExecStart=/usr/sbin/sshd -D\n[Install]\nWantedBy=multi-user.target\nAlso=ssh.socket\n",
+		"/lib/systemd/system/ssh.socket":        "[Socket]\nListenStream=22\n[Install]\nWantedBy=sockets.target\n",
+		"/lib/systemd/system/telnet.socket":     "[Socket]\nListenStream=23\n[Install]\nWantedBy=sockets.target\n",
+		"/lib/systemd/system/rsync.service":     "[Service]\nExecStart=/usr/bin/rsync --daemon\n[Install]\nWantedBy=multi-user.target\n",
+
+		// O arquivo de /etc substitui o de mesmo nome em /lib; os demais são lidos pela ordem do nome
+		"/lib/systemd/system-preset/90-systemd.preset": "enable rsync.service\ndisable *\n",
+		"/etc/systemd/system-preset/90-systemd.preset": "disable *\n",
+		"/usr/lib/systemd/system-preset/10-ssh.preset": "# comentário\nenable ssh.service\n",
+	}
+
+	tests := []struct {
+		name      string
+		machineID string
+		want      []string
+	}{
+		// No primeiro boot os presets habilitam as units, incluindo as de Also=
+		{name: "primeiro boot", want: []string{"sockets.target", "ssh.service", "ssh.socket"}},
+		{name: "machine-id não inicializado", machineID: "uninitialized\n", want: []string{"sockets.target", "ssh.service", "ssh.socket"}},
+		// Em um sistema já iniciado, os presets não são aplicados
+		{name: "sistema iniciado", machineID: "0123456789abcdef0123456789abcdef\n", want: []string{"sockets.target"}},
+	}
+
+	for _, tt := range tests {
+		tree := map[string]string{}
+		for path, content := range files {
+			tree[path] = content
+		}
+		if tt.machineID != "" {
+			tree["/etc/machine-id"] = tt.machineID
+		}
+
+		var names []string
+		for _, u := range newUnitGraph(writeTree(t, tree)).bootUnits() {
+			names = append(names, u.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("%s: bootUnits() = %v, esperado %v", tt.name, names, tt.want)
+		}
+	}
+}
+
+func TestTemplateName(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		instance string
+	}{
+		{"getty@tty1.service", "getty@.service", "tty1"},
+		{"telnet@0-10.0.0.1:23-10.0.0.2:5555.service", "telnet@.service", "0-10.0.0.1:23-10.0.0.2:5555"},
+		{"getty@.service", "", ""},
+		{"ssh.service", "", ""},
+	}
+
+	for _, tt := range tests {
+		if got := templateName(tt.name); got != tt.template {
+			t.Errorf("templateName(%q) = %q, esperado %q", tt.name, got, tt.template)
+		}
+		if got := unitInstance(tt.name); got != tt.instance {
+			t.Errorf("unitInstance(%q) = %q, esperado %q", tt.name, got, tt.instance)
+		}
+	}
+}
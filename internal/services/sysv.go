@@ -0,0 +1,355 @@
+package services
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// initScriptDirs são os diretórios dos scripts de inicialização (Debian/OpenRC e Red Hat)
+var initScriptDirs = []string{initDir, "/etc/rc.d/init.d"}
+
+// sysvRunlevelBases são os diretórios que contêm os diretórios rcN.d
+var sysvRunlevelBases = []string{"/etc", "/etc/rc.d"}
+
+// defaultSysVRunlevels são os runlevels multiusuário verificados quando o inittab não define o padrão
+var defaultSysVRunlevels = []string{"2", "3", "5"}
+
+const (
+	// inittabPath define o runlevel padrão do SysV init (linha id:N:initdefault:)
+	inittabPath = "/etc/inittab"
+
+	// openrcRunlevelDir contém um diretório por runlevel do OpenRC, com links para os scripts
+	openrcRunlevelDir = "/etc/runlevels"
+
+	// openrcStartedDir contém um link para cada serviço iniciado pelo OpenRC
+	openrcStartedDir = "/run/openrc/started"
+)
+
+// openrcRunlevels são os runlevels do OpenRC percorridos no boot
+var openrcRunlevels = []string{"sysinit", "boot", "default"}
+
+// initScript é um script de inicialização SysV ou OpenRC habilitado ou em execução
+type initScript struct {
+	Name   string
+	Path   string
+	OpenRC bool
+
+	// Links são os links que habilitam o script (rcN.d/S<NN><nome> ou runlevels/<nível>/<nome>)
+	Links     []string
+	Runlevels []string
+
+	// Running só é verificado no sistema em execução
+	Running bool
+}
+
+// Describe descreve onde o script está habilitado e se está em execução
+func (s initScript) Describe() string {
+	var parts []string
+	if len(s.Runlevels) > 0 {
+		label := "nos runlevels"
+		if len(s.Runlevels) == 1 {
+			label = "no runlevel"
+		}
+		parts = append(parts, fmt.Sprintf("habilitado %s %s", label, strings.Join(s.Runlevels, ", ")))
+	}
+	if s.Running {
+		parts = append(parts, "em execução")
+	}
+	return fmt.Sprintf("%s está %s", s.Path, strings.Join(parts, " e "))
+}
+
+// FixCommand gera o comando que para e desabilita o script. Em um mountPoint, os links são
+// alterados diretamente, como faria o update-rc.d ou o rc-update no sistema montado.
+func (s initScript) FixCommand(mountPoint string) string {
+	if mountPoint != "" {
+		var cmds []string
+		for _, link := range s.Links {
+			if s.OpenRC {
+				cmds = append(cmds, fmt.Sprintf("rm %s", util.JoinMount(mountPoint, link)))
+				continue
+			}
+			cmds = append(cmds, fmt.Sprintf("mv %s %s", util.JoinMount(mountPoint, link), util.JoinMount(mountPoint, sysvDisabledLink(link))))
+		}
+		return strings.Join(cmds, " && ")
+	}
+
+	if s.OpenRC {
+		cmd := fmt.Sprintf("rc-service %s stop", s.Name)
+		if len(s.Runlevels) > 0 {
+			cmd += fmt.Sprintf(" && rc-update del %s %s", s.Name, strings.Join(s.Runlevels, " "))
+		}
+		return cmd
+	}
+	if util.HasCommand("update-rc.d") || !util.HasCommand("chkconfig") {
+		return fmt.Sprintf("service %s stop && update-rc.d %s disable", s.Name, s.Name)
+	}
+	return fmt.Sprintf("service %s stop && chkconfig %s off", s.Name, s.Name)
+}
+
+// hasInitScripts verifica se o sistema analisado tem scripts de inicialização
+func hasInitScripts(mountPoint string) bool {
+	for _, dir := range append(append([]string{}, initScriptDirs...), openrcRunlevelDir) {
+		if info, err := os.Stat(util.JoinMount(mountPoint, dir)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// readInitScripts lê, sem executar os scripts, os serviços habilitados nos runlevels do
+// OpenRC (/etc/runlevels) ou do SysV (rcN.d/S*). No sistema em execução, scripts não
+// habilitados que estão rodando também são retornados.
+func readInitScripts(mountPoint string) []initScript {
+	scripts := make(map[string]*initScript)
+	openrc := isOpenRC(mountPoint)
+
+	get := func(name string) *initScript {
+		if script, ok := scripts[name]; ok {
+			return script
+		}
+		path := findInitScript(mountPoint, name)
+		if path == "" {
+			return nil
+		}
+		scripts[name] = &initScript{Name: name, Path: path, OpenRC: openrc}
+		return scripts[name]
+	}
+
+	if openrc {
+		for _, level := range openrcRunlevels {
+			dir := filepath.Join(openrcRunlevelDir, level)
+			entries, _ := os.ReadDir(util.JoinMount(mountPoint, dir))
+			for _, entry := range entries {
+				if script := get(entry.Name()); script != nil {
+					script.Links = append(script.Links, filepath.Join(dir, entry.Name()))
+					script.Runlevels = util.AppendUnique(script.Runlevels, level)
+				}
+			}
+		}
+	} else {
+		for _, dir := range sysvRunlevelDirs(mountPoint) {
+			links, _ := filepath.Glob(filepath.Join(util.JoinMount(mountPoint, dir), "S[0-9][0-9]*"))
+			sort.Strings(links)
+			for _, link := range links {
+				if script := get(filepath.Base(link)[3:]); script != nil {
+					script.Links = append(script.Links, filepath.Join(dir, filepath.Base(link)))
+					script.Runlevels = util.AppendUnique(script.Runlevels, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(dir), "rc"), ".d"))
+				}
+			}
+		}
+	}
+
+	// O estado de execução só existe no sistema em execução
+	if mountPoint == "" {
+		running := runningProcesses()
+		for _, dir := range initScriptDirs {
+			entries, _ := os.ReadDir(dir)
+			for _, entry := range entries {
+				if entry.IsDir() || !isRunning(entry.Name(), openrc, running) {
+					continue
+				}
+				if script := get(entry.Name()); script != nil {
+					script.Running = true
+				}
+			}
+		}
+	}
+
+	var result []initScript
+	for _, script := range scripts {
+		result = append(result, *script)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// isOpenRC verifica se o sistema usa o OpenRC, que organiza os runlevels em /etc/runlevels
+func isOpenRC(mountPoint string) bool {
+	info, err := os.Stat(util.JoinMount(mountPoint, openrcRunlevelDir))
+	return err == nil && info.IsDir()
+}
+
+// findInitScript retorna o caminho do script de inicialização com o nome informado
+func findInitScript(mountPoint, name string) string {
+	for _, dir := range initScriptDirs {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(util.JoinMount(mountPoint, path)); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// sysvRunlevelDirs retorna os diretórios rcN.d dos runlevels iniciados no boot: o runlevel
+// padrão do inittab ou, sem ele, os runlevels multiusuário
+func sysvRunlevelDirs(mountPoint string) []string {
+	levels := defaultSysVRunlevels
+	if level := inittabDefault(mountPoint); level != "" {
+		levels = []string{level}
+	}
+	return runlevelDirs(mountPoint, append([]string{"S"}, levels...))
+}
+
+// runlevelDirs retorna os diretórios rcN.d existentes dos runlevels informados. Em sistemas
+// em que /etc/rcN.d é um link para /etc/rc.d/rcN.d, cada diretório aparece uma única vez.
+func runlevelDirs(mountPoint string, levels []string) []string {
+	var dirs []string
+	var seen []os.FileInfo
+	for _, base := range sysvRunlevelBases {
+		for _, level := range levels {
+			dir := filepath.Join(base, "rc"+level+".d")
+			info, err := os.Stat(util.JoinMount(mountPoint, dir))
+			if err != nil || !info.IsDir() {
+				continue
+			}
+
+			duplicate := false
+			for _, other := range seen {
+				if os.SameFile(info, other) {
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				seen = append(seen, info)
+				dirs = append(dirs, dir)
+			}
+		}
+	}
+	return dirs
+}
+
+// inittabDefault lê o runlevel padrão do /etc/inittab
+func inittabDefault(mountPoint string) string {
+	file, err := os.Open(util.JoinMount(mountPoint, inittabPath))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ":")
+		if len(fields) >= 3 && fields[2] == "initdefault" && fields[1] != "" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// sysvDisabledLink retorna o nome que o update-rc.d disable dá a um link S<NN><nome>: K<100-NN><nome>
+func sysvDisabledLink(link string) string {
+	base := filepath.Base(link)
+	priority, err := strconv.Atoi(base[1:3])
+	if err != nil {
+		return link
+	}
+	return filepath.Join(filepath.Dir(link), fmt.Sprintf("K%02d%s", 100-priority, base[3:]))
+}
+
+// runningProcesses lista os nomes (/proc/<pid>/comm) dos processos em execução
+func runningProcesses() map[string]bool {
+	names := make(map[string]bool)
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return names
+	}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil {
+			continue
+		}
+		if data, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm")); err == nil {
+			names[strings.TrimSpace(string(data))] = true
+		}
+	}
+	return names
+}
+
+// isRunning verifica em /proc se o serviço de um script está em execução: pelo registro
+// de serviços iniciados do OpenRC, pelo arquivo de PID ou pelo nome do programa
+func isRunning(name string, openrc bool, running map[string]bool) bool {
+	if openrc {
+		if _, err := os.Lstat(filepath.Join(openrcStartedDir, name)); err == nil {
+			return true
+		}
+	}
+
+	path := findInitScript("", name)
+	pidfiles, programs := scriptTargets(path)
+	pidfiles = append(pidfiles, filepath.Join("/run", name+".pid"), filepath.Join("/var/run", name+".pid"))
+	for _, pidfile := range pidfiles {
+		if pidAlive(pidfile) {
+			return true
+		}
+	}
+
+	for _, program := range programs {
+		// O kernel limita o comm a 15 caracteres
+		if len(program) > 15 {
+			program = program[:15]
+		}
+		if running[program] {
+			return true
+		}
+	}
+	return false
+}
+
+// scriptTargets lê, sem executar o script, os arquivos de PID (PIDFILE=, pidfile=) e os
+// programas iniciados (DAEMON=, command=, exec=) declarados nele
+func scriptTargets(path string) ([]string, []string) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil
+	}
+	defer file.Close()
+
+	var pidfiles, programs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		eq := strings.Index(line, "=")
+		if eq <= 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line[eq+1:])
+		if len(fields) == 0 {
+			continue
+		}
+		key := strings.TrimPrefix(line[:eq], "export ")
+		value := strings.Trim(fields[0], `"'`)
+		if value == "" || strings.ContainsAny(value, "$`") {
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "pidfile":
+			pidfiles = util.AppendUnique(pidfiles, value)
+		case "daemon", "command", "exec", "prog":
+			programs = util.AppendUnique(programs, filepath.Base(value))
+		}
+	}
+	return pidfiles, programs
+}
+
+// pidAlive verifica se o processo de um arquivo de PID existe em /proc
+func pidAlive(pidfile string) bool {
+	data, err := os.ReadFile(pidfile)
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return false
+	}
+	_, err = os.Stat(filepath.Join("/proc", strconv.Itoa(pid)))
+	return err == nil
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSysvDisabledLink(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"/etc/rc2.d/S01telnetd", "/etc/rc2.d/K99telnetd"},
+		{"/etc/rc3.d/S20rsync", "/etc/rc3.d/K80rsync"},
+		{"/etc/rc.d/rc5.d/S99local", "/etc/rc.d/rc5.d/K01local"},
+		{"/etc/rc2.d/Sxxtelnetd", "/etc/rc2.d/Sxxtelnetd"},
+	}
+
+	for _, tt := range tests {
+		if got := sysvDisabledLink(tt.link); got != tt.want {
+			t.Errorf("sysvDisabledLink(%q) = %q, esperado %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestInittabDefault(t *testing.T) {
+	tests := []struct {
+		name    string
+		inittab string
+		want    string
+	}{
+		{"runlevel 3", "# comentário\nsi::sysinit:/etc/init.d/rcS\nid:3:initdefault:\n", "3"},
+		{"espaços", "  id:5:initdefault:  \n", "5"},
+		{"sem initdefault", "si::sysinit:/etc/init.d/rcS\n", ""},
+		{"initdefault vazio", "id::initdefault:\n", ""},
+	}
+
+	for _, tt := range tests {
+		root := writeTree(t, map[string]string{inittabPath: tt.inittab})
+		if got := inittabDefault(root); got != tt.want {
+			t.Errorf("%s: inittabDefault() = %q, esperado %q", tt.name, got, tt.want)
+		}
+	}
+
+	if got := inittabDefault(t.TempDir()); got != "" {
+		t.Errorf("sem inittab: inittabDefault() = %q, esperado vazio", got)
+	}
+}
+
+func TestReadInitScriptsSysV(t *testing.T) {
+	tests := []struct {
+		name    string
+		inittab string
+		want    map[string][]string
+	}{
+		{
+			// Sem inittab valem os runlevels multiusuário 2, 3 e 5, além do S
+			name: "runlevels padrão",
+			want: map[string][]string{"networking": {"S"}, "rsync": {"2", "5"}, "telnetd": {"3"}},
+		},
+		{
+			name:    "runlevel do inittab",
+			inittab: "id:3:initdefault:\n",
+			want:    map[string][]string{"networking": {"S"}, "telnetd": {"3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		files := map[string]string{
+			"/etc/init.d/networking":   "#!/bin/sh\n",
+			"/etc/init.d/rsync":        "#!/bin/sh\n",
+			"/etc/init.d/telnetd":      "#!/bin/sh\n",
+			"/etc/rcS.d/S01networking": "->../init.d/networking",
+			"/etc/rc2.d/S02rsync":      "->../init.d/rsync",
+			"/etc/rc5.d/S02rsync":      "->../init.d/rsync",
+			"/etc/rc3.d/S20telnetd":    "->../init.d/telnetd",
+			// Links desabilitados, de runlevels que não são iniciados no boot e sem script são ignorados
+			"/etc/rc3.d/K01rsync":   "->../init.d/rsync",
+			"/etc/rc1.d/S01telnetd": "->../init.d/telnetd",
+			"/etc/rc3.d/S05missing": "->../init.d/missing",
+		}
+		if tt.inittab != "" {
+			files[inittabPath] = tt.inittab
+		}
+
+		got := make(map[string][]string)
+		for _, script := range readInitScripts(writeTree(t, files)) {
+			if script.OpenRC || script.Path != filepath.Join(initDir, script.Name) {
+				t.Errorf("%s: script = %+v", tt.name, script)
+			}
+			got[script.Name] = script.Runlevels
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: runlevels = %v, esperado %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadInitScriptsRedHat(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/rc.d/init.d/xinetd":   "#!/bin/sh\n",
+		"/etc/rc.d/rc3.d/S56xinetd": "->../init.d/xinetd",
+		"/etc/rc.d/rc5.d/S56xinetd": "->../init.d/xinetd",
+		"/etc/inittab":              "id:5:initdefault:\n",
+	})
+	// Como no Red Hat, /etc/rc5.d é um link para /etc/rc.d/rc5.d e não deve ser lido duas vezes
+	if err := os.Symlink("rc.d/rc5.d", filepath.Join(root, "etc/rc5.d")); err != nil {
+		t.Fatal(err)
+	}
+
+	scripts := readInitScripts(root)
+	if len(scripts) != 1 {
+		t.Fatalf("scripts = %+v, esperado apenas xinetd", scripts)
+	}
+	want := initScript{Name: "xinetd", Path: "/etc/rc.d/init.d/xinetd", Links: []string{"/etc/rc5.d/S56xinetd"}, Runlevels: []string{"5"}}
+	if !reflect.DeepEqual(scripts[0], want) {
+		t.Errorf("script = %+v, esperado %+v", scripts[0], want)
+	}
+}
+
+func TestReadInitScriptsOpenRC(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/init.d/sshd":                 "#!/sbin/openrc-run\n",
+		"/etc/init.d/telnetd":              "#!/sbin/openrc-run\n",
+		"/etc/runlevels/boot/sshd":         "->/etc/init.d/sshd",
+		"/etc/runlevels/default/sshd":      "->/etc/init.d/sshd",
+		"/etc/runlevels/nonetwork/telnetd": "->/etc/init.d/telnetd",
+	})
+
+	// Apenas os runlevels percorridos no boot são considerados
+	scripts := readInitScripts(root)
+	want := []initScript{{
+		Name:      "sshd",
+		Path:      "/etc/init.d/sshd",
+		OpenRC:    true,
+		Links:     []string{"/etc/runlevels/boot/sshd", "/etc/runlevels/default/sshd"},
+		Runlevels: []string{"boot", "default"},
+	}}
+	if !reflect.DeepEqual(scripts, want) {
+		t.Errorf("scripts = %+v, esperado %+v", scripts, want)
+	}
+}
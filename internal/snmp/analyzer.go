@@ -0,0 +1,450 @@
+package snmp
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/services"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// defaultCommunities são as communities de fábrica, tentadas primeiro por qualquer scanner
+var defaultCommunities = []string{"public", "private"}
+
+// weakCommunities são communities comuns em listas de força bruta
+var weakCommunities = []string{"community", "snmp", "snmpd", "admin", "manager", "monitor", "secret", "password", "cisco", "default", "read", "write", "test"}
+
+// minCommunityLength é o tamanho mínimo de uma community que não seja trivial de adivinhar
+const minCommunityLength = 8
+
+// Analyzer é o analisador da configuração do snmpd
+type Analyzer struct {
+	mountPoint string
+	configPath string
+}
+
+// community é uma community SNMP v1/v2c, declarada diretamente (rocommunity, rwcommunity,
+// authcommunity) ou pelo modelo VACM (com2sec, group e access)
+type community struct {
+	Name   string
+	Source string
+	Write  bool
+
+	// Directive é a linha que declara a community
+	Directive directive
+
+	// Access é a linha access que concede escrita no modelo VACM
+	Access *directive
+}
+
+// user é um usuário SNMPv3 com acesso concedido (rouser, rwuser ou authuser)
+type user struct {
+	Name      string
+	Level     string
+	Directive directive
+}
+
+// NewAnalyzer cria um novo analisador do snmpd
+func NewAnalyzer(mountPoint string) *Analyzer {
+	return &Analyzer{
+		mountPoint: mountPoint,
+		configPath: configPath,
+	}
+}
+
+// Analyze verifica o snmpd.conf quando o snmpd é iniciado no boot
+func (a *Analyzer) Analyze() ([]report.Issue, error) {
+	issues, _, err := a.analyze()
+	return issues, err
+}
+
+// analyze retorna os problemas e as linhas que são comentadas na correção
+func (a *Analyzer) analyze() ([]report.Issue, []directive, error) {
+	if _, enabled := services.Enabled(a.mountPoint, "snmpd", "snmp"); !enabled {
+		return nil, nil, nil
+	}
+
+	directives, err := readConfig(a.mountPoint, a.configPath)
+	if os.IsNotExist(err) {
+		// Sem configuração, o snmpd não concede acesso a ninguém
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler configuração do snmpd: %w", err)
+	}
+
+	var issues []report.Issue
+	var fixes []directive
+
+	communities := readCommunities(directives)
+	local := localOnly(directives)
+	for _, c := range communities {
+		lower := strings.ToLower(c.Name)
+		switch {
+		case util.ContainsString(defaultCommunities, lower):
+			issues = append(issues, a.communityIssue(c, report.SeverityCritical,
+				fmt.Sprintf("Community SNMP padrão %q em %s", c.Name, c.Directive.Name)))
+			fixes = append(fixes, c.Directive)
+		case util.ContainsString(weakCommunities, lower) || len(c.Name) < minCommunityLength:
+			issue := a.communityIssue(c, report.SeverityWarning,
+				fmt.Sprintf("Community SNMP fraca %q em %s", c.Name, c.Directive.Name))
+			issue.FixCommand = ""
+			issues = append(issues, issue)
+		}
+
+		if c.Write {
+			description := fmt.Sprintf("Acesso de escrita via SNMP v1/v2c com a community %q, enviada em texto claro", c.Name)
+			if c.Access != nil {
+				description += fmt.Sprintf(" (concedido em %s)", c.Access.Source())
+			}
+			issues = append(issues, a.communityIssue(c, report.SeverityCritical, description))
+			fixes = append(fixes, c.Directive)
+		}
+
+		// Escutando apenas em loopback, a origem não restringe ninguém além dos usuários locais
+		if isUnrestricted(c.Source) && !local {
+			issues = append(issues, report.Issue{
+				Category:         "snmp",
+				Severity:         report.SeverityWarning,
+				Key:              c.Directive.Name,
+				Description:      fmt.Sprintf("Community %q aceita requisições de qualquer origem", c.Name),
+				CurrentValue:     sourceLabel(c.Source),
+				RecommendedValue: "rede de gerência (ex: 10.0.0.0/24)",
+				Source:           c.Directive.Source(),
+			})
+		}
+	}
+
+	users := readUsers(directives)
+	authPriv := hasPrivAccess(directives)
+	for _, u := range users {
+		if u.Level == "priv" {
+			authPriv = true
+			continue
+		}
+		issues = append(issues, report.Issue{
+			Category:         "snmp",
+			Severity:         report.SeverityWarning,
+			Key:              u.Directive.Name,
+			Description:      fmt.Sprintf("Usuário SNMPv3 %s não exige criptografia (nível %s)", u.Name, u.Level),
+			CurrentValue:     u.Level,
+			RecommendedValue: "priv",
+			Source:           u.Directive.Source(),
+		})
+	}
+
+	if !authPriv {
+		description := "Nenhum usuário SNMPv3 com authPriv (autenticação e criptografia) está configurado"
+		if len(communities) > 0 {
+			description += "; o acesso depende de communities v1/v2c em texto claro"
+		}
+		issues = append(issues, report.Issue{
+			Category:         "snmp",
+			Severity:         report.SeverityWarning,
+			Key:              "authPriv",
+			Description:      description,
+			RecommendedValue: "net-snmp-create-v3-user -ro -a SHA -x AES <usuário>",
+			Source:           a.configPath,
+		})
+	}
+
+	return issues, fixes, nil
+}
+
+// communityIssue cria o problema de uma community, corrigido comentando a linha que a declara
+func (a *Analyzer) communityIssue(c community, severity report.Severity, description string) report.Issue {
+	return report.Issue{
+		Category:         "snmp",
+		Severity:         severity,
+		Key:              c.Directive.Name,
+		Description:      description,
+		CurrentValue:     c.Name,
+		RecommendedValue: "SNMPv3 com authPriv (rouser <usuário> priv)",
+		Source:           c.Directive.Source(),
+		FixCommand:       fmt.Sprintf("sed -i '%ds/^/# /' %s", c.Directive.Line, util.JoinMount(a.mountPoint, c.Directive.File)),
+	}
+}
+
+// readCommunities lê as communities declaradas diretamente e as do modelo VACM
+func readCommunities(directives []directive) []community {
+	var communities []community
+	secNames := make(map[string][]int)
+	groups := make(map[string][]string)
+
+	for _, d := range directives {
+		switch d.Name {
+		case "rocommunity", "rocommunity6", "rwcommunity", "rwcommunity6":
+			communities = append(communities, community{
+				Name:      d.arg(0),
+				Source:    d.arg(1),
+				Write:     strings.HasPrefix(d.Name, "rw"),
+				Directive: d,
+			})
+		case "authcommunity":
+			// authcommunity TIPOS COMMUNITY [ORIGEM ...], com TIPOS como read,write
+			communities = append(communities, community{
+				Name:      d.arg(1),
+				Source:    d.arg(2),
+				Write:     util.ContainsString(strings.Split(strings.ToLower(d.arg(0)), ","), "write"),
+				Directive: d,
+			})
+		case "com2sec", "com2sec6":
+			// com2sec [-Cn CONTEXTO] NOME_SEGURANÇA ORIGEM COMMUNITY
+			args := d.Args
+			if len(args) > 0 && args[0] == "-Cn" && len(args) > 1 {
+				args = args[2:]
+			}
+			if len(args) < 3 {
+				continue
+			}
+			secNames[args[0]] = append(secNames[args[0]], len(communities))
+			communities = append(communities, community{Name: args[2], Source: args[1], Directive: d})
+		case "group":
+			// group GRUPO MODELO NOME_SEGURANÇA
+			if model := strings.ToLower(d.arg(1)); model == "v1" || model == "v2c" {
+				groups[d.arg(0)] = append(groups[d.arg(0)], d.arg(2))
+			}
+		}
+	}
+
+	// access GRUPO CONTEXTO MODELO NÍVEL PREFIXO LEITURA ESCRITA NOTIFICAÇÃO
+	for _, d := range directives {
+		if d.Name != "access" || len(d.Args) < 7 {
+			continue
+		}
+		model := strings.ToLower(d.arg(2))
+		if d.arg(6) == "none" || (model != "any" && model != "v1" && model != "v2c") {
+			continue
+		}
+		for _, secName := range groups[d.arg(0)] {
+			for _, i := range secNames[secName] {
+				if !communities[i].Write {
+					access := d
+					communities[i].Write = true
+					communities[i].Access = &access
+				}
+			}
+		}
+	}
+
+	return communities
+}
+
+// readUsers lê os usuários SNMPv3 com acesso concedido. O nível padrão do rouser e do rwuser é auth.
+func readUsers(directives []directive) []user {
+	var users []user
+	for _, d := range directives {
+		args := d.Args
+		switch d.Name {
+		case "rouser", "rwuser":
+		case "authuser":
+			if len(args) == 0 {
+				continue
+			}
+			args = args[1:]
+		default:
+			continue
+		}
+
+		// -s MODELO seleciona o modelo de segurança; apenas o usm tem usuários SNMPv3
+		if len(args) > 1 && args[0] == "-s" {
+			if strings.ToLower(args[1]) != "usm" {
+				continue
+			}
+			args = args[2:]
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		level := "auth"
+		if len(args) > 1 {
+			if l, ok := securityLevel(args[1]); ok {
+				level = l
+			}
+		}
+		users = append(users, user{Name: args[0], Level: level, Directive: d})
+	}
+
+	sort.SliceStable(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users
+}
+
+// hasPrivAccess verifica se alguma linha access concede acesso a usuários SNMPv3 com authPriv
+func hasPrivAccess(directives []directive) bool {
+	for _, d := range directives {
+		if level, _ := securityLevel(d.arg(3)); d.Name == "access" && strings.ToLower(d.arg(2)) == "usm" && level == "priv" {
+			return true
+		}
+	}
+	return false
+}
+
+// securityLevel normaliza um nível de segurança SNMPv3 para noauth, auth ou priv, aceitando
+// também os nomes longos (ex: authPriv, como no snmpd.conf padrão do Debian)
+func securityLevel(value string) (string, bool) {
+	switch strings.ToLower(value) {
+	case "noauth", "noauthnopriv":
+		return "noauth", true
+	case "auth", "authnopriv":
+		return "auth", true
+	case "priv", "authpriv":
+		return "priv", true
+	}
+	return "", false
+}
+
+// localOnly verifica se o snmpd escuta apenas em loopback ou em sockets Unix, segundo a última
+// linha agentAddress. Sem agentAddress, o snmpd escuta em udp:161 em todas as interfaces.
+func localOnly(directives []directive) bool {
+	var addresses []string
+	for _, d := range directives {
+		if d.Name == "agentaddress" {
+			addresses = strings.Split(strings.Join(d.Args, ","), ",")
+		}
+	}
+
+	local := false
+	for _, address := range addresses {
+		if address = strings.TrimSpace(address); address == "" {
+			continue
+		}
+		if !isLocalAddress(address) {
+			return false
+		}
+		local = true
+	}
+	return local
+}
+
+// isLocalAddress verifica se um endereço do agentAddress ([TRANSPORTE:]ENDEREÇO[:PORTA]) é local
+func isLocalAddress(address string) bool {
+	transport := ""
+	if i := strings.Index(address, ":"); i > 0 && isTransport(address[:i]) {
+		transport, address = strings.ToLower(address[:i]), address[i+1:]
+	}
+	if transport == "unix" {
+		return true
+	}
+
+	host := address
+	if strings.HasPrefix(host, "[") {
+		if end := strings.Index(host, "]"); end > 0 {
+			host = host[1:end]
+		}
+	} else if strings.HasSuffix(transport, "6") || strings.Count(host, ":") > 1 {
+		// Endereços IPv6 sem colchetes não têm porta
+	} else if i := strings.LastIndex(host, ":"); i >= 0 {
+		host = host[:i]
+	} else if _, err := strconv.Atoi(host); err == nil {
+		// Apenas a porta: escuta em todas as interfaces
+		return false
+	}
+
+	host = strings.ToLower(host)
+	return host == "localhost" || host == "ip6-localhost" || host == "::1" || strings.HasPrefix(host, "127.")
+}
+
+// isTransport verifica se o prefixo de um endereço do agentAddress é um transporte (ex: udp, tcp6)
+func isTransport(prefix string) bool {
+	switch strings.ToLower(prefix) {
+	case "udp", "udp6", "udpv6", "udpipv6", "tcp", "tcp6", "tcpv6", "tcpipv6", "unix", "dtlsudp", "tls", "tlstcp", "ipx", "aal5pvc":
+		return true
+	}
+	return false
+}
+
+// isUnrestricted verifica se a origem de uma community aceita qualquer endereço
+func isUnrestricted(source string) bool {
+	switch source {
+	case "", "default", "0.0.0.0/0", "0.0.0.0/0.0.0.0", "::/0", "0/0":
+		return true
+	}
+	// Opções como -V VISÃO ocupam a posição da origem quando ela é omitida
+	return strings.HasPrefix(source, "-") || strings.HasPrefix(source, ".")
+}
+
+// sourceLabel descreve a origem de uma community
+func sourceLabel(source string) string {
+	if source == "" || strings.HasPrefix(source, "-") || strings.HasPrefix(source, ".") {
+		return "qualquer origem"
+	}
+	return source
+}
+
+// Fix comenta as linhas das communities padrão e das communities com acesso de escrita,
+// mantendo um backup .bak de cada arquivo alterado. Os demais problemas exigem decisões
+// do administrador (redes de gerência e usuários SNMPv3) e não são corrigidos.
+func (a *Analyzer) Fix() error {
+	_, fixes, err := a.analyze()
+	if err != nil {
+		return err
+	}
+
+	if len(fixes) == 0 {
+		fmt.Println("Nenhuma community SNMP para desabilitar.")
+		return nil
+	}
+
+	byFile := make(map[string][]int)
+	var files []string
+	for _, d := range fixes {
+		if _, ok := byFile[d.File]; !ok {
+			files = append(files, d.File)
+		}
+		if !containsInt(byFile[d.File], d.Line) {
+			byFile[d.File] = append(byFile[d.File], d.Line)
+		}
+	}
+
+	for _, file := range files {
+		path := util.JoinMount(a.mountPoint, file)
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("erro ao verificar %s: %w", path, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("erro ao ler %s: %w", path, err)
+		}
+
+		lines := strings.Split(string(data), "\n")
+		for _, number := range byFile[file] {
+			if number-1 < len(lines) {
+				lines[number-1] = "# " + lines[number-1]
+			}
+		}
+
+		if err := os.WriteFile(path+".bak", data, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("erro ao criar backup de %s: %w", path, err)
+		}
+		fmt.Printf("Backup criado em %s.bak\n", path)
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("erro ao gravar %s: %w", path, err)
+		}
+		var numbers []string
+		for _, number := range byFile[file] {
+			numbers = append(numbers, strconv.Itoa(number))
+		}
+		fmt.Printf("Communities comentadas em %s (linhas %s)\n", path, strings.Join(numbers, ", "))
+	}
+
+	if a.mountPoint == "" {
+		fmt.Println("Reinicie o snmpd para aplicar as alterações (ex: systemctl restart snmpd).")
+	}
+	return nil
+}
+
+// containsInt verifica se a lista contém o número
+func containsInt(list []int, value int) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
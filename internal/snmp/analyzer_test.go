@@ -0,0 +1,90 @@
+package snmp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// parseConfig grava o conteúdo como snmpd.conf em um mountPoint temporário e lê as diretivas
+func parseConfig(t *testing.T, content string) []directive {
+	t.Helper()
+
+	root := t.TempDir()
+	path := filepath.Join(root, configPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	directives, err := readConfig(root, configPath)
+	if err != nil {
+		t.Fatalf("readConfig: %v", err)
+	}
+	return directives
+}
+
+func TestReadUsersLevels(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"rouser alice", "auth"},
+		{"rouser alice priv", "priv"},
+		{"rouser authPrivUser authpriv", "priv"},
+		{"rwuser alice AuthNoPriv", "auth"},
+		{"rouser alice noAuthNoPriv", "noauth"},
+		{"authuser read -s usm alice authPriv", "priv"},
+	}
+
+	for _, tt := range tests {
+		users := readUsers(parseConfig(t, tt.line+"\n"))
+		if len(users) != 1 || users[0].Level != tt.want {
+			t.Errorf("readUsers(%q) = %+v, esperado nível %s", tt.line, users, tt.want)
+		}
+	}
+}
+
+func TestHasPrivAccess(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"access grupo \"\" usm priv exact all none none", true},
+		{"access grupo \"\" usm authPriv exact all none none", true},
+		{"access grupo \"\" usm authNoPriv exact all none none", false},
+		{"access grupo \"\" v2c noauth exact all none none", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasPrivAccess(parseConfig(t, tt.line+"\n")); got != tt.want {
+			t.Errorf("hasPrivAccess(%q) = %v, esperado %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestLocalOnly(t *testing.T) {
+	tests := []struct {
+		config string
+		want   bool
+	}{
+		{"", false},
+		{"agentAddress udp:127.0.0.1:161\n", true},
+		{"agentAddress udp:127.0.0.1:161,udp6:[::1]:161\n", true},
+		{"agentAddress localhost\n", true},
+		{"agentAddress unix:/var/run/snmpd.sock\n", true},
+		{"agentAddress udp:161\n", false},
+		{"agentAddress udp:127.0.0.1:161,udp:10.0.0.5:161\n", false},
+		{"agentAddress udp6:[::]:161\n", false},
+		// Vale a última linha agentAddress
+		{"agentAddress udp:161\nagentAddress udp:127.0.0.1:161\n", true},
+	}
+
+	for _, tt := range tests {
+		if got := localOnly(parseConfig(t, tt.config)); got != tt.want {
+			t.Errorf("localOnly(%q) = %v, esperado %v", tt.config, got, tt.want)
+		}
+	}
+}
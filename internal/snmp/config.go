@@ -0,0 +1,135 @@
+package snmp
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// configPath é o arquivo de configuração do snmpd (Net-SNMP)
+const configPath = "/etc/snmp/snmpd.conf"
+
+// directive é uma linha da configuração do snmpd
+type directive struct {
+	Name string
+	Args []string
+	File string
+	Line int
+}
+
+// Source retorna o arquivo e a linha da diretiva (ex: /etc/snmp/snmpd.conf:12)
+func (d directive) Source() string {
+	return fmt.Sprintf("%s:%d", d.File, d.Line)
+}
+
+// arg retorna um argumento da diretiva, ou vazio se não existir
+func (d directive) arg(i int) string {
+	if i < len(d.Args) {
+		return d.Args[i]
+	}
+	return ""
+}
+
+// readConfig lê o snmpd.conf e os arquivos incluídos por includeFile e includeDir
+func readConfig(mountPoint, path string) ([]directive, error) {
+	seen := make(map[string]bool)
+	return readConfigFile(mountPoint, path, seen)
+}
+
+// readConfigFile lê um arquivo de configuração, seguindo as inclusões
+func readConfigFile(mountPoint, path string, seen map[string]bool) ([]directive, error) {
+	if seen[path] {
+		return nil, nil
+	}
+	seen[path] = true
+
+	file, err := os.Open(util.JoinMount(mountPoint, path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var directives []directive
+	number := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		number++
+		fields := splitFields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		d := directive{Name: strings.ToLower(fields[0]), Args: fields[1:], File: path, Line: number}
+		switch d.Name {
+		case "includefile":
+			included, err := readConfigFile(mountPoint, resolveInclude(path, d.arg(0)), seen)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			directives = append(directives, included...)
+		case "includedir":
+			matches, _ := filepath.Glob(filepath.Join(util.JoinMount(mountPoint, resolveInclude(path, d.arg(0))), "*.conf"))
+			sort.Strings(matches)
+			for _, match := range matches {
+				included, err := readConfigFile(mountPoint, filepath.Join(resolveInclude(path, d.arg(0)), filepath.Base(match)), seen)
+				if err != nil {
+					return nil, err
+				}
+				directives = append(directives, included...)
+			}
+		default:
+			directives = append(directives, d)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler %s: %w", path, err)
+	}
+
+	return directives, nil
+}
+
+// resolveInclude interpreta caminhos relativos em relação ao diretório do arquivo que os inclui
+func resolveInclude(from, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(from), path)
+}
+
+// splitFields separa uma linha em campos, respeitando valores entre aspas e comentários
+func splitFields(line string) []string {
+	var fields []string
+	var current strings.Builder
+	inQuotes, hasField := false, false
+
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasField = true
+		case r == '#' && !inQuotes && !hasField:
+			if len(fields) == 0 {
+				return []string{"#"}
+			}
+			return fields
+		case (r == ' ' || r == '\t') && !inQuotes:
+			if hasField {
+				fields = append(fields, current.String())
+				current.Reset()
+				hasField = false
+			}
+		default:
+			current.WriteRune(r)
+			hasField = true
+		}
+	}
+	if hasField {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
@@ -0,0 +1,201 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// defaultUIDMin é o primeiro UID de usuários comuns quando o login.defs não define UID_MIN
+const defaultUIDMin = 1000
+
+// account representa uma conta do /etc/passwd do sistema analisado
+type account struct {
+	Name  string
+	UID   int
+	GID   int
+	Home  string
+	Shell string
+
+	// Expired indica que a conta expirou (campo 8 do /etc/shadow)
+	Expired bool
+
+	// PasswordLocked indica que a senha está bloqueada ("!"), como gravam o passwd -l e o
+	// cloud-init (lock_passwd). Apenas a senha é bloqueada: o login por chave continua possível.
+	PasswordLocked bool
+}
+
+// Blocked indica se o sshd recusa qualquer login da conta, inclusive por chave. Contas
+// expiradas são sempre recusadas. A senha bloqueada só impede o login por chave com
+// UsePAM no: com PAM, o sshd delega a verificação da conta ao pam_unix, que a aceita.
+func (acc account) Blocked(usePAM bool) bool {
+	return acc.Expired || (acc.PasswordLocked && !usePAM)
+}
+
+// IsSystem indica se a conta é uma conta de sistema (UID abaixo de UID_MIN, exceto root)
+func (acc account) IsSystem(uidMin int) bool {
+	return acc.UID != 0 && acc.UID < uidMin
+}
+
+// HasLoginShell indica se a conta possui um shell que permite login interativo
+func (acc account) HasLoginShell() bool {
+	shell := filepath.Base(acc.Shell)
+	return acc.Shell != "" && shell != "nologin" && shell != "false"
+}
+
+// readAccounts lê as contas do /etc/passwd e o estado de bloqueio do /etc/shadow
+func readAccounts(mountPoint string) ([]account, error) {
+	file, err := os.Open(util.JoinMount(mountPoint, "/etc/passwd"))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir /etc/passwd: %w", err)
+	}
+	defer file.Close()
+
+	var accounts []account
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 7 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		uid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		gid, _ := strconv.Atoi(fields[3])
+
+		accounts = append(accounts, account{
+			Name:  fields[0],
+			UID:   uid,
+			GID:   gid,
+			Home:  fields[5],
+			Shell: fields[6],
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler /etc/passwd: %w", err)
+	}
+
+	// O /etc/shadow só é legível pelo root; sem ele, nenhuma conta é considerada bloqueada
+	shadow := readShadow(mountPoint)
+	for i := range accounts {
+		state := shadow[accounts[i].Name]
+		accounts[i].Expired = state.Expired
+		accounts[i].PasswordLocked = state.PasswordLocked
+	}
+
+	return accounts, nil
+}
+
+// shadowState é o estado de uma conta no /etc/shadow
+type shadowState struct {
+	Expired        bool
+	PasswordLocked bool
+}
+
+// readShadow lê as contas expiradas e as com senha bloqueada ("!") no /etc/shadow
+func readShadow(mountPoint string) map[string]shadowState {
+	states := make(map[string]shadowState)
+
+	file, err := os.Open(util.JoinMount(mountPoint, "/etc/shadow"))
+	if err != nil {
+		return states
+	}
+	defer file.Close()
+
+	today := int(time.Now().Unix() / 86400)
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 2 {
+			continue
+		}
+
+		state := shadowState{PasswordLocked: strings.HasPrefix(fields[1], "!")}
+
+		// Campo 8: data de expiração da conta em dias desde 1970-01-01
+		if len(fields) >= 8 && fields[7] != "" {
+			if expire, err := strconv.Atoi(fields[7]); err == nil && expire <= today {
+				state.Expired = true
+			}
+		}
+		states[fields[0]] = state
+	}
+
+	return states
+}
+
+// group representa um grupo do /etc/group do sistema analisado
+type group struct {
+	Name    string
+	GID     int
+	Members []string
+}
+
+// readGroups lê os grupos do /etc/group do sistema analisado
+func readGroups(mountPoint string) ([]group, error) {
+	file, err := os.Open(util.JoinMount(mountPoint, "/etc/group"))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir /etc/group: %w", err)
+	}
+	defer file.Close()
+
+	var groups []group
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) < 4 {
+			continue
+		}
+
+		gid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+
+		var members []string
+		for _, member := range strings.Split(fields[3], ",") {
+			if member = strings.TrimSpace(member); member != "" {
+				members = append(members, member)
+			}
+		}
+
+		groups = append(groups, group{Name: fields[0], GID: gid, Members: members})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler /etc/group: %w", err)
+	}
+
+	return groups, nil
+}
+
+// readUIDMin lê o UID_MIN do /etc/login.defs
+func readUIDMin(mountPoint string) int {
+	file, err := os.Open(util.JoinMount(mountPoint, "/etc/login.defs"))
+	if err != nil {
+		return defaultUIDMin
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[0] == "UID_MIN" {
+			if uidMin, err := strconv.Atoi(fields[1]); err == nil {
+				return uidMin
+			}
+		}
+	}
+
+	return defaultUIDMin
+}
@@ -0,0 +1,96 @@
+package ssh
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+// algorithmDirectives relaciona as diretivas de configuração com o tipo de algoritmo que configuram.
+// Os nomes são os mesmos no sshd_config e no ssh_config.
+var algorithmDirectives = []struct {
+	Key  string
+	Kind string
+}{
+	{"KexAlgorithms", algorithmKex},
+	{"HostKeyAlgorithms", algorithmHostKey},
+	{"Ciphers", algorithmCipher},
+	{"MACs", algorithmMAC},
+}
+
+// analyzeAlgorithms verifica as listas de algoritmos configuradas explicitamente no sshd_config.
+// Quando a diretiva não existe, o sshd usa os padrões da versão instalada, que não são verificados aqui.
+func (a *Analyzer) analyzeAlgorithms(config *sshdConfig, editor *configEditor) []report.Issue {
+	var issues []report.Issue
+
+	for _, directive := range algorithmDirectives {
+		value, ok := config.Get(directive.Key)
+		if !ok {
+			continue
+		}
+
+		issue, weak := algorithmIssue(directive.Key, directive.Kind, value)
+		if !weak {
+			continue
+		}
+
+		issue.Category = "ssh"
+		issue.Description = fmt.Sprintf("Algoritmos de %s fracos habilitados em %s: %s", algorithmLabels[directive.Kind], directive.Key, issue.Description)
+		if issue.Key != "" {
+			issue.FixCommand = a.directiveFix(config, editor, issue.Key, issue.RecommendedValue)
+		} else {
+			// A diretiva é comentada no arquivo que a define, que pode ser um arquivo incluído
+			issue.FixCommand = commentOutCommand(directive.Key, a.logicalPath(config.Source(directive.Key)))
+		}
+
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// algorithmIssue avalia o valor de uma diretiva de algoritmos. A descrição retornada contém
+// apenas os algoritmos fracos; quando Key fica vazia, a correção é voltar ao padrão do OpenSSH
+// comentando a diretiva.
+func algorithmIssue(key, kind, value string) (report.Issue, bool) {
+	// "+" adiciona ao padrão, "^" coloca no início e "-" remove do padrão
+	prefix := ""
+	list := value
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "^") || strings.HasPrefix(value, "-") {
+		prefix, list = value[:1], value[1:]
+	}
+	if prefix == "-" {
+		return report.Issue{}, false
+	}
+
+	weak, strong, severity := weakAlgorithms(kind, strings.Split(list, ","))
+	if len(weak) == 0 {
+		return report.Issue{}, false
+	}
+
+	issue := report.Issue{
+		Severity:     severity,
+		Description:  strings.Join(weak, ", "),
+		CurrentValue: value,
+	}
+
+	switch {
+	case len(strong) > 0:
+		issue.Key = key
+		issue.RecommendedValue = prefix + strings.Join(strong, ",")
+	case prefix != "":
+		// Sem algoritmos adicionais fortes, basta voltar ao padrão do OpenSSH
+		issue.RecommendedValue = "padrão do OpenSSH"
+	default:
+		issue.Key = key
+		issue.RecommendedValue = recommendedAlgorithms[kind]
+	}
+
+	return issue, true
+}
+
+// commentOutCommand gera o comando que comenta todas as definições de uma diretiva
+func commentOutCommand(key, path string) string {
+	return fmt.Sprintf("sed -i -E 's/^([[:space:]]*%s[[:space:]=])/#\\1/I' %s", key, path)
+}
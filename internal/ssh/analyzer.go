@@ -1,13 +1,13 @@
 package ssh
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/rules"
 )
 
 // Analyzer é o analisador de configurações SSH
@@ -15,6 +15,8 @@ type Analyzer struct {
 	mountPoint string
 	configPath string
 	rules      []SSHRule
+	force      bool
+	dropIn     bool
 }
 
 // SSHRule representa uma regra para verificação de configuração SSH
@@ -23,7 +25,19 @@ type SSHRule struct {
 	RecommendedValue  string
 	Severity          report.Severity
 	Description       string
-	ComparisonFunc    func(string, string) bool
+
+	// Expect define os valores aceitos, comparados com o valor atual da diretiva
+	Expect rules.Expectation
+
+	// DefaultValue é o valor usado pelo sshd quando a diretiva não está definida.
+	// Quando vazio, a ausência da diretiva é considerada uma violação.
+	DefaultValue string
+
+	// Alternatives são diretivas que, quando definidas, também satisfazem a regra (ex: AllowUsers no lugar de AllowGroups)
+	Alternatives []string
+
+	// Append indica que o valor recomendado deve ser acrescentado à lista atual, em vez de substituí-la
+	Append bool
 }
 
 // NewAnalyzer cria um novo analisador SSH
@@ -40,6 +54,56 @@ func NewAnalyzer(mountPoint string) *Analyzer {
 	}
 }
 
+// SetForce permite aplicar correções de autenticação mesmo quando elas podem bloquear o acesso
+func (a *Analyzer) SetForce(force bool) {
+	a.force = force
+}
+
+// SetDropIn faz com que as correções sejam gravadas em /etc/ssh/sshd_config.d/00-hardshell.conf
+// em vez de editar o sshd_config principal
+func (a *Analyzer) SetDropIn(dropIn bool) {
+	a.dropIn = dropIn
+}
+
+// SetRules aplica as regras do arquivo de regras. Regras com a mesma chave de uma regra padrão
+// substituem os campos preenchidos; as demais são adicionadas e, sem expect, exigem o valor recomendado.
+func (a *Analyzer) SetRules(custom []rules.Rule) {
+	for _, rule := range custom {
+		idx := -1
+		for i := range a.rules {
+			if strings.EqualFold(a.rules[i].Key, rule.Key) {
+				idx = i
+				break
+			}
+		}
+
+		if idx < 0 {
+			a.rules = append(a.rules, SSHRule{
+				Key:      rule.Key,
+				Severity: report.SeverityWarning,
+				Expect:   rules.Equal(),
+			})
+			idx = len(a.rules) - 1
+		}
+
+		if rule.RecommendedValue != "" {
+			a.rules[idx].RecommendedValue = rule.RecommendedValue
+		}
+		if rule.Severity != "" {
+			a.rules[idx].Severity = report.Severity(rule.Severity)
+		}
+		if rule.Description != "" {
+			a.rules[idx].Description = rule.Description
+		}
+		if rule.Expect != nil {
+			a.rules[idx].Expect = *rule.Expect
+		}
+		if a.rules[idx].Description == "" {
+			a.rules[idx].Description = fmt.Sprintf("%s deve ser %s", rule.Key, a.rules[idx].Expect.Describe(a.rules[idx].RecommendedValue))
+		}
+	}
+}
+
 // Analyze analisa o arquivo sshd_config em busca de configurações inseguras
 func (a *Analyzer) Analyze() ([]report.Issue, error) {
 	// Verifica se o arquivo de configuração existe
@@ -47,73 +111,110 @@ func (a *Analyzer) Analyze() ([]report.Issue, error) {
 		return nil, fmt.Errorf("arquivo de configuração SSH não encontrado: %s", a.configPath)
 	}
 
-	// Lê o arquivo de configuração
-	configFile, err := os.Open(a.configPath)
+	// Lê e analisa o arquivo de configuração
+	config, err := parseConfig(a.mountPoint, a.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// O editor é usado apenas para gerar comandos de correção equivalentes à edição estruturada
+	data, err := os.ReadFile(a.configPath)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao abrir arquivo de configuração SSH: %w", err)
+		return nil, fmt.Errorf("erro ao ler arquivo de configuração SSH: %w", err)
 	}
-	defer configFile.Close()
+	editor := newConfigEditor(data)
 
-	// Analisa o arquivo de configuração
-	config := make(map[string]string)
-	scanner := bufio.NewScanner(configFile)
+	// Verifica as regras
+	var issues []report.Issue
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	for _, rule := range a.rules {
+		value, exists := config.Get(rule.Key)
 
-		// Ignora comentários e linhas em branco
-		if strings.HasPrefix(line, "#") || line == "" {
+		// Uma diretiva alternativa definida satisfaz a regra quando a principal não existe
+		if !exists && hasAny(config, rule.Alternatives) {
 			continue
 		}
 
-		// Separa a chave e o valor
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) != 2 {
+		// Sem valor padrão conhecido, a ausência da configuração é uma violação
+		if !exists && rule.DefaultValue == "" {
+			issues = append(issues, a.ruleIssue(config, editor, rule, "", rule.RecommendedValue))
 			continue
 		}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+		effective := value
+		if !exists {
+			effective = rule.DefaultValue
+		}
 
-		config[key] = value
+		// Verifica se o valor atual atende à regra
+		if !rule.Expect.Match(effective, rule.RecommendedValue) {
+			recommended := rule.RecommendedValue
+			if rule.Append && exists {
+				recommended = value + " " + rule.RecommendedValue
+			}
+			issues = append(issues, a.ruleIssue(config, editor, rule, value, recommended))
+		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao ler arquivo de configuração SSH: %w", err)
+	// Verifica os algoritmos criptográficos configurados
+	issues = append(issues, a.analyzeAlgorithms(config, editor)...)
+
+	// Verifica as chaves de host e os grupos Diffie-Hellman
+	hostKeyIssues, err := a.analyzeHostKeys(config)
+	if err != nil {
+		return nil, err
 	}
+	issues = append(issues, hostKeyIssues...)
 
-	// Verifica as regras
-	var issues []report.Issue
+	moduliIssues, err := a.analyzeModuli()
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, moduliIssues...)
 
-	for _, rule := range a.rules {
-		value, exists := config[rule.Key]
+	return issues, nil
+}
 
-		// Se a configuração não existe, considere como uma violação
-		if !exists {
-			issues = append(issues, report.Issue{
-				Category:         "ssh",
-				Severity:         rule.Severity,
-				Description:      rule.Description,
-				RecommendedValue: rule.RecommendedValue,
-				FixCommand:       fmt.Sprintf("echo '%s %s' >> /etc/ssh/sshd_config", rule.Key, rule.RecommendedValue),
-			})
-			continue
-		}
+// ruleIssue cria o problema de uma regra violada. Sem valor recomendado não há correção
+// automática (ex: o grupo de AllowGroups depende de cada ambiente).
+func (a *Analyzer) ruleIssue(config *sshdConfig, editor *configEditor, rule SSHRule, current, recommended string) report.Issue {
+	issue := report.Issue{
+		Category:         "ssh",
+		Severity:         rule.Severity,
+		Description:      rule.Description,
+		CurrentValue:     current,
+		RecommendedValue: recommended,
+		Expected:         rule.Expect.Describe(rule.RecommendedValue),
+	}
 
-		// Verifica se o valor atual atende à regra
-		if !rule.ComparisonFunc(value, rule.RecommendedValue) {
-			issues = append(issues, report.Issue{
-				Category:         "ssh",
-				Severity:         rule.Severity,
-				Description:      rule.Description,
-				CurrentValue:     value,
-				RecommendedValue: rule.RecommendedValue,
-				FixCommand:       fmt.Sprintf("sed -i 's/^%s.*/%s %s/' /etc/ssh/sshd_config", rule.Key, rule.Key, rule.RecommendedValue),
-			})
+	if recommended != "" {
+		issue.Key = rule.Key
+		issue.FixCommand = a.directiveFix(config, editor, rule.Key, recommended)
+	}
+
+	return issue
+}
+
+// hasAny verifica se alguma das diretivas está definida
+func hasAny(config *sshdConfig, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := config.Get(key); ok {
+			return true
 		}
 	}
+	return false
+}
 
-	return issues, nil
+// directiveFix gera o comando de correção de uma diretiva e registra a edição no editor,
+// para que os comandos seguintes considerem as linhas já inseridas
+func (a *Analyzer) directiveFix(config *sshdConfig, editor *configEditor, key, value string) string {
+	if a.usesDropIn(config, key) {
+		return dropInCommand(key, value)
+	}
+
+	cmd := editor.ShellCommand(key, value, "/etc/ssh/sshd_config")
+	editor.Set(key, value)
+	return cmd
 }
 
 // Fix gera um script para corrigir os problemas encontrados
@@ -129,6 +230,38 @@ func (a *Analyzer) Fix() error {
 		return nil
 	}
 
+	// Verifica se as correções de autenticação podem bloquear o acesso ao servidor
+	config, err := parseConfig(a.mountPoint, a.configPath)
+	if err != nil {
+		return err
+	}
+
+	reasons, err := a.checkLockout(config, issues)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar risco de perda de acesso: %w", err)
+	}
+
+	if len(reasons) > 0 {
+		fmt.Println("As correções de autenticação SSH podem bloquear o acesso ao servidor:")
+		for _, reason := range reasons {
+			fmt.Printf("  - %s\n", reason)
+		}
+
+		if a.force {
+			fmt.Println("Aplicando mesmo assim (--force).")
+		} else {
+			fmt.Println("Essas correções foram recusadas. Use --force para aplicá-las mesmo assim.")
+
+			var safe []report.Issue
+			for _, issue := range issues {
+				if !lockoutKeys[strings.ToLower(issue.Key)] {
+					safe = append(safe, issue)
+				}
+			}
+			issues = safe
+		}
+	}
+
 	// Cria um backup do arquivo de configuração
 	backupPath := a.configPath + ".bak"
 	err = copyFile(a.configPath, backupPath)
@@ -138,13 +271,36 @@ func (a *Analyzer) Fix() error {
 
 	fmt.Printf("Backup criado em %s\n", backupPath)
 
-	// Aplica as correções
+	// As diretivas do sshd_config são gravadas pelo editor estruturado
+	var directives, others []report.Issue
 	for _, issue := range issues {
+		if issue.Key != "" {
+			directives = append(directives, issue)
+		} else {
+			others = append(others, issue)
+		}
+	}
+
+	if len(directives) > 0 {
+		if err := a.writeDirectives(config, directives); err != nil {
+			return err
+		}
+		for _, issue := range directives {
+			fmt.Printf("Aplicada correção: %s %s\n", issue.Key, issue.RecommendedValue)
+		}
+	}
+
+	// Aplica as demais correções
+	for _, issue := range others {
+		if issue.FixCommand == "" {
+			continue
+		}
+
 		cmd := issue.FixCommand
 
 		// Adapta o comando para o mountPoint, se necessário
 		if a.mountPoint != "" {
-			cmd = strings.Replace(cmd, "/etc/ssh/sshd_config", a.configPath, -1)
+			cmd = strings.Replace(cmd, "/etc/ssh/", filepath.Join(a.mountPoint, "etc/ssh")+"/", -1)
 		}
 
 		// Executa o comando
@@ -184,82 +340,161 @@ func getDefaultRules() []SSHRule {
 			RecommendedValue: "no",
 			Severity:         report.SeverityCritical,
 			Description:      "Login direto como root deve ser desabilitado",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "Protocol",
 			RecommendedValue: "2",
 			Severity:         report.SeverityCritical,
 			Description:      "Apenas o protocolo SSH 2 deve ser permitido (SSH 1 é inseguro)",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "PasswordAuthentication",
 			RecommendedValue: "no",
 			Severity:         report.SeverityWarning,
 			Description:      "Autenticação por senha deve ser desabilitada, prefira chaves SSH",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "PermitEmptyPasswords",
 			RecommendedValue: "no",
 			Severity:         report.SeverityCritical,
 			Description:      "Senhas vazias não devem ser permitidas",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "X11Forwarding",
 			RecommendedValue: "no",
 			Severity:         report.SeverityWarning,
 			Description:      "Encaminhamento X11 deve ser desabilitado se não for necessário",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "MaxAuthTries",
 			RecommendedValue: "4",
 			Severity:         report.SeverityWarning,
 			Description:      "Número máximo de tentativas de autenticação deve ser limitado",
-			ComparisonFunc:   func(actual, recommended string) bool {
-				// Verifica se o valor atual é menor ou igual ao recomendado
-				return actual <= recommended
-			},
+			Expect:           rules.AtMost(""),
 		},
 		{
+			// 0 desabilita o keepalive
 			Key:              "ClientAliveInterval",
 			RecommendedValue: "300",
 			Severity:         report.SeverityInfo,
 			Description:      "Definir um intervalo de keepalive para detectar clientes desconectados",
-			ComparisonFunc:   func(actual, recommended string) bool {
-				// Verifica se há algum valor definido
-				return actual != ""
-			},
+			Expect:           rules.AllOf(rules.AtLeast("1"), rules.AtMost("")),
 		},
 		{
 			Key:              "ClientAliveCountMax",
 			RecommendedValue: "3",
 			Severity:         report.SeverityInfo,
 			Description:      "Limitar o número de mensagens keepalive sem resposta antes de desconectar",
-			ComparisonFunc:   func(actual, recommended string) bool {
-				// Verifica se o valor atual é menor ou igual ao recomendado
-				return actual <= recommended
-			},
+			Expect:           rules.AtMost(""),
 		},
 		{
 			Key:              "LogLevel",
 			RecommendedValue: "VERBOSE",
 			Severity:         report.SeverityWarning,
 			Description:      "Nível de log deve ser detalhado para auditoria adequada",
-			ComparisonFunc:   func(actual, recommended string) bool {
-				// Valores aceitáveis: VERBOSE ou INFO
-				return actual == "VERBOSE" || actual == "INFO"
-			},
+			Expect:           rules.OneOf("VERBOSE", "INFO"),
 		},
 		{
 			Key:              "UsePAM",
 			RecommendedValue: "yes",
 			Severity:         report.SeverityWarning,
 			Description:      "PAM deve ser habilitado para controle de acesso avançado",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
+		},
+		{
+			// O grupo exigido depende do ambiente e é definido no arquivo de regras
+			Key:              "AllowGroups",
+			RecommendedValue: "",
+			Severity:         report.SeverityWarning,
+			Description:      "O acesso SSH deve ser restrito a grupos ou usuários específicos com AllowGroups ou AllowUsers",
+			Expect:           rules.Contains(),
+			Alternatives:     []string{"AllowUsers"},
+			Append:           true,
+		},
+		{
+			Key:              "Banner",
+			RecommendedValue: "/etc/issue.net",
+			Severity:         report.SeverityInfo,
+			Description:      "Um banner de aviso deve ser exibido antes do login",
+			Expect:           rules.Excludes("none"),
+			DefaultValue:     "none",
+		},
+		{
+			// 0 desabilita o limite
+			Key:              "LoginGraceTime",
+			RecommendedValue: "60",
+			Severity:         report.SeverityWarning,
+			Description:      "O tempo para concluir o login deve ser limitado para reduzir conexões ociosas não autenticadas",
+			Expect: rules.AllOf(
+				rules.Expectation{Op: rules.OpMin, Min: "1", Duration: true},
+				rules.Expectation{Op: rules.OpMax, Duration: true},
+			),
+			DefaultValue: "120",
+		},
+		{
+			Key:              "MaxSessions",
+			RecommendedValue: "10",
+			Severity:         report.SeverityInfo,
+			Description:      "O número de sessões por conexão deve ser limitado",
+			Expect:           rules.AtMost(""),
+			DefaultValue:     "10",
+		},
+		{
+			// início:taxa:máximo; são comparados o início e o máximo
+			Key:              "MaxStartups",
+			RecommendedValue: "10:30:60",
+			Severity:         report.SeverityWarning,
+			Description:      "Conexões simultâneas não autenticadas devem ser limitadas para mitigar ataques de negação de serviço",
+			Expect: rules.AllOf(
+				rules.Expectation{Op: rules.OpMax, Field: 1},
+				rules.Expectation{Op: rules.OpMax, Field: 3},
+			),
+			DefaultValue: "10:30:100",
+		},
+		{
+			Key:              "IgnoreRhosts",
+			RecommendedValue: "yes",
+			Severity:         report.SeverityWarning,
+			Description:      "Arquivos .rhosts e .shosts devem ser ignorados",
+			Expect:           rules.Equal(),
+			DefaultValue:     "yes",
+		},
+		{
+			Key:              "HostbasedAuthentication",
+			RecommendedValue: "no",
+			Severity:         report.SeverityWarning,
+			Description:      "Autenticação baseada em host deve ser desabilitada",
+			Expect:           rules.Equal(),
+			DefaultValue:     "no",
+		},
+		{
+			Key:              "PermitUserEnvironment",
+			RecommendedValue: "no",
+			Severity:         report.SeverityWarning,
+			Description:      "Usuários não devem poder definir variáveis de ambiente que o sshd repassa à sessão (ex: LD_PRELOAD)",
+			Expect:           rules.Equal(),
+			DefaultValue:     "no",
+		},
+		{
+			Key:              "AllowTcpForwarding",
+			RecommendedValue: "no",
+			Severity:         report.SeverityWarning,
+			Description:      "Encaminhamento de portas TCP deve ser desabilitado se não for necessário",
+			Expect:           rules.Equal(),
+			DefaultValue:     "yes",
+		},
+		{
+			Key:              "AllowAgentForwarding",
+			RecommendedValue: "no",
+			Severity:         report.SeverityWarning,
+			Description:      "Encaminhamento do agente SSH deve ser desabilitado se não for necessário",
+			Expect:           rules.Equal(),
+			DefaultValue:     "yes",
 		},
 	}
 }
@@ -0,0 +1,110 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/atomicfile"
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+// dropInPath é o arquivo gerenciado pelo Hardshell no modo drop-in
+const dropInPath = "/etc/ssh/sshd_config.d/00-hardshell.conf"
+
+// sshdConfigPath é o arquivo de configuração principal do sshd no sistema analisado
+const sshdConfigPath = "/etc/ssh/sshd_config"
+
+// usesDropIn verifica se uma diretiva deve ser gravada no arquivo drop-in. Além do modo
+// drop-in, isso acontece quando o valor efetivo vem de um arquivo incluído (ex: o
+// 50-cloud-init.conf incluído no início do sshd_config do Debian): como o sshd usa o
+// primeiro valor lido, editar o sshd_config depois do Include não teria efeito.
+func (a *Analyzer) usesDropIn(config *sshdConfig, key string) bool {
+	if a.dropIn {
+		return true
+	}
+	source := config.Source(key)
+	return source != "" && source != a.configPath
+}
+
+// writeDirectives grava as diretivas corrigidas no sshd_config ou no arquivo drop-in.
+// Os arquivos só são substituídos depois de validados com sshd -t.
+func (a *Analyzer) writeDirectives(config *sshdConfig, issues []report.Issue) error {
+	mainChange, err := atomicfile.Load(a.configPath)
+	if err != nil {
+		return err
+	}
+	main := newConfigEditor(mainChange.Old)
+
+	var dropInIssues []report.Issue
+	for _, issue := range issues {
+		if a.usesDropIn(config, issue.Key) {
+			dropInIssues = append(dropInIssues, issue)
+			continue
+		}
+		main.Set(issue.Key, issue.RecommendedValue)
+	}
+
+	if len(dropInIssues) == 0 {
+		mainChange.New = main.Bytes()
+		return a.installConfig(mainChange, nil)
+	}
+
+	// As diretivas ficam em um arquivo próprio, regenerado a cada correção
+	dropInChange, err := atomicfile.Load(a.hostPath(dropInPath))
+	if err != nil {
+		return err
+	}
+
+	dropInData := dropInChange.Old
+	if len(dropInData) == 0 {
+		dropInData = []byte(atomicfile.Header)
+	}
+	dropIn := newConfigEditor(dropInData)
+
+	for _, issue := range dropInIssues {
+		dropIn.Set(issue.Key, issue.RecommendedValue)
+
+		if source := config.Source(issue.Key); !a.dropIn && source != dropInChange.Path {
+			fmt.Printf("%s é definido em %s, incluído pelo sshd_config; a correção será gravada em %s\n", issue.Key, source, dropInChange.Path)
+		}
+
+		// Definições no arquivo principal anteriores ao Include teriam prioridade sobre o drop-in
+		if count := main.CommentOut(issue.Key); count > 0 {
+			fmt.Printf("Comentadas %d definições de %s em %s\n", count, issue.Key, a.configPath)
+		}
+	}
+
+	// O arquivo principal precisa carregar o drop-in antes das demais diretivas
+	if !main.Includes(dropInPath) {
+		main.AddInclude(dropInPath)
+		fmt.Printf("Adicionado 'Include %s' em %s\n", dropInPath, a.configPath)
+	}
+
+	mainChange.New = main.Bytes()
+	dropInChange.New = dropIn.Bytes()
+
+	if err := os.MkdirAll(filepath.Dir(dropInChange.Path), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório do drop-in SSH: %w", err)
+	}
+
+	return a.installConfig(mainChange, dropInChange)
+}
+
+// dropInCommand gera o comando equivalente à gravação de uma diretiva no arquivo drop-in:
+// grava a diretiva, inclui o drop-in no início do sshd_config quando nenhum Include o carrega
+// e comenta as definições globais do sshd_config, que teriam prioridade. O Include usa o
+// caminho relativo a /etc/ssh, que continua válido quando o comando é adaptado a um mountPoint.
+func dropInCommand(key, value string) string {
+	include := strings.TrimPrefix(dropInPath, "/etc/ssh/")
+	return strings.Join([]string{
+		fmt.Sprintf("mkdir -p %s", filepath.Dir(dropInPath)),
+		fmt.Sprintf("touch %s", dropInPath),
+		fmt.Sprintf("sed -i '/^%s[[:space:]]/Id' %s", key, dropInPath),
+		fmt.Sprintf("echo '%s %s' >> %s", key, value, dropInPath),
+		fmt.Sprintf("{ grep -qiE '^[[:space:]]*Include[[:space:]].*sshd_config\\.d/(\\*|00-hardshell)\\.conf' %s || sed -i '1i Include %s' %s; }",
+			sshdConfigPath, include, sshdConfigPath),
+		fmt.Sprintf("sed -i -E '0,/^[[:space:]]*Match[[:space:]]/I s/^([[:space:]]*%s[[:space:]=])/#\\1/I' %s", key, sshdConfigPath),
+	}, " && ")
+}
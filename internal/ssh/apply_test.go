@@ -0,0 +1,122 @@
+package ssh
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+// writeTree grava os arquivos informados (caminho do sistema analisado -> conteúdo) em um mountPoint temporário
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for path, content := range files {
+		file := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestWriteDirectivesIncludedValue(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/ssh/sshd_config": "Include /etc/ssh/sshd_config.d/*.conf\n" +
+			"X11Forwarding yes\n" +
+			"Match User backup\n" +
+			"\tPasswordAuthentication yes\n",
+		"/etc/ssh/sshd_config.d/50-cloud-init.conf": "PasswordAuthentication yes\n",
+	})
+
+	a := NewAnalyzer(root)
+	config, err := parseConfig(root, a.configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source := config.Source("PasswordAuthentication"); !strings.HasSuffix(source, "50-cloud-init.conf") {
+		t.Fatalf("Source(PasswordAuthentication) = %q", source)
+	}
+
+	issues := []report.Issue{
+		{Key: "PasswordAuthentication", RecommendedValue: "no"},
+		{Key: "X11Forwarding", RecommendedValue: "no"},
+	}
+	if err := a.writeDirectives(config, issues); err != nil {
+		t.Fatalf("writeDirectives: %v", err)
+	}
+
+	config, err = parseConfig(root, a.configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"PasswordAuthentication": "no", "X11Forwarding": "no"} {
+		if value, _ := config.Get(key); value != want {
+			t.Errorf("%s efetivo = %q, esperado %q", key, value, want)
+		}
+	}
+
+	// X11Forwarding vem do próprio sshd_config e continua sendo editado nele
+	main, _ := os.ReadFile(a.configPath)
+	if !strings.Contains(string(main), "X11Forwarding no\n") {
+		t.Errorf("sshd_config não foi editado:\n%s", main)
+	}
+	if strings.Contains(string(main), "Include "+dropInPath) {
+		t.Errorf("o Include existente já carrega o drop-in:\n%s", main)
+	}
+
+	dropIn, _ := os.ReadFile(a.hostPath(dropInPath))
+	if !strings.Contains(string(dropIn), "PasswordAuthentication no\n") || strings.Contains(string(dropIn), "X11Forwarding") {
+		t.Errorf("conteúdo do drop-in inesperado:\n%s", dropIn)
+	}
+}
+
+func TestWriteDirectivesAddsInclude(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/ssh/sshd_config": "# Configuração do sshd\nPermitRootLogin yes\nPort 22\n",
+	})
+
+	a := NewAnalyzer(root)
+	a.SetDropIn(true)
+	config, err := parseConfig(root, a.configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.writeDirectives(config, []report.Issue{{Key: "PermitRootLogin", RecommendedValue: "no"}}); err != nil {
+		t.Fatalf("writeDirectives: %v", err)
+	}
+
+	main, _ := os.ReadFile(a.configPath)
+	want := "# Configuração do sshd\n#PermitRootLogin yes\nInclude " + dropInPath + "\nPort 22\n"
+	if string(main) != want {
+		t.Errorf("sshd_config =\n%s\nesperado\n%s", main, want)
+	}
+
+	config, err = parseConfig(root, a.configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := config.Get("PermitRootLogin"); value != "no" {
+		t.Errorf("PermitRootLogin efetivo = %q, esperado no", value)
+	}
+}
+
+func TestDropInCommand(t *testing.T) {
+	cmd := dropInCommand("PasswordAuthentication", "no")
+
+	for _, want := range []string{
+		"echo 'PasswordAuthentication no' >> " + dropInPath,
+		"sed -i '1i Include sshd_config.d/00-hardshell.conf' /etc/ssh/sshd_config",
+		"s/^([[:space:]]*PasswordAuthentication[[:space:]=])/#\\1/I' /etc/ssh/sshd_config",
+	} {
+		if !strings.Contains(cmd, want) {
+			t.Errorf("dropInCommand não contém %q:\n%s", want, cmd)
+		}
+	}
+}
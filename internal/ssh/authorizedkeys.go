@@ -0,0 +1,402 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// defaultAuthorizedKeysFile é o valor padrão do AuthorizedKeysFile no OpenSSH
+const defaultAuthorizedKeysFile = ".ssh/authorized_keys .ssh/authorized_keys2"
+
+// AuthorizedKeysAnalyzer é o analisador dos arquivos authorized_keys de todas as contas
+type AuthorizedKeysAnalyzer struct {
+	mountPoint string
+	configPath string
+}
+
+// authorizedKey representa uma chave encontrada em um arquivo authorized_keys
+type authorizedKey struct {
+	keyInfo
+	Account account
+	File    string
+	Line    int
+	Blob    string
+	Options map[string]string
+}
+
+// NewAuthorizedKeysAnalyzer cria um novo analisador de authorized_keys
+func NewAuthorizedKeysAnalyzer(mountPoint string) *AuthorizedKeysAnalyzer {
+	return &AuthorizedKeysAnalyzer{
+		mountPoint: mountPoint,
+		configPath: util.JoinMount(mountPoint, "/etc/ssh/sshd_config"),
+	}
+}
+
+// Analyze percorre as contas do sistema e verifica as chaves autorizadas de cada uma
+func (a *AuthorizedKeysAnalyzer) Analyze() ([]report.Issue, error) {
+	// Resolve o AuthorizedKeysFile a partir do sshd_config, usando o padrão se não houver
+	patterns := defaultAuthorizedKeysFile
+	var config *sshdConfig
+	if _, err := os.Stat(a.configPath); err == nil {
+		config, err = parseConfig(a.mountPoint, a.configPath)
+		if err != nil {
+			return nil, err
+		}
+		if value, ok := config.Get("AuthorizedKeysFile"); ok {
+			patterns = value
+		}
+	}
+	usePAM := config.UsePAM()
+
+	accounts, err := readAccounts(a.mountPoint)
+	if err != nil {
+		return nil, err
+	}
+	uidMin := readUIDMin(a.mountPoint)
+
+	var issues []report.Issue
+	var keys []authorizedKey
+	seen := make(map[string]bool)
+
+	for _, acc := range accounts {
+		for _, path := range resolveAuthorizedKeysFiles(patterns, acc) {
+			// Contas que compartilham o mesmo home (ex: "/") apontam para o mesmo arquivo
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+
+			fullPath := util.JoinMount(a.mountPoint, path)
+
+			info, err := os.Stat(fullPath)
+			if err != nil {
+				continue
+			}
+
+			issues = append(issues, a.checkPermissions(acc, path, info)...)
+
+			// Um arquivo ilegível não interrompe a verificação das demais contas
+			fileKeys, err := readAuthorizedKeys(fullPath)
+			if err != nil {
+				issues = append(issues, report.Issue{
+					Category:    "ssh-keys",
+					Severity:    report.SeverityWarning,
+					Description: fmt.Sprintf("Não foi possível verificar as chaves autorizadas de %s em %s: %v", acc.Name, path, err),
+					Source:      path,
+				})
+				continue
+			}
+
+			for _, key := range fileKeys {
+				key.Account = acc
+				key.File = path
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	// Chaves compartilhadas entre contas diferentes
+	owners := make(map[string][]string)
+	for _, key := range keys {
+		names := owners[key.Fingerprint]
+		if len(names) == 0 || names[len(names)-1] != key.Account.Name {
+			owners[key.Fingerprint] = append(names, key.Account.Name)
+		}
+	}
+
+	reported := make(map[string]bool)
+	for _, key := range keys {
+		location := fmt.Sprintf("%s:%d", key.File, key.Line)
+
+		if severity, reason, weak := checkKeyStrength(key.keyInfo); weak {
+			issues = append(issues, report.Issue{
+				Category:         "ssh-keys",
+				Severity:         severity,
+				Description:      fmt.Sprintf("Chave autorizada fraca para %s em %s: %s", key.Account.Name, location, reason),
+				CurrentValue:     key.keyInfo.String(),
+				RecommendedValue: fmt.Sprintf("ssh-ed25519 ou ssh-rsa >= %d bits", minRSABits),
+				FixCommand:       a.removeKeyFix(key),
+			})
+		}
+
+		if names := owners[key.Fingerprint]; len(names) > 1 && !reported[key.Fingerprint] {
+			reported[key.Fingerprint] = true
+			issues = append(issues, report.Issue{
+				Category:         "ssh-keys",
+				Severity:         report.SeverityWarning,
+				Description:      fmt.Sprintf("A mesma chave está autorizada em várias contas (%s)", key.Fingerprint),
+				CurrentValue:     strings.Join(names, ", "),
+				RecommendedValue: "uma chave por pessoa e por conta",
+			})
+		}
+
+		if key.Account.UID == 0 {
+			_, hasFrom := key.Options["from"]
+			_, hasRestrict := key.Options["restrict"]
+			if !hasFrom && !hasRestrict {
+				issues = append(issues, report.Issue{
+					Category:         "ssh-keys",
+					Severity:         report.SeverityWarning,
+					Description:      fmt.Sprintf("Chave autorizada para %s sem restrição de origem em %s", key.Account.Name, location),
+					CurrentValue:     key.keyInfo.String(),
+					RecommendedValue: `from="<rede de administração>" ou restrict`,
+				})
+			}
+		}
+
+		// Uma senha bloqueada não impede o login por chave com UsePAM yes (ex: o usuário padrão
+		// das imagens de nuvem), e a chave nunca é removida por causa dela
+		switch {
+		case key.Account.Expired:
+			issues = append(issues, report.Issue{
+				Category:     "ssh-keys",
+				Severity:     report.SeverityWarning,
+				Description:  fmt.Sprintf("Conta expirada possui chave autorizada (%s em %s)", key.Account.Name, location),
+				CurrentValue: key.keyInfo.String(),
+				FixCommand:   a.removeKeyFix(key),
+			})
+		case key.Account.IsSystem(uidMin):
+			issues = append(issues, report.Issue{
+				Category:     "ssh-keys",
+				Severity:     report.SeverityWarning,
+				Description:  fmt.Sprintf("Conta de sistema possui chave autorizada (%s em %s)", key.Account.Name, location),
+				CurrentValue: fmt.Sprintf("uid %d", key.Account.UID),
+				FixCommand:   a.removeKeyFix(key),
+			})
+		case key.Account.Blocked(usePAM):
+			issues = append(issues, report.Issue{
+				Category:         "ssh-keys",
+				Severity:         report.SeverityInfo,
+				Description:      fmt.Sprintf("Conta com senha bloqueada possui chave autorizada, recusada pelo sshd com UsePAM no (%s em %s)", key.Account.Name, location),
+				CurrentValue:     key.keyInfo.String(),
+				RecommendedValue: "remover a chave ou habilitar o login por chave (usermod -p '*')",
+			})
+		}
+	}
+
+	return issues, nil
+}
+
+// checkPermissions verifica se o arquivo e os diretórios acima dele só podem ser alterados pelo dono.
+// São as mesmas verificações feitas pelo sshd com StrictModes. Os comandos de correção usam o
+// caminho real no mountPoint e o uid:gid numérico do passwd analisado, pois os nomes seriam
+// resolvidos pelo passwd do host.
+func (a *AuthorizedKeysAnalyzer) checkPermissions(acc account, path string, info os.FileInfo) []report.Issue {
+	var issues []report.Issue
+
+	check := func(target string, info os.FileInfo) {
+		mode := info.Mode().Perm()
+		if mode&0022 != 0 {
+			issues = append(issues, report.Issue{
+				Category:         "ssh-keys",
+				Severity:         report.SeverityCritical,
+				Description:      fmt.Sprintf("%s pode ser alterado por outros usuários além de %s", target, acc.Name),
+				CurrentValue:     fmt.Sprintf("%04o", mode),
+				RecommendedValue: "sem permissão de escrita para grupo e outros",
+				FixCommand:       fmt.Sprintf("chmod go-w %s", util.JoinMount(a.mountPoint, target)),
+			})
+		}
+
+		if stat, ok := info.Sys().(*syscall.Stat_t); ok && stat.Uid != 0 && int(stat.Uid) != acc.UID {
+			issues = append(issues, report.Issue{
+				Category:         "ssh-keys",
+				Severity:         report.SeverityCritical,
+				Description:      fmt.Sprintf("%s não pertence a %s nem ao root", target, acc.Name),
+				CurrentValue:     fmt.Sprintf("uid %d", stat.Uid),
+				RecommendedValue: fmt.Sprintf("uid %d", acc.UID),
+				FixCommand:       fmt.Sprintf("chown %d:%d %s", acc.UID, acc.GID, util.JoinMount(a.mountPoint, target)),
+			})
+		}
+	}
+
+	check(path, info)
+
+	// Diretórios entre o arquivo e o home da conta (ex: ~/.ssh e o próprio home)
+	home := filepath.Clean(acc.Home)
+	for dir := filepath.Dir(path); strings.HasPrefix(dir, home) && dir != "/"; dir = filepath.Dir(dir) {
+		dirInfo, err := os.Stat(util.JoinMount(a.mountPoint, dir))
+		if err != nil {
+			break
+		}
+		check(dir, dirInfo)
+		if dir == home {
+			break
+		}
+	}
+
+	return issues
+}
+
+// Fix gera um script para corrigir os problemas encontrados
+func (a *AuthorizedKeysAnalyzer) Fix() error {
+	// Analisa os problemas
+	issues, err := a.Analyze()
+	if err != nil {
+		return err
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("Nenhum problema encontrado nas chaves autorizadas.")
+		return nil
+	}
+
+	// Aplica as correções
+	for _, issue := range issues {
+		if issue.FixCommand == "" {
+			continue
+		}
+
+		cmd := issue.FixCommand
+
+		// Executa o comando
+		fmt.Printf("Aplicando correção: %s\n", cmd)
+
+		// Aqui você pode implementar a execução do comando
+		// Por enquanto, apenas simula a execução
+		fmt.Printf("  [Simulando] %s\n", cmd)
+	}
+
+	return nil
+}
+
+// resolveAuthorizedKeysFiles expande os tokens do AuthorizedKeysFile para uma conta
+func resolveAuthorizedKeysFiles(patterns string, acc account) []string {
+	var files []string
+
+	for _, pattern := range strings.Fields(patterns) {
+		if pattern == "none" {
+			continue
+		}
+
+		replacer := strings.NewReplacer(
+			"%%", "%",
+			"%h", acc.Home,
+			"%u", acc.Name,
+			"%U", strconv.Itoa(acc.UID),
+		)
+		path := replacer.Replace(pattern)
+
+		// Caminhos relativos são relativos ao home do usuário
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(acc.Home, path)
+		}
+
+		files = append(files, filepath.Clean(path))
+	}
+
+	return files
+}
+
+// readAuthorizedKeys lê as chaves de um arquivo authorized_keys
+func readAuthorizedKeys(path string) ([]authorizedKey, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo authorized_keys: %w", err)
+	}
+	defer file.Close()
+
+	var keys []authorizedKey
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Ignora comentários e linhas em branco
+		if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
+
+		options, keyPart := splitKeyOptions(line)
+
+		info, err := parsePublicKeyLine(keyPart)
+		if err != nil {
+			continue
+		}
+
+		keys = append(keys, authorizedKey{
+			keyInfo: info,
+			Line:    lineNumber,
+			Blob:    strings.Fields(keyPart)[1],
+			Options: options,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo authorized_keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// splitKeyOptions separa as opções (from=, restrict, command=, ...) do restante da linha
+func splitKeyOptions(line string) (map[string]string, string) {
+	options := make(map[string]string)
+
+	// Sem opções: a linha começa diretamente pelo tipo da chave
+	first := strings.Fields(line)[0]
+	if isKeyType(first) {
+		return options, line
+	}
+
+	// As opções terminam no primeiro espaço fora de aspas
+	inQuotes := false
+	end := len(line)
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		}
+		if !inQuotes && (c == ' ' || c == '\t') {
+			end = i
+			break
+		}
+	}
+
+	for _, option := range splitOutsideQuotes(line[:end], ',') {
+		name, value, _ := strings.Cut(option, "=")
+		options[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(value, `"`)
+	}
+
+	return options, strings.TrimSpace(line[end:])
+}
+
+// splitOutsideQuotes divide uma string pelo separador, ignorando separadores entre aspas
+func splitOutsideQuotes(s string, sep rune) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+
+	for i, c := range s {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == sep && !inQuotes:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// isKeyType verifica se uma palavra é um tipo de chave SSH conhecido
+func isKeyType(word string) bool {
+	return strings.HasPrefix(word, "ssh-") ||
+		strings.HasPrefix(word, "ecdsa-sha2-") ||
+		strings.HasPrefix(word, "sk-")
+}
+
+// removeKeyFix gera o comando para remover uma chave de um arquivo authorized_keys
+func (a *AuthorizedKeysAnalyzer) removeKeyFix(key authorizedKey) string {
+	return fmt.Sprintf("sed -i '\\#%s#d' %s", key.Blob, util.JoinMount(a.mountPoint, key.File))
+}
@@ -0,0 +1,163 @@
+package ssh
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ed25519Line monta uma linha de authorized_keys com uma chave ed25519 de bytes fixos
+func ed25519Line(seed byte) string {
+	var blob []byte
+	for _, field := range [][]byte{[]byte("ssh-ed25519"), make([]byte, 32)} {
+		blob = binary.BigEndian.AppendUint32(blob, uint32(len(field)))
+		blob = append(blob, field...)
+	}
+	blob[len(blob)-1] = seed
+	return "ssh-ed25519 " + base64.StdEncoding.EncodeToString(blob) + " backup@host"
+}
+
+func TestAuthorizedKeysFixCommandsUseMountPoint(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/passwd": "root:x:0:0:root:/root:/bin/bash\n" +
+			"backup:x:34:34:backup:/var/backups:/usr/sbin/nologin\n",
+		"/var/backups/.ssh/authorized_keys": ed25519Line(1) + "\n",
+	})
+	keys := filepath.Join(root, "/var/backups/.ssh/authorized_keys")
+	if err := os.Chmod(keys, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := NewAuthorizedKeysAnalyzer(root).Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var commands []string
+	for _, issue := range issues {
+		if issue.FixCommand == "" {
+			continue
+		}
+		commands = append(commands, issue.FixCommand)
+		if !strings.Contains(issue.FixCommand, " "+root+"/var/backups") {
+			t.Errorf("o comando não usa o caminho no mountPoint: %s", issue.FixCommand)
+		}
+		if strings.HasPrefix(issue.FixCommand, "chown ") && !strings.HasPrefix(issue.FixCommand, "chown 34:34 ") {
+			t.Errorf("chown deve usar o uid:gid do passwd analisado: %s", issue.FixCommand)
+		}
+	}
+
+	want := []string{"chmod go-w " + keys, "#d' " + keys}
+	for _, prefix := range want {
+		found := false
+		for _, cmd := range commands {
+			if strings.Contains(cmd, prefix) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("nenhum comando contém %q: %v", prefix, commands)
+		}
+	}
+}
+
+func TestAuthorizedKeysLockedAccounts(t *testing.T) {
+	tests := []struct {
+		name       string
+		shadow     string
+		config     string
+		wantIssue  string
+		wantRemove bool
+	}{
+		{
+			// Padrão das imagens de nuvem: senha bloqueada pelo cloud-init, login por chave com PAM
+			name:   "senha bloqueada com UsePAM yes",
+			shadow: "ubuntu:!:19000:0:99999:7:::\n",
+			config: "UsePAM yes\n",
+		},
+		{
+			name:      "senha bloqueada com UsePAM no",
+			shadow:    "ubuntu:!:19000:0:99999:7:::\n",
+			config:    "UsePAM no\n",
+			wantIssue: "senha bloqueada",
+		},
+		{
+			name:       "conta expirada",
+			shadow:     "ubuntu:$6$salt$hash:19000:0:99999:7::1:\n",
+			config:     "UsePAM yes\n",
+			wantIssue:  "expirada",
+			wantRemove: true,
+		},
+	}
+
+	for _, tt := range tests {
+		root := writeTree(t, map[string]string{
+			"/etc/passwd":                       "ubuntu:x:1000:1000::/home/ubuntu:/bin/bash\n",
+			"/etc/shadow":                       tt.shadow,
+			"/etc/ssh/sshd_config":              tt.config,
+			"/home/ubuntu/.ssh/authorized_keys": ed25519Line(2) + "\n",
+		})
+
+		issues, err := NewAuthorizedKeysAnalyzer(root).Analyze()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		var found, remove bool
+		for _, issue := range issues {
+			if tt.wantIssue != "" && strings.Contains(issue.Description, tt.wantIssue) {
+				found = true
+			}
+			if strings.Contains(issue.FixCommand, "authorized_keys") && strings.HasPrefix(issue.FixCommand, "sed ") {
+				remove = true
+			}
+		}
+		if found != (tt.wantIssue != "") {
+			t.Errorf("%s: problema %q encontrado = %v: %+v", tt.name, tt.wantIssue, found, issues)
+		}
+		if remove != tt.wantRemove {
+			t.Errorf("%s: remoção da chave = %v, esperado %v", tt.name, remove, tt.wantRemove)
+		}
+	}
+}
+
+func TestAuthorizedKeysUnreadableFile(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/passwd": "alice:x:1000:1000::/home/alice:/bin/bash\n" +
+			"bob:x:1001:1001::/home/bob:/bin/bash\n",
+		"/home/bob/.ssh/authorized_keys": ed25519Line(3) + "\n",
+	})
+
+	// Um diretório no lugar do arquivo passa pelo os.Stat, mas falha na leitura
+	if err := os.MkdirAll(filepath.Join(root, "/home/alice/.ssh/authorized_keys"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(root, "/home/bob/.ssh/authorized_keys"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := NewAuthorizedKeysAnalyzer(root).Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+
+	// As chaves das demais contas continuam sendo verificadas
+	var unreadable, bob bool
+	for _, issue := range issues {
+		if strings.Contains(issue.Description, "Não foi possível verificar") && strings.Contains(issue.Description, "alice") {
+			unreadable = true
+		}
+		if strings.HasPrefix(issue.FixCommand, "chmod go-w ") && strings.Contains(issue.FixCommand, "/home/bob/") {
+			bob = true
+		}
+	}
+	if !unreadable {
+		t.Errorf("o arquivo ilegível de alice não foi reportado: %+v", issues)
+	}
+	if !bob {
+		t.Errorf("as permissões de bob não foram verificadas: %+v", issues)
+	}
+}
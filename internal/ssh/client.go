@@ -0,0 +1,307 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/atomicfile"
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/rules"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// clientConfigPath é o arquivo de configuração do cliente SSH para todo o sistema
+const clientConfigPath = "/etc/ssh/ssh_config"
+
+// ClientAnalyzer é o analisador da configuração do cliente SSH (ssh_config)
+type ClientAnalyzer struct {
+	mountPoint  string
+	configPath  string
+	rules       []ClientRule
+	userConfigs bool
+}
+
+// ClientRule representa uma regra para verificação da configuração do cliente SSH
+type ClientRule struct {
+	Key              string
+	RecommendedValue string
+	DefaultValue     string
+	Severity         report.Severity
+	Description      string
+	Expect           rules.Expectation
+}
+
+// NewClientAnalyzer cria um novo analisador do cliente SSH
+func NewClientAnalyzer(mountPoint string) *ClientAnalyzer {
+	return &ClientAnalyzer{
+		mountPoint: mountPoint,
+		configPath: util.JoinMount(mountPoint, clientConfigPath),
+		rules:      getDefaultClientRules(),
+	}
+}
+
+// SetUserConfigs faz com que o ~/.ssh/config de cada conta também seja verificado
+func (a *ClientAnalyzer) SetUserConfigs(userConfigs bool) {
+	a.userConfigs = userConfigs
+}
+
+// Analyze analisa o ssh_config do sistema e, opcionalmente, o ~/.ssh/config de cada conta
+func (a *ClientAnalyzer) Analyze() ([]report.Issue, error) {
+	var issues []report.Issue
+
+	// Sem ssh_config o cliente SSH provavelmente não está instalado
+	if data, err := os.ReadFile(a.configPath); err == nil {
+		config, err := parseClientConfig(a.mountPoint, a.configPath, "/etc/ssh")
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, a.checkConfig(config, newClientConfigEditor(data), clientConfigPath, true)...)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("erro ao ler configuração do cliente SSH: %w", err)
+	}
+
+	if !a.userConfigs {
+		return issues, nil
+	}
+
+	accounts, err := readAccounts(a.mountPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, acc := range accounts {
+		if acc.Home == "" || seen[acc.Home] {
+			continue
+		}
+		seen[acc.Home] = true
+
+		path := filepath.Join(acc.Home, ".ssh", "config")
+		data, err := os.ReadFile(util.JoinMount(a.mountPoint, path))
+		if err != nil {
+			continue
+		}
+
+		config, err := parseClientConfig(a.mountPoint, util.JoinMount(a.mountPoint, path), filepath.Join(acc.Home, ".ssh"))
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, a.checkConfig(config, newClientConfigEditor(data), path, false)...)
+	}
+
+	return issues, nil
+}
+
+// checkConfig aplica as regras a uma configuração do cliente. Apenas os problemas do arquivo
+// do sistema recebem Key, pois só ele é corrigido automaticamente.
+// Os blocos específicos são listados primeiro: comentar uma linha não altera a numeração
+// usada pelos comandos de inserção gerados em seguida.
+func (a *ClientAnalyzer) checkConfig(config *clientConfig, editor *configEditor, path string, system bool) []report.Issue {
+	var overrides, issues []report.Issue
+
+	where := ""
+	if !system {
+		where = fmt.Sprintf(" (%s)", path)
+	}
+
+	for _, rule := range a.rules {
+		value, exists := config.Get(rule.Key)
+		effective := value
+		if !exists {
+			effective = rule.DefaultValue
+		}
+
+		if !rule.Expect.Match(effective, rule.RecommendedValue) {
+			issue := report.Issue{
+				Category:         "ssh-client",
+				Severity:         rule.Severity,
+				Description:      rule.Description + where,
+				CurrentValue:     value,
+				RecommendedValue: rule.RecommendedValue,
+				Expected:         rule.Expect.Describe(rule.RecommendedValue),
+				FixCommand:       editor.ShellCommand(rule.Key, rule.RecommendedValue, path),
+			}
+			if system {
+				issue.Key = rule.Key
+			}
+			editor.Set(rule.Key, rule.RecommendedValue)
+			issues = append(issues, issue)
+		}
+
+		// Blocos Host/Match específicos podem reverter o padrão seguro para alguns destinos
+		for _, override := range config.Overrides(rule.Key) {
+			if rule.Expect.Match(override.Value, rule.RecommendedValue) {
+				continue
+			}
+			issue := a.overrideIssue(override, rule.Severity, rule.RecommendedValue)
+			issue.Expected = rule.Expect.Describe(rule.RecommendedValue)
+			overrides = append(overrides, issue)
+		}
+	}
+
+	for _, directive := range algorithmDirectives {
+		if value, ok := config.Get(directive.Key); ok {
+			if issue, weak := algorithmIssue(directive.Key, directive.Kind, value); weak {
+				issue.Category = "ssh-client"
+				issue.Description = fmt.Sprintf("Algoritmos de %s fracos habilitados em %s do cliente SSH: %s%s", algorithmLabels[directive.Kind], directive.Key, issue.Description, where)
+				if issue.Key != "" {
+					issue.FixCommand = editor.ShellCommand(issue.Key, issue.RecommendedValue, path)
+					editor.Set(issue.Key, issue.RecommendedValue)
+				} else {
+					issue.FixCommand = commentOutCommand(directive.Key, path)
+				}
+				if !system {
+					issue.Key = ""
+				}
+				issues = append(issues, issue)
+			}
+		}
+
+		for _, override := range config.Overrides(directive.Key) {
+			if issue, weak := algorithmIssue(directive.Key, directive.Kind, override.Value); weak {
+				overrides = append(overrides, a.overrideIssue(override, issue.Severity, issue.RecommendedValue))
+			}
+		}
+	}
+
+	return append(overrides, issues...)
+}
+
+// overrideIssue descreve uma definição insegura restrita a um bloco Host ou Match
+func (a *ClientAnalyzer) overrideIssue(override clientDirective, severity report.Severity, recommended string) report.Issue {
+	file := override.File
+	if a.mountPoint != "" {
+		if rel, err := filepath.Rel(a.mountPoint, file); err == nil {
+			file = "/" + rel
+		}
+	}
+
+	return report.Issue{
+		Category:         "ssh-client",
+		Severity:         severity,
+		Description:      fmt.Sprintf("Bloco '%s' redefine %s com valor inseguro (%s:%d)", override.Block, override.Key, file, override.Line),
+		CurrentValue:     override.Value,
+		RecommendedValue: recommended,
+		FixCommand:       fmt.Sprintf("sed -i '%ds/^/#/' %s", override.Line, file),
+	}
+}
+
+// Fix aplica as correções no ssh_config do sistema. As correções dos arquivos dos usuários
+// e dos blocos Host específicos são apenas exibidas, pois podem ser exceções intencionais.
+func (a *ClientAnalyzer) Fix() error {
+	issues, err := a.Analyze()
+	if err != nil {
+		return err
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("Nenhum problema encontrado na configuração do cliente SSH.")
+		return nil
+	}
+
+	var directives, others []report.Issue
+	for _, issue := range issues {
+		if issue.Key != "" {
+			directives = append(directives, issue)
+		} else {
+			others = append(others, issue)
+		}
+	}
+
+	// Os comandos dos blocos específicos usam a numeração de linhas anterior às inserções
+	for _, issue := range others {
+		cmd := issue.FixCommand
+
+		// Adapta o comando para o mountPoint, se necessário (o arquivo é sempre o último argumento)
+		if a.mountPoint != "" {
+			idx := strings.LastIndex(cmd, " ")
+			cmd = cmd[:idx+1] + util.JoinMount(a.mountPoint, cmd[idx+1:])
+		}
+
+		fmt.Printf("Aplicando correção: %s\n", cmd)
+		fmt.Printf("  [Simulando] %s\n", cmd)
+	}
+
+	if len(directives) > 0 {
+		change, err := atomicfile.Load(a.configPath)
+		if err != nil {
+			return err
+		}
+
+		// Cria um backup do arquivo de configuração
+		backupPath := a.configPath + ".bak"
+		if err := copyFile(a.configPath, backupPath); err != nil {
+			return fmt.Errorf("erro ao criar backup do arquivo de configuração: %w", err)
+		}
+		fmt.Printf("Backup criado em %s\n", backupPath)
+
+		editor := newClientConfigEditor(change.Old)
+		for _, issue := range directives {
+			editor.Set(issue.Key, issue.RecommendedValue)
+		}
+		change.New = editor.Bytes()
+
+		if err := a.installConfig(change); err != nil {
+			return err
+		}
+		for _, issue := range directives {
+			fmt.Printf("Aplicada correção: %s %s\n", issue.Key, issue.RecommendedValue)
+		}
+	}
+
+	return nil
+}
+
+// installConfig valida o ssh_config candidato com ssh -G, quando possível, e substitui o arquivo atual
+func (a *ClientAnalyzer) installConfig(change *atomicfile.Change) error {
+	changes := []*atomicfile.Change{change}
+	if err := atomicfile.Write(changes); err != nil {
+		return err
+	}
+
+	// ssh -G apenas interpreta a configuração e imprime o resultado, sem conectar
+	if a.mountPoint == "" && util.HasCommand("ssh") {
+		output, err := exec.Command("ssh", "-G", "-F", change.Candidate(), "localhost").CombinedOutput()
+		if err != nil {
+			atomicfile.Remove(changes)
+			return fmt.Errorf("configuração do cliente SSH inválida, nenhuma alteração foi aplicada: %s", strings.TrimSpace(string(output)))
+		}
+		fmt.Println("Configuração do cliente validada com ssh -G")
+	}
+
+	return atomicfile.Install(changes, false)
+}
+
+// getDefaultClientRules retorna as regras padrão para o cliente SSH.
+// DefaultValue é o comportamento do OpenSSH quando a diretiva não está definida.
+func getDefaultClientRules() []ClientRule {
+	return []ClientRule{
+		{
+			Key:              "StrictHostKeyChecking",
+			RecommendedValue: "ask",
+			DefaultValue:     "ask",
+			Severity:         report.SeverityWarning,
+			Description:      "A verificação de chaves de host do cliente SSH não deve ser desabilitada",
+			Expect:           rules.Excludes("no", "off"),
+		},
+		{
+			Key:              "ForwardAgent",
+			RecommendedValue: "no",
+			DefaultValue:     "no",
+			Severity:         report.SeverityWarning,
+			Description:      "O encaminhamento do agente SSH expõe as chaves do usuário a quem controla o servidor remoto",
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "HashKnownHosts",
+			RecommendedValue: "yes",
+			DefaultValue:     "no",
+			Severity:         report.SeverityInfo,
+			Description:      "Os nomes no known_hosts devem ser armazenados com hash para não revelar os destinos acessados",
+			Expect:           rules.Equal(),
+		},
+	}
+}
@@ -0,0 +1,95 @@
+package ssh
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestClientFixAppendsHostBlock(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		clientConfigPath: "ForwardAgent no\n" +
+			"\n" +
+			"Host legacy\n" +
+			"    ForwardAgent yes\n" +
+			"    HashKnownHosts no\n",
+	})
+
+	a := NewClientAnalyzer(root)
+	issues, err := a.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// O ForwardAgent global vem antes do bloco e prevalece; o HashKnownHosts do bloco vale para o host
+	var overrides []string
+	for _, issue := range issues {
+		if strings.HasPrefix(issue.Description, "Bloco") {
+			overrides = append(overrides, issue.Description)
+		}
+	}
+	if len(overrides) != 1 || !strings.Contains(overrides[0], "HashKnownHosts") {
+		t.Errorf("redefinições reportadas = %v, esperado apenas HashKnownHosts", overrides)
+	}
+
+	if err := a.Fix(); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+
+	data, _ := os.ReadFile(a.configPath)
+	want := "ForwardAgent no\n" +
+		"\n" +
+		"Host legacy\n" +
+		"    ForwardAgent yes\n" +
+		"    HashKnownHosts no\n" +
+		"Host *\n" +
+		"HashKnownHosts yes\n"
+	if string(data) != want {
+		t.Errorf("ssh_config =\n%s\nesperado\n%s", data, want)
+	}
+}
+
+func TestClientFixTrailingHostBlock(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		clientConfigPath: "Host bastion\n" +
+			"    ForwardAgent yes\n" +
+			"\n" +
+			"Host *\n" +
+			"    StrictHostKeyChecking no\n" +
+			"#   HashKnownHosts no\n" +
+			"    SendEnv LANG LC_*\n",
+	})
+
+	a := NewClientAnalyzer(root)
+	if err := a.Fix(); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+
+	data, _ := os.ReadFile(a.configPath)
+	want := "Host bastion\n" +
+		"    ForwardAgent yes\n" +
+		"\n" +
+		"Host *\n" +
+		"    StrictHostKeyChecking ask\n" +
+		"#   HashKnownHosts no\n" +
+		"HashKnownHosts yes\n" +
+		"    SendEnv LANG LC_*\n"
+	if string(data) != want {
+		t.Errorf("ssh_config =\n%s\nesperado\n%s", data, want)
+	}
+}
+
+func TestClientShellCommandMatchesSet(t *testing.T) {
+	editor := newClientConfigEditor([]byte("Host legacy\n    HashKnownHosts no\n"))
+
+	cmd := editor.ShellCommand("HashKnownHosts", "yes", clientConfigPath)
+	if want := "echo 'Host *' >> /etc/ssh/ssh_config && echo 'HashKnownHosts yes' >> /etc/ssh/ssh_config"; cmd != want {
+		t.Errorf("ShellCommand = %q, esperado %q", cmd, want)
+	}
+
+	// Depois do bloco criado, as diretivas seguintes entram nele
+	editor.Set("HashKnownHosts", "yes")
+	if cmd := editor.ShellCommand("ForwardAgent", "no", clientConfigPath); cmd != "echo 'ForwardAgent no' >> /etc/ssh/ssh_config" {
+		t.Errorf("ShellCommand após o bloco = %q", cmd)
+	}
+}
@@ -0,0 +1,158 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// clientDirective é uma diretiva lida de um ssh_config
+type clientDirective struct {
+	Key   string
+	Value string
+	File  string
+	Line  int
+
+	// Block é o cabeçalho do bloco que restringe a diretiva (ex: "Host bastion").
+	// Fica vazio quando a diretiva vale para todos os hosts (escopo global, "Host *" ou "Match all").
+	Block string
+}
+
+// clientConfig guarda as diretivas de um ssh_config e de seus Includes, na ordem de leitura
+type clientConfig struct {
+	directives []clientDirective
+}
+
+// parseClientConfig lê um ssh_config, seguindo as diretivas Include.
+// includeBase é o diretório usado para Includes relativos (/etc/ssh ou ~/.ssh).
+func parseClientConfig(mountPoint, path, includeBase string) (*clientConfig, error) {
+	config := &clientConfig{}
+	if err := config.readFile(mountPoint, path, includeBase, "", 0); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Get retorna o valor efetivo de uma diretiva para todos os hosts.
+// Assim como no ssh, o primeiro valor encontrado é o que vale.
+func (c *clientConfig) Get(key string) (string, bool) {
+	for _, directive := range c.directives {
+		if directive.Block == "" && strings.EqualFold(directive.Key, key) {
+			return directive.Value, true
+		}
+	}
+	return "", false
+}
+
+// Overrides retorna as definições de uma diretiva que valem apenas para hosts específicos.
+// Como o primeiro valor encontrado é o que vale, blocos lidos depois da definição global
+// não têm efeito e não são retornados.
+func (c *clientConfig) Overrides(key string) []clientDirective {
+	var overrides []clientDirective
+	for _, directive := range c.directives {
+		if !strings.EqualFold(directive.Key, key) {
+			continue
+		}
+		if directive.Block == "" {
+			break
+		}
+		overrides = append(overrides, directive)
+	}
+	return overrides
+}
+
+// readFile lê um arquivo de configuração do cliente. block é o bloco em vigor na linha do Include.
+func (c *clientConfig) readFile(mountPoint, path, includeBase, block string, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("excesso de Includes aninhados no ssh_config: %s", path)
+	}
+
+	configFile, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de configuração do cliente SSH: %w", err)
+	}
+	defer configFile.Close()
+
+	scanner := bufio.NewScanner(configFile)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Ignora comentários e linhas em branco
+		if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
+
+		key, value, ok := splitDirective(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case strings.EqualFold(key, "Host"):
+			block = "Host " + value
+			if value == "*" {
+				block = ""
+			}
+		case strings.EqualFold(key, "Match"):
+			block = "Match " + value
+			if strings.EqualFold(value, "all") {
+				block = ""
+			}
+		case strings.EqualFold(key, "Include"):
+			for _, included := range expandClientInclude(mountPoint, includeBase, value) {
+				if err := c.readFile(mountPoint, included, includeBase, block, depth+1); err != nil {
+					return err
+				}
+			}
+		default:
+			c.directives = append(c.directives, clientDirective{
+				Key:   key,
+				Value: value,
+				File:  path,
+				Line:  lineNumber,
+				Block: block,
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("erro ao ler arquivo de configuração do cliente SSH: %w", err)
+	}
+
+	return nil
+}
+
+// expandClientInclude expande os padrões de um Include do ssh_config para os arquivos existentes.
+// Caminhos relativos são relativos a /etc/ssh no arquivo do sistema e a ~/.ssh nos arquivos dos usuários.
+func expandClientInclude(mountPoint, includeBase, value string) []string {
+	var files []string
+
+	for _, pattern := range strings.Fields(value) {
+		if strings.HasPrefix(pattern, "~/") {
+			// No arquivo do sistema o "~" depende do usuário que executa o ssh
+			if filepath.Base(includeBase) != ".ssh" {
+				continue
+			}
+			pattern = filepath.Join(filepath.Dir(includeBase), pattern[2:])
+		} else if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(includeBase, pattern)
+		}
+
+		matches, err := filepath.Glob(util.JoinMount(mountPoint, pattern))
+		if err != nil {
+			continue
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	return files
+}
@@ -0,0 +1,161 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// maxIncludeDepth limita o aninhamento de diretivas Include (o sshd usa o mesmo limite)
+const maxIncludeDepth = 16
+
+// sshdConfig guarda as diretivas globais lidas de um arquivo sshd_config e seus Includes
+type sshdConfig struct {
+	// values guarda todos os valores de cada diretiva, na ordem em que aparecem.
+	// As chaves são armazenadas em minúsculas, pois o sshd não diferencia maiúsculas.
+	values map[string][]string
+
+	// sources guarda o arquivo de onde veio cada valor, na mesma ordem de values
+	sources map[string][]string
+}
+
+// Get retorna o valor efetivo de uma diretiva.
+// Assim como no sshd, o primeiro valor encontrado é o que vale.
+func (c *sshdConfig) Get(key string) (string, bool) {
+	values := c.values[strings.ToLower(key)]
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// Source retorna o arquivo que define o valor efetivo de uma diretiva (o sshd_config ou um
+// arquivo incluído), ou vazio se ela não estiver definida
+func (c *sshdConfig) Source(key string) string {
+	sources := c.sources[strings.ToLower(key)]
+	if len(sources) == 0 {
+		return ""
+	}
+	return sources[0]
+}
+
+// UsePAM indica se o sshd usa PAM. O padrão do OpenSSH é no, mas Debian, Ubuntu e RHEL
+// habilitam o PAM no sshd_config distribuído.
+func (c *sshdConfig) UsePAM() bool {
+	if c == nil {
+		return false
+	}
+	value, _ := c.Get("UsePAM")
+	return strings.EqualFold(value, "yes")
+}
+
+// GetAll retorna todos os valores de uma diretiva que pode se repetir (ex: HostKey)
+func (c *sshdConfig) GetAll(key string) []string {
+	return c.values[strings.ToLower(key)]
+}
+
+// parseConfig lê um arquivo sshd_config, seguindo as diretivas Include.
+// Diretivas dentro de blocos Match não são globais e são ignoradas.
+func parseConfig(mountPoint, path string) (*sshdConfig, error) {
+	config := &sshdConfig{values: make(map[string][]string), sources: make(map[string][]string)}
+	if err := config.readFile(mountPoint, path, 0); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// readFile lê um arquivo de configuração e acumula suas diretivas globais
+func (c *sshdConfig) readFile(mountPoint, path string, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("excesso de Includes aninhados no sshd_config: %s", path)
+	}
+
+	configFile, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de configuração SSH: %w", err)
+	}
+	defer configFile.Close()
+
+	scanner := bufio.NewScanner(configFile)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Ignora comentários e linhas em branco
+		if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
+
+		key, value, ok := splitDirective(line)
+		if !ok {
+			continue
+		}
+
+		// Tudo após um Match só vale para as conexões que casam com o bloco
+		if strings.EqualFold(key, "Match") {
+			break
+		}
+
+		if strings.EqualFold(key, "Include") {
+			for _, included := range expandInclude(mountPoint, value) {
+				if err := c.readFile(mountPoint, included, depth+1); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		c.values[strings.ToLower(key)] = append(c.values[strings.ToLower(key)], value)
+		c.sources[strings.ToLower(key)] = append(c.sources[strings.ToLower(key)], path)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("erro ao ler arquivo de configuração SSH: %w", err)
+	}
+
+	return nil
+}
+
+// expandInclude expande os padrões de uma diretiva Include para os arquivos existentes.
+// Caminhos relativos são relativos a /etc/ssh, como no sshd.
+func expandInclude(mountPoint, value string) []string {
+	var files []string
+
+	for _, pattern := range strings.Fields(value) {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join("/etc/ssh", pattern)
+		}
+
+		matches, err := filepath.Glob(util.JoinMount(mountPoint, pattern))
+		if err != nil {
+			continue
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+
+	return files
+}
+
+// splitDirective separa uma linha de configuração em chave e valor.
+// O OpenSSH aceita espaços, tabs ou "=" como separador.
+func splitDirective(line string) (string, string, bool) {
+	idx := strings.IndexAny(line, " \t=")
+	if idx <= 0 {
+		return "", "", false
+	}
+
+	key := line[:idx]
+	value := strings.TrimSpace(line[idx:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	if value == "" {
+		return "", "", false
+	}
+
+	return key, value, true
+}
@@ -0,0 +1,263 @@
+package ssh
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// editAction indica como uma diretiva será gravada no arquivo
+type editAction int
+
+const (
+	// editReplace substitui a primeira definição global ativa da diretiva
+	editReplace editAction = iota
+
+	// editAfterComment insere a diretiva logo após o valor padrão comentado (ex: "#PermitRootLogin yes")
+	editAfterComment
+
+	// editBeforeMatch insere a diretiva antes do primeiro bloco Match, para que continue global
+	editBeforeMatch
+
+	// editAppend adiciona a diretiva ao final do arquivo
+	editAppend
+
+	// editAppendBlock adiciona um bloco "Host *" com a diretiva ao final do ssh_config
+	editAppendBlock
+)
+
+// configEditor edita um sshd_config preservando comentários, indentação e a ordem das linhas
+type configEditor struct {
+	lines []string
+
+	// hostBlocks indica que blocos Host também encerram o escopo global, como no ssh_config
+	hostBlocks bool
+}
+
+// newConfigEditor cria um editor a partir do conteúdo de um arquivo
+func newConfigEditor(data []byte) *configEditor {
+	content := strings.TrimSuffix(string(data), "\n")
+	if content == "" {
+		return &configEditor{}
+	}
+	return &configEditor{lines: strings.Split(content, "\n")}
+}
+
+// newClientConfigEditor cria um editor para um ssh_config, em que blocos Host e Match
+// restringem as diretivas seguintes
+func newClientConfigEditor(data []byte) *configEditor {
+	editor := newConfigEditor(data)
+	editor.hostBlocks = true
+	return editor
+}
+
+// Bytes retorna o conteúdo editado
+func (e *configEditor) Bytes() []byte {
+	if len(e.lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(e.lines, "\n") + "\n")
+}
+
+// Set define o valor de uma diretiva global
+func (e *configEditor) Set(key, value string) {
+	directive := key + " " + value
+	action, idx := e.plan(key)
+
+	switch action {
+	case editReplace:
+		indent := e.lines[idx][:len(e.lines[idx])-len(strings.TrimLeft(e.lines[idx], " \t"))]
+		e.lines[idx] = indent + directive
+	case editAfterComment:
+		e.insert(idx+1, directive)
+	case editBeforeMatch:
+		e.insert(idx, directive)
+	case editAppendBlock:
+		e.lines = append(e.lines, "Host *", directive)
+	default:
+		e.lines = append(e.lines, directive)
+	}
+}
+
+// CommentOut comenta todas as definições globais ativas de uma diretiva
+func (e *configEditor) CommentOut(key string) int {
+	count := 0
+	for i := 0; i < e.globalEnd(); i++ {
+		if k, ok := activeKey(e.lines[i]); ok && strings.EqualFold(k, key) {
+			e.lines[i] = "#" + e.lines[i]
+			count++
+		}
+	}
+	return count
+}
+
+// Includes verifica se alguma diretiva Include global carrega o arquivo informado
+func (e *configEditor) Includes(path string) bool {
+	for i := 0; i < e.globalEnd(); i++ {
+		key, value, ok := splitDirective(strings.TrimSpace(e.lines[i]))
+		if !ok || strings.HasPrefix(strings.TrimSpace(e.lines[i]), "#") || !strings.EqualFold(key, "Include") {
+			continue
+		}
+		for _, pattern := range strings.Fields(value) {
+			if !filepath.IsAbs(pattern) {
+				pattern = filepath.Join("/etc/ssh", pattern)
+			}
+			if matched, _ := filepath.Match(pattern, path); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AddInclude adiciona uma diretiva Include antes da primeira diretiva ativa do arquivo.
+// Como o sshd usa o primeiro valor encontrado, o arquivo incluído passa a ter prioridade.
+func (e *configEditor) AddInclude(path string) {
+	for i, line := range e.lines {
+		if _, ok := activeKey(line); ok {
+			e.insert(i, "Include "+path)
+			return
+		}
+	}
+	e.lines = append(e.lines, "Include "+path)
+}
+
+// plan decide onde uma diretiva será gravada, sem alterar o arquivo
+func (e *configEditor) plan(key string) (editAction, int) {
+	if e.hostBlocks {
+		return e.planClient(key)
+	}
+
+	end := e.globalEnd()
+
+	for i := 0; i < end; i++ {
+		if k, ok := activeKey(e.lines[i]); ok && strings.EqualFold(k, key) {
+			return editReplace, i
+		}
+	}
+
+	for i := 0; i < end; i++ {
+		if k, ok := commentedKey(e.lines[i]); ok && strings.EqualFold(k, key) {
+			return editAfterComment, i
+		}
+	}
+
+	if end < len(e.lines) {
+		// Mantém os comentários que descrevem o bloco Match junto dele
+		idx := end
+		for idx > 0 && strings.HasPrefix(strings.TrimSpace(e.lines[idx-1]), "#") {
+			idx--
+		}
+		return editBeforeMatch, idx
+	}
+
+	return editAppend, len(e.lines)
+}
+
+// planClient decide onde uma diretiva será gravada em um ssh_config. O ssh usa o primeiro
+// valor encontrado entre os blocos que casam com o destino, então um padrão inserido antes
+// dos blocos Host teria prioridade sobre eles. Sem uma definição global para substituir, a
+// diretiva vai para o fim do arquivo, dentro de um bloco "Host *" (criado se necessário).
+func (e *configEditor) planClient(key string) (editAction, int) {
+	global := true
+	tail := 0
+	for i, line := range e.lines {
+		k, ok := activeKey(line)
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(k, "Host") || strings.EqualFold(k, "Match") {
+			global = isGlobalBlock(line)
+			tail = -1
+			if global {
+				tail = i + 1
+			}
+			continue
+		}
+		if global && strings.EqualFold(k, key) {
+			return editReplace, i
+		}
+	}
+
+	if tail < 0 {
+		return editAppendBlock, len(e.lines)
+	}
+	for i := tail; i < len(e.lines); i++ {
+		if k, ok := commentedKey(e.lines[i]); ok && strings.EqualFold(k, key) {
+			return editAfterComment, i
+		}
+	}
+	return editAppend, len(e.lines)
+}
+
+// isGlobalBlock verifica se a linha abre um bloco que vale para todos os destinos ("Host *" ou "Match all")
+func isGlobalBlock(line string) bool {
+	key, value, ok := splitDirective(strings.TrimSpace(line))
+	if !ok {
+		return false
+	}
+	return strings.EqualFold(key, "Host") && value == "*" || strings.EqualFold(key, "Match") && strings.EqualFold(value, "all")
+}
+
+// ShellCommand gera um comando equivalente à edição feita por Set, para relatórios e scripts
+func (e *configEditor) ShellCommand(key, value, path string) string {
+	directive := key + " " + value
+	action, idx := e.plan(key)
+
+	switch action {
+	case editReplace:
+		return fmt.Sprintf("sed -i -E '%ds|^([[:space:]]*).*|\\1%s|' %s", idx+1, directive, path)
+	case editAfterComment:
+		return fmt.Sprintf("sed -i '%da %s' %s", idx+1, directive, path)
+	case editBeforeMatch:
+		return fmt.Sprintf("sed -i '%di %s' %s", idx+1, directive, path)
+	case editAppendBlock:
+		return fmt.Sprintf("echo 'Host *' >> %s && echo '%s' >> %s", path, directive, path)
+	default:
+		return fmt.Sprintf("echo '%s' >> %s", directive, path)
+	}
+}
+
+// globalEnd retorna o índice da primeira linha Match ativa (ou Host, no ssh_config) ou o total de linhas
+func (e *configEditor) globalEnd() int {
+	for i, line := range e.lines {
+		key, ok := activeKey(line)
+		if ok && (strings.EqualFold(key, "Match") || e.hostBlocks && strings.EqualFold(key, "Host")) {
+			return i
+		}
+	}
+	return len(e.lines)
+}
+
+// insert insere uma linha na posição indicada
+func (e *configEditor) insert(idx int, line string) {
+	e.lines = append(e.lines, "")
+	copy(e.lines[idx+1:], e.lines[idx:])
+	e.lines[idx] = line
+}
+
+// activeKey retorna a chave de uma linha de diretiva não comentada
+func activeKey(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+	key, _, ok := splitDirective(line)
+	return key, ok
+}
+
+// commentedKey retorna a chave de uma diretiva comentada (ex: "#PermitRootLogin yes")
+func commentedKey(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "#") {
+		return "", false
+	}
+	line = strings.TrimSpace(strings.TrimLeft(line, "#"))
+
+	key, value, ok := splitDirective(line)
+	if !ok || strings.ContainsAny(key, ":.,") || len(strings.Fields(value)) > 3 {
+		// Provavelmente é um comentário em texto, não uma diretiva
+		return "", false
+	}
+	return key, true
+}
@@ -0,0 +1,286 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// moduliPath é o arquivo com os grupos Diffie-Hellman usados pelo sshd
+const moduliPath = "/etc/ssh/moduli"
+
+// analyzeHostKeys verifica as chaves de host do sshd: tipo, tamanho, dono e permissões
+func (a *Analyzer) analyzeHostKeys(config *sshdConfig) ([]report.Issue, error) {
+	var issues []report.Issue
+
+	// Chaves configuradas explicitamente com HostKey
+	configured := make(map[string]bool)
+	for _, path := range config.GetAll("HostKey") {
+		configured[path] = true
+	}
+
+	// Chaves presentes no diretório padrão, mesmo que não estejam configuradas
+	matches, err := filepath.Glob(a.hostPath("/etc/ssh/ssh_host_*_key"))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar chaves de host SSH: %w", err)
+	}
+
+	keys := make(map[string]bool)
+	for path := range configured {
+		keys[path] = true
+	}
+	for _, match := range matches {
+		keys[a.logicalPath(match)] = true
+	}
+
+	paths := make([]string, 0, len(keys))
+	for path := range keys {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		fullPath := a.hostPath(path)
+
+		info, err := os.Stat(fullPath)
+		if os.IsNotExist(err) {
+			// Apenas as chaves configuradas podem estar ausentes
+			issues = append(issues, report.Issue{
+				Category:     "ssh",
+				Severity:     report.SeverityWarning,
+				Description:  fmt.Sprintf("HostKey aponta para um arquivo inexistente (%s)", path),
+				CurrentValue: "HostKey " + path,
+				FixCommand:   missingHostKeyFix(path),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("erro ao verificar chave de host %s: %w", path, err)
+		}
+
+		issues = append(issues, a.checkHostKeyPermissions(path, info)...)
+
+		key, err := readHostKey(fullPath)
+		if err != nil {
+			// Chaves em formato desconhecido não impedem o restante da análise
+			continue
+		}
+
+		if severity, reason, weak := checkKeyStrength(key); weak {
+			issues = append(issues, report.Issue{
+				Category:         "ssh",
+				Severity:         severity,
+				Description:      fmt.Sprintf("Chave de host fraca em %s: %s", path, reason),
+				CurrentValue:     key.String(),
+				RecommendedValue: fmt.Sprintf("ssh-ed25519 ou ssh-rsa >= %d bits", minRSABits),
+				FixCommand:       regenerateHostKeyFix(path, key),
+			})
+		}
+	}
+
+	return issues, nil
+}
+
+// checkHostKeyPermissions verifica se a chave privada pertence ao root e não é legível por outros
+func (a *Analyzer) checkHostKeyPermissions(path string, info os.FileInfo) []report.Issue {
+	var issues []report.Issue
+	fix := fmt.Sprintf("chown root:root %s && chmod 600 %s", path, path)
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if ok && stat.Uid != 0 {
+		issues = append(issues, report.Issue{
+			Category:         "ssh",
+			Severity:         report.SeverityCritical,
+			Description:      fmt.Sprintf("Chave privada de host não pertence ao root (%s)", path),
+			CurrentValue:     fmt.Sprintf("uid %d", stat.Uid),
+			RecommendedValue: "uid 0",
+			FixCommand:       fix,
+		})
+	}
+
+	mode := info.Mode().Perm()
+	if mode&0007 != 0 {
+		issues = append(issues, report.Issue{
+			Category:         "ssh",
+			Severity:         report.SeverityCritical,
+			Description:      fmt.Sprintf("Chave privada de host acessível por outros usuários (%s)", path),
+			CurrentValue:     fmt.Sprintf("%04o", mode),
+			RecommendedValue: "0600",
+			FixCommand:       fix,
+		})
+		return issues
+	}
+
+	// Algumas distribuições (RHEL, Fedora) usam 0640 com o grupo ssh_keys
+	if mode&0070 != 0 {
+		groupAllowed := ok && mode&0030 == 0 && a.groupName(stat.Gid) == "ssh_keys"
+		if !groupAllowed {
+			issues = append(issues, report.Issue{
+				Category:         "ssh",
+				Severity:         report.SeverityWarning,
+				Description:      fmt.Sprintf("Chave privada de host acessível pelo grupo (%s)", path),
+				CurrentValue:     fmt.Sprintf("%04o", mode),
+				RecommendedValue: "0600",
+				FixCommand:       fix,
+			})
+		}
+	}
+
+	return issues
+}
+
+// analyzeModuli procura grupos Diffie-Hellman menores que o mínimo no arquivo moduli
+func (a *Analyzer) analyzeModuli() ([]report.Issue, error) {
+	file, err := os.Open(a.hostPath(moduliPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo moduli: %w", err)
+	}
+	defer file.Close()
+
+	var total, weak, smallest int
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
+
+		// Formato: timestamp tipo testes tentativas tamanho gerador módulo
+		fields := strings.Fields(line)
+		if len(fields) != 7 {
+			continue
+		}
+
+		// O campo tamanho é o número de bits menos um
+		size, err := strconv.Atoi(fields[4])
+		if err != nil {
+			continue
+		}
+		bits := size + 1
+
+		total++
+		if bits < minModuliBits {
+			weak++
+			if smallest == 0 || bits < smallest {
+				smallest = bits
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo moduli: %w", err)
+	}
+
+	if weak == 0 {
+		return nil, nil
+	}
+
+	return []report.Issue{{
+		Category:         "ssh",
+		Severity:         report.SeverityWarning,
+		Description:      fmt.Sprintf("Arquivo moduli contém grupos Diffie-Hellman com menos de %d bits", minModuliBits),
+		CurrentValue:     fmt.Sprintf("%d de %d grupos abaixo do mínimo (menor: %d bits)", weak, total, smallest),
+		RecommendedValue: fmt.Sprintf(">= %d bits", minModuliBits),
+		FixCommand: fmt.Sprintf("awk '$5 >= %d' %s > %s.safe && mv %s.safe %s",
+			minModuliBits-1, moduliPath, moduliPath, moduliPath, moduliPath),
+	}}, nil
+}
+
+// readHostKey obtém o tipo e o tamanho de uma chave de host.
+// Usa o arquivo .pub quando disponível e, caso contrário, a própria chave privada.
+func readHostKey(path string) (keyInfo, error) {
+	if data, err := os.ReadFile(path + ".pub"); err == nil {
+		if key, err := parsePublicKeyLine(string(data)); err == nil {
+			return key, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return keyInfo{}, err
+	}
+	return parsePrivateKey(data)
+}
+
+// missingHostKeyFix gera o comando para criar uma chave de host ausente
+func missingHostKeyFix(path string) string {
+	keyType := hostKeyTypeFromName(path)
+	if keyType == "" {
+		return fmt.Sprintf("sed -i '\\#^HostKey %s$#d' /etc/ssh/sshd_config", path)
+	}
+	return keygenCommand(keyType, path)
+}
+
+// regenerateHostKeyFix gera o comando para substituir uma chave de host fraca
+func regenerateHostKeyFix(path string, key keyInfo) string {
+	if strings.HasPrefix(key.Type, "ssh-dss") {
+		// DSA não deve ser regenerada, apenas removida
+		return fmt.Sprintf("rm -f %s %s.pub && sed -i '\\#^HostKey %s$#d' /etc/ssh/sshd_config", path, path, path)
+	}
+	return fmt.Sprintf("rm -f %s %s.pub && %s", path, path, keygenCommand("rsa", path))
+}
+
+// keygenCommand monta o comando ssh-keygen para gerar uma chave de host
+func keygenCommand(keyType, path string) string {
+	switch keyType {
+	case "rsa":
+		return fmt.Sprintf("ssh-keygen -q -t rsa -b 4096 -N '' -f %s", path)
+	default:
+		return fmt.Sprintf("ssh-keygen -q -t %s -N '' -f %s", keyType, path)
+	}
+}
+
+// hostKeyTypeFromName deduz o tipo de chave a partir do nome do arquivo
+func hostKeyTypeFromName(path string) string {
+	name := filepath.Base(path)
+	for _, keyType := range []string{"ed25519", "ecdsa", "rsa"} {
+		if strings.Contains(name, keyType) {
+			return keyType
+		}
+	}
+	return ""
+}
+
+// groupName procura o nome de um grupo no /etc/group do sistema analisado
+func (a *Analyzer) groupName(gid uint32) string {
+	groups, err := readGroups(a.mountPoint)
+	if err != nil {
+		return ""
+	}
+	for _, g := range groups {
+		if g.GID == int(gid) {
+			return g.Name
+		}
+	}
+	return ""
+}
+
+// hostPath converte um caminho do sistema analisado para o caminho real, considerando o mountPoint
+func (a *Analyzer) hostPath(path string) string {
+	return util.JoinMount(a.mountPoint, path)
+}
+
+// logicalPath converte um caminho real para o caminho visto pelo sistema analisado
+func (a *Analyzer) logicalPath(path string) string {
+	if a.mountPoint == "" {
+		return path
+	}
+	rel, err := filepath.Rel(a.mountPoint, path)
+	if err != nil {
+		return path
+	}
+	return "/" + rel
+}
@@ -0,0 +1,179 @@
+package ssh
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// keyInfo descreve uma chave pública SSH
+type keyInfo struct {
+	// Type é o algoritmo da chave (ssh-rsa, ssh-ed25519, etc)
+	Type string
+
+	// Bits é o tamanho da chave em bits (0 se desconhecido)
+	Bits int
+
+	// Fingerprint é o fingerprint SHA256 no mesmo formato do ssh-keygen -l
+	Fingerprint string
+}
+
+// String retorna uma descrição curta da chave para os relatórios
+func (k keyInfo) String() string {
+	if k.Bits == 0 {
+		return k.Type
+	}
+	return fmt.Sprintf("%s %d bits", k.Type, k.Bits)
+}
+
+// errUnknownKey indica que o formato da chave não é reconhecido
+var errUnknownKey = errors.New("formato de chave SSH desconhecido")
+
+// parsePublicKeyLine interpreta uma chave pública no formato "tipo base64 [comentário]"
+func parsePublicKeyLine(line string) (keyInfo, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return keyInfo{}, errUnknownKey
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return keyInfo{}, fmt.Errorf("chave pública com base64 inválido: %w", err)
+	}
+
+	return parseWireKey(blob)
+}
+
+// parseWireKey interpreta uma chave pública no formato binário do protocolo SSH (RFC 4253)
+func parseWireKey(blob []byte) (keyInfo, error) {
+	info := keyInfo{Fingerprint: fingerprint(blob)}
+
+	keyType, rest, ok := readString(blob)
+	if !ok {
+		return keyInfo{}, errUnknownKey
+	}
+	info.Type = string(keyType)
+
+	// Certificados carregam um nonce antes dos campos da chave
+	if strings.HasSuffix(info.Type, "-cert-v01@openssh.com") {
+		if _, rest, ok = readString(rest); !ok {
+			return keyInfo{}, errUnknownKey
+		}
+	}
+
+	baseType := strings.TrimSuffix(info.Type, "-cert-v01@openssh.com")
+	baseType = strings.TrimSuffix(baseType, "@openssh.com")
+
+	switch {
+	case baseType == "ssh-rsa":
+		// e, n
+		_, rest, ok = readString(rest)
+		if !ok {
+			return keyInfo{}, errUnknownKey
+		}
+		n, _, ok := readString(rest)
+		if !ok {
+			return keyInfo{}, errUnknownKey
+		}
+		info.Bits = new(big.Int).SetBytes(n).BitLen()
+	case baseType == "ssh-dss":
+		// p, q, g, y
+		p, _, ok := readString(rest)
+		if !ok {
+			return keyInfo{}, errUnknownKey
+		}
+		info.Bits = new(big.Int).SetBytes(p).BitLen()
+	case strings.HasSuffix(baseType, "nistp256"):
+		info.Bits = 256
+	case strings.HasSuffix(baseType, "nistp384"):
+		info.Bits = 384
+	case strings.HasSuffix(baseType, "nistp521"):
+		info.Bits = 521
+	case strings.HasSuffix(baseType, "ed25519"):
+		info.Bits = 256
+	default:
+		return keyInfo{}, errUnknownKey
+	}
+
+	return info, nil
+}
+
+// parsePrivateKey extrai as informações da chave pública contida em uma chave privada.
+// Suporta o formato openssh-key-v1 e os formatos PEM antigos (RSA, DSA e EC).
+func parsePrivateKey(data []byte) (keyInfo, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return keyInfo{}, errUnknownKey
+	}
+
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		const magic = "openssh-key-v1\x00"
+		if !strings.HasPrefix(string(block.Bytes), magic) {
+			return keyInfo{}, errUnknownKey
+		}
+		rest := block.Bytes[len(magic):]
+
+		// ciphername, kdfname, kdfoptions
+		for i := 0; i < 3; i++ {
+			var ok bool
+			if _, rest, ok = readString(rest); !ok {
+				return keyInfo{}, errUnknownKey
+			}
+		}
+
+		// Número de chaves seguido da primeira chave pública (sempre em texto claro)
+		if len(rest) < 4 {
+			return keyInfo{}, errUnknownKey
+		}
+		pub, _, ok := readString(rest[4:])
+		if !ok {
+			return keyInfo{}, errUnknownKey
+		}
+		return parseWireKey(pub)
+	case "RSA PRIVATE KEY":
+		if _, encrypted := block.Headers["Proc-Type"]; encrypted {
+			return keyInfo{Type: "ssh-rsa"}, nil
+		}
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return keyInfo{Type: "ssh-rsa"}, nil
+		}
+		return keyInfo{Type: "ssh-rsa", Bits: key.N.BitLen()}, nil
+	case "DSA PRIVATE KEY":
+		return keyInfo{Type: "ssh-dss"}, nil
+	case "EC PRIVATE KEY":
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return keyInfo{Type: "ecdsa"}, nil
+		}
+		bits := key.Curve.Params().BitSize
+		return keyInfo{Type: fmt.Sprintf("ecdsa-sha2-nistp%d", bits), Bits: bits}, nil
+	}
+
+	return keyInfo{}, errUnknownKey
+}
+
+// readString lê uma string no formato do protocolo SSH (uint32 de tamanho + dados)
+func readString(b []byte) ([]byte, []byte, bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	length := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < length {
+		return nil, nil, false
+	}
+	return b[4 : 4+length], b[4+length:], true
+}
+
+// fingerprint calcula o fingerprint SHA256 de uma chave pública
+func fingerprint(blob []byte) string {
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,331 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// lockoutKeys são as diretivas cuja alteração pode impedir o acesso remoto ao servidor
+var lockoutKeys = map[string]bool{
+	"passwordauthentication": true,
+	"permitrootlogin":        true,
+	"allowusers":             true,
+	"allowgroups":            true,
+	"denyusers":              true,
+	"denygroups":             true,
+}
+
+// defaultAdminGroups são os grupos com sudo quando o sudoers não pode ser lido
+var defaultAdminGroups = []string{"sudo", "wheel", "admin"}
+
+// lockoutCheck reúne as informações necessárias para as verificações de perda de acesso
+type lockoutCheck struct {
+	mountPoint string
+	config     *sshdConfig
+	pending    map[string]string
+	accounts   []account
+	groups     []group
+	sudoers    sudoRules
+}
+
+// sudoRules guarda os usuários e grupos que possuem regras no sudoers
+type sudoRules struct {
+	users    map[string]bool
+	groups   map[string]bool
+	readable bool
+}
+
+// checkLockout verifica se as correções pendentes podem bloquear o acesso SSH ao servidor.
+// Retorna a lista de motivos pelos quais as correções de autenticação devem ser recusadas.
+func (a *Analyzer) checkLockout(config *sshdConfig, issues []report.Issue) ([]string, error) {
+	pending := make(map[string]string)
+	for _, issue := range issues {
+		if lockoutKeys[strings.ToLower(issue.Key)] {
+			pending[strings.ToLower(issue.Key)] = issue.RecommendedValue
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	accounts, err := readAccounts(a.mountPoint)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := readGroups(a.mountPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &lockoutCheck{
+		mountPoint: a.mountPoint,
+		config:     config,
+		pending:    pending,
+		accounts:   accounts,
+		groups:     groups,
+		sudoers:    readSudoers(a.mountPoint),
+	}
+
+	var reasons []string
+
+	// Deve existir ao menos uma conta administrativa que continue acessível por chave, mesmo
+	// que a autenticação por senha continue habilitada: a senha pode ser desabilitada depois
+	var admins []string
+	for _, acc := range accounts {
+		if acc.UID != 0 && check.isAdmin(acc) && check.hasValidKey(acc) && check.canLogin(acc) == "" {
+			admins = append(admins, acc.Name)
+		}
+	}
+	if len(admins) == 0 {
+		reasons = append(reasons, "nenhuma conta além do root possui authorized_keys válido, permissão de sudo e acesso liberado pelo sshd")
+	}
+
+	// A sessão atual não pode perder o acesso (apenas em sistemas em execução)
+	if a.mountPoint == "" {
+		if name := sessionUser(); name != "" {
+			acc, found := check.findAccount(name)
+			if !found {
+				reasons = append(reasons, fmt.Sprintf("o usuário da sessão atual (%s) não existe no /etc/passwd", name))
+			} else if reason := check.canLogin(acc); reason != "" {
+				reasons = append(reasons, fmt.Sprintf("o usuário da sessão atual (%s) perderia o acesso: %s", name, reason))
+			}
+		}
+	}
+
+	return reasons, nil
+}
+
+// canLogin verifica se uma conta continuará podendo entrar via SSH após as correções.
+// Retorna uma string vazia se a conta mantiver o acesso, ou o motivo caso contrário.
+func (c *lockoutCheck) canLogin(acc account) string {
+	// Uma senha bloqueada (ex: lock_passwd do cloud-init) não impede o login por chave com PAM
+	if acc.Expired {
+		return "a conta está expirada"
+	}
+	if acc.Blocked(c.config.UsePAM()) {
+		return "a senha da conta está bloqueada e o sshd usa UsePAM no, que recusa também o login por chave"
+	}
+	if !acc.HasLoginShell() {
+		return "a conta não possui shell de login"
+	}
+
+	if acc.UID == 0 {
+		switch strings.ToLower(c.value("PermitRootLogin")) {
+		case "no":
+			return "PermitRootLogin no impede o login como root"
+		case "forced-commands-only":
+			return "PermitRootLogin forced-commands-only impede o login interativo como root"
+		}
+	}
+
+	if reason := c.allowedBySSHD(acc); reason != "" {
+		return reason
+	}
+
+	if strings.ToLower(c.value("PasswordAuthentication")) == "no" && !c.hasValidKey(acc) {
+		return "a conta não possui chave válida em authorized_keys e a autenticação por senha será desabilitada"
+	}
+
+	return ""
+}
+
+// allowedBySSHD aplica as diretivas DenyUsers, AllowUsers, DenyGroups e AllowGroups
+func (c *lockoutCheck) allowedBySSHD(acc account) string {
+	groups := c.groupsOf(acc)
+
+	if value := c.list("DenyUsers"); value != "" && matchesAny(value, []string{acc.Name}) {
+		return "a conta está listada em DenyUsers"
+	}
+	if value := c.list("AllowUsers"); value != "" && !matchesAny(value, []string{acc.Name}) {
+		return "a conta não está listada em AllowUsers"
+	}
+	if value := c.list("DenyGroups"); value != "" && matchesAny(value, groups) {
+		return "um grupo da conta está listado em DenyGroups"
+	}
+	if value := c.list("AllowGroups"); value != "" && !matchesAny(value, groups) {
+		return "nenhum grupo da conta está listado em AllowGroups"
+	}
+
+	return ""
+}
+
+// hasValidKey verifica se a conta possui ao menos uma chave autorizada que não seja fraca
+func (c *lockoutCheck) hasValidKey(acc account) bool {
+	patterns := defaultAuthorizedKeysFile
+	if value, ok := c.config.Get("AuthorizedKeysFile"); ok {
+		patterns = value
+	}
+
+	for _, file := range resolveAuthorizedKeysFiles(patterns, acc) {
+		keys, err := readAuthorizedKeys(util.JoinMount(c.mountPoint, file))
+		if err != nil {
+			continue
+		}
+		for _, key := range keys {
+			if _, _, weak := checkKeyStrength(key.keyInfo); !weak {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isAdmin verifica se a conta possui permissão de sudo, diretamente ou por grupo
+func (c *lockoutCheck) isAdmin(acc account) bool {
+	if c.sudoers.users[acc.Name] {
+		return true
+	}
+
+	adminGroups := c.sudoers.groups
+	if !c.sudoers.readable {
+		adminGroups = make(map[string]bool)
+		for _, name := range defaultAdminGroups {
+			adminGroups[name] = true
+		}
+	}
+
+	for _, name := range c.groupsOf(acc) {
+		if adminGroups[name] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// value retorna o valor de uma diretiva considerando as correções pendentes
+func (c *lockoutCheck) value(key string) string {
+	if value, ok := c.pending[strings.ToLower(key)]; ok {
+		return value
+	}
+	value, _ := c.config.Get(key)
+	return value
+}
+
+// list retorna os padrões de uma diretiva de lista (AllowUsers, DenyGroups, ...), que o sshd
+// acumula quando ela se repete. A correção pendente substitui a primeira definição, como no editor.
+func (c *lockoutCheck) list(key string) string {
+	values := append([]string(nil), c.config.GetAll(key)...)
+	if value, ok := c.pending[strings.ToLower(key)]; ok {
+		if len(values) == 0 {
+			values = append(values, value)
+		} else {
+			values[0] = value
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// groupsOf retorna os nomes dos grupos de uma conta (primário e suplementares)
+func (c *lockoutCheck) groupsOf(acc account) []string {
+	var names []string
+	for _, g := range c.groups {
+		if g.GID == acc.GID {
+			names = append(names, g.Name)
+			continue
+		}
+		for _, member := range g.Members {
+			if member == acc.Name {
+				names = append(names, g.Name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// findAccount procura uma conta pelo nome
+func (c *lockoutCheck) findAccount(name string) (account, bool) {
+	for _, acc := range c.accounts {
+		if acc.Name == name {
+			return acc, true
+		}
+	}
+	return account{}, false
+}
+
+// matchesAny verifica se algum dos nomes corresponde a algum padrão da lista (com * e ?).
+// Padrões no formato USUARIO@HOST são comparados apenas pela parte do usuário.
+func matchesAny(patterns string, names []string) bool {
+	for _, pattern := range strings.Fields(patterns) {
+		if at := strings.Index(pattern, "@"); at >= 0 {
+			pattern = pattern[:at]
+		}
+		for _, name := range names {
+			if matched, _ := path.Match(pattern, name); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sessionUser retorna o usuário que iniciou a sessão atual, mesmo sob sudo
+func sessionUser() string {
+	if name := os.Getenv("SUDO_USER"); name != "" {
+		return name
+	}
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return ""
+}
+
+// readSudoers lê os usuários e grupos com regras no /etc/sudoers e /etc/sudoers.d
+func readSudoers(mountPoint string) sudoRules {
+	rules := sudoRules{users: make(map[string]bool), groups: make(map[string]bool)}
+
+	files := []string{util.JoinMount(mountPoint, "/etc/sudoers")}
+	if entries, err := os.ReadDir(util.JoinMount(mountPoint, "/etc/sudoers.d")); err == nil {
+		for _, entry := range entries {
+			// O sudo ignora arquivos com "." ou terminados em "~" no includedir
+			name := entry.Name()
+			if entry.IsDir() || strings.Contains(name, ".") || strings.HasSuffix(name, "~") {
+				continue
+			}
+			files = append(files, filepath.Join(util.JoinMount(mountPoint, "/etc/sudoers.d"), name))
+		}
+	}
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		rules.readable = true
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "@") {
+				continue
+			}
+
+			fields := strings.Fields(line)
+			if len(fields) < 2 || strings.HasPrefix(fields[0], "Defaults") || strings.HasSuffix(fields[0], "_Alias") {
+				continue
+			}
+
+			for _, subject := range strings.Split(fields[0], ",") {
+				if strings.HasPrefix(subject, "%") {
+					rules.groups[strings.TrimPrefix(subject, "%")] = true
+				} else {
+					rules.users[subject] = true
+				}
+			}
+		}
+		f.Close()
+	}
+
+	return rules
+}
@@ -0,0 +1,124 @@
+package ssh
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+func TestCheckLockout(t *testing.T) {
+	passwd := "root:x:0:0:root:/root:/bin/bash\n" +
+		"alice:x:1000:1000:Alice:/home/alice:/bin/bash\n" +
+		"bob:x:1001:1001:Bob:/home/bob:/bin/bash\n"
+	group := "root:x:0:\nsudo:x:27:alice\nalice:x:1000:\nbob:x:1001:\nops:x:1002:alice\n"
+	aliceKey := map[string]string{"/home/alice/.ssh/authorized_keys": ed25519Line(1) + "\n"}
+
+	// Senha bloqueada com "!", como grava o cloud-init nas imagens de nuvem
+	lockedShadow := map[string]string{"/etc/shadow": "root:*:19000:0:99999:7:::\nalice:!:19000:0:99999:7:::\n"}
+	withKey := func(files map[string]string) map[string]string {
+		merged := map[string]string{}
+		for path, content := range aliceKey {
+			merged[path] = content
+		}
+		for path, content := range files {
+			merged[path] = content
+		}
+		return merged
+	}
+
+	tests := []struct {
+		name    string
+		config  string
+		files   map[string]string
+		pending []report.Issue
+		locked  bool
+	}{
+		{
+			name:    "administrador com senha bloqueada e chave, UsePAM yes",
+			config:  "UsePAM yes\nPasswordAuthentication yes\n",
+			files:   withKey(lockedShadow),
+			pending: []report.Issue{{Key: "PasswordAuthentication", RecommendedValue: "no"}},
+		},
+		{
+			name:    "administrador com senha bloqueada e chave, UsePAM no",
+			config:  "UsePAM no\n",
+			files:   withKey(lockedShadow),
+			pending: []report.Issue{{Key: "PermitRootLogin", RecommendedValue: "no"}},
+			locked:  true,
+		},
+		{
+			name:    "administrador expirado",
+			config:  "UsePAM yes\n",
+			files:   withKey(map[string]string{"/etc/shadow": "alice:$6$salt$hash:19000:0:99999:7::1:\n"}),
+			pending: []report.Issue{{Key: "PermitRootLogin", RecommendedValue: "no"}},
+			locked:  true,
+		},
+		{
+			name:    "administrador com chave",
+			config:  "PasswordAuthentication yes\n",
+			files:   aliceKey,
+			pending: []report.Issue{{Key: "PermitRootLogin", RecommendedValue: "no"}},
+		},
+		{
+			// A chave é exigida mesmo com a autenticação por senha mantida
+			name:    "administrador sem chave",
+			config:  "PasswordAuthentication yes\n",
+			pending: []report.Issue{{Key: "PermitRootLogin", RecommendedValue: "no"}},
+			locked:  true,
+		},
+		{
+			// O sshd acumula as linhas AllowUsers repetidas
+			name:    "AllowUsers em várias linhas",
+			config:  "AllowUsers bob\nAllowUsers alice\n",
+			files:   aliceKey,
+			pending: []report.Issue{{Key: "PasswordAuthentication", RecommendedValue: "no"}},
+		},
+		{
+			name:    "AllowGroups em várias linhas",
+			config:  "AllowGroups bob\nAllowGroups ops\n",
+			files:   aliceKey,
+			pending: []report.Issue{{Key: "PasswordAuthentication", RecommendedValue: "no"}},
+		},
+		{
+			name:    "administrador fora do AllowUsers",
+			config:  "AllowUsers bob\n",
+			files:   aliceKey,
+			pending: []report.Issue{{Key: "PasswordAuthentication", RecommendedValue: "no"}},
+			locked:  true,
+		},
+		{
+			name:    "correção pendente substitui a primeira linha",
+			config:  "AllowUsers alice\nAllowUsers bob\n",
+			files:   aliceKey,
+			pending: []report.Issue{{Key: "AllowUsers", RecommendedValue: "carol"}},
+			locked:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		files := map[string]string{
+			"/etc/passwd":          passwd,
+			"/etc/group":           group,
+			"/etc/sudoers":         "%sudo ALL=(ALL:ALL) ALL\n",
+			"/etc/ssh/sshd_config": tt.config,
+		}
+		for path, content := range tt.files {
+			files[path] = content
+		}
+		root := writeTree(t, files)
+
+		a := NewAnalyzer(root)
+		config, err := parseConfig(root, a.configPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reasons, err := a.checkLockout(config, tt.pending)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if locked := len(reasons) > 0; locked != tt.locked {
+			t.Errorf("%s: bloqueio = %v, esperado %v (%s)", tt.name, locked, tt.locked, strings.Join(reasons, "; "))
+		}
+	}
+}
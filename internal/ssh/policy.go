@@ -0,0 +1,128 @@
+package ssh
+
+import (
+	"fmt"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+const (
+	// minRSABits é o tamanho mínimo recomendado para chaves RSA
+	minRSABits = 3072
+
+	// criticalRSABits é o tamanho abaixo do qual uma chave RSA é considerada quebrável
+	criticalRSABits = 2048
+
+	// minModuliBits é o tamanho mínimo aceitável para grupos Diffie-Hellman
+	minModuliBits = 3072
+)
+
+// checkKeyStrength verifica se uma chave atende à política de criptografia.
+// Retorna a severidade e o motivo quando a chave é considerada fraca.
+func checkKeyStrength(key keyInfo) (report.Severity, string, bool) {
+	switch key.Type {
+	case "ssh-dss", "ssh-dss-cert-v01@openssh.com":
+		return report.SeverityCritical, "chaves DSA são limitadas a 1024 bits e foram removidas do OpenSSH", true
+	case "ssh-rsa", "ssh-rsa-cert-v01@openssh.com":
+		if key.Bits == 0 {
+			return "", "", false
+		}
+		if key.Bits < criticalRSABits {
+			return report.SeverityCritical, fmt.Sprintf("chave RSA de %d bits é considerada quebrável", key.Bits), true
+		}
+		if key.Bits < minRSABits {
+			return report.SeverityWarning, fmt.Sprintf("chave RSA de %d bits está abaixo do mínimo recomendado de %d bits", key.Bits, minRSABits), true
+		}
+	}
+
+	return "", "", false
+}
+
+// Tipos de algoritmo negociados no handshake SSH
+const (
+	algorithmKex     = "kex"
+	algorithmHostKey = "hostkey"
+	algorithmCipher  = "cipher"
+	algorithmMAC     = "mac"
+)
+
+// weakAlgorithmPolicy lista os algoritmos considerados fracos para cada tipo, com a severidade
+var weakAlgorithmPolicy = map[string]map[string]report.Severity{
+	algorithmKex: {
+		"diffie-hellman-group1-sha1":         report.SeverityCritical,
+		"rsa1024-sha1":                       report.SeverityCritical,
+		"diffie-hellman-group14-sha1":        report.SeverityWarning,
+		"diffie-hellman-group-exchange-sha1": report.SeverityWarning,
+	},
+	algorithmHostKey: {
+		"ssh-dss":                      report.SeverityCritical,
+		"ssh-dss-cert-v01@openssh.com": report.SeverityCritical,
+		"ssh-rsa":                      report.SeverityWarning,
+		"ssh-rsa-cert-v01@openssh.com": report.SeverityWarning,
+	},
+	algorithmCipher: {
+		"des-cbc":                     report.SeverityCritical,
+		"3des-cbc":                    report.SeverityCritical,
+		"blowfish-cbc":                report.SeverityCritical,
+		"cast128-cbc":                 report.SeverityCritical,
+		"arcfour":                     report.SeverityCritical,
+		"arcfour128":                  report.SeverityCritical,
+		"arcfour256":                  report.SeverityCritical,
+		"none":                        report.SeverityCritical,
+		"aes128-cbc":                  report.SeverityWarning,
+		"aes192-cbc":                  report.SeverityWarning,
+		"aes256-cbc":                  report.SeverityWarning,
+		"rijndael-cbc@lysator.liu.se": report.SeverityWarning,
+	},
+	algorithmMAC: {
+		"hmac-md5":                     report.SeverityCritical,
+		"hmac-md5-96":                  report.SeverityCritical,
+		"hmac-md5-etm@openssh.com":     report.SeverityCritical,
+		"hmac-md5-96-etm@openssh.com":  report.SeverityCritical,
+		"hmac-sha1-96":                 report.SeverityCritical,
+		"hmac-sha1-96-etm@openssh.com": report.SeverityCritical,
+		"none":                         report.SeverityCritical,
+		"hmac-sha1":                    report.SeverityWarning,
+		"hmac-sha1-etm@openssh.com":    report.SeverityWarning,
+		"umac-64@openssh.com":          report.SeverityWarning,
+		"umac-64-etm@openssh.com":      report.SeverityWarning,
+		"hmac-ripemd160":               report.SeverityWarning,
+	},
+}
+
+// recommendedAlgorithms são as listas sugeridas quando todos os algoritmos configurados são fracos
+var recommendedAlgorithms = map[string]string{
+	algorithmKex:     "sntrup761x25519-sha512@openssh.com,curve25519-sha256,curve25519-sha256@libssh.org,diffie-hellman-group16-sha512,diffie-hellman-group18-sha512",
+	algorithmHostKey: "ssh-ed25519,rsa-sha2-512,rsa-sha2-256",
+	algorithmCipher:  "chacha20-poly1305@openssh.com,aes256-gcm@openssh.com,aes128-gcm@openssh.com,aes256-ctr,aes192-ctr,aes128-ctr",
+	algorithmMAC:     "hmac-sha2-512-etm@openssh.com,hmac-sha2-256-etm@openssh.com,umac-128-etm@openssh.com",
+}
+
+// algorithmLabels são os nomes dos tipos de algoritmo usados nos relatórios
+var algorithmLabels = map[string]string{
+	algorithmKex:     "troca de chaves",
+	algorithmHostKey: "chave de host",
+	algorithmCipher:  "cifra",
+	algorithmMAC:     "MAC",
+}
+
+// weakAlgorithms separa os algoritmos fracos de uma lista, retornando também a maior severidade
+func weakAlgorithms(kind string, algorithms []string) ([]string, []string, report.Severity) {
+	var weak, strong []string
+	var severity report.Severity
+
+	for _, algorithm := range algorithms {
+		algorithmSeverity, isWeak := weakAlgorithmPolicy[kind][algorithm]
+		if !isWeak {
+			strong = append(strong, algorithm)
+			continue
+		}
+
+		weak = append(weak, algorithm)
+		if severity != report.SeverityCritical {
+			severity = algorithmSeverity
+		}
+	}
+
+	return weak, strong, severity
+}
@@ -0,0 +1,283 @@
+package ssh
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+const (
+	// probeBanner é a identificação enviada pelo Hardshell ao servidor
+	probeBanner = "SSH-2.0-Hardshell_probe"
+
+	// msgKexInit é o código da mensagem SSH_MSG_KEXINIT (RFC 4253, seção 7.1)
+	msgKexInit = 20
+
+	// maxPacketSize é o maior pacote aceito durante o handshake
+	maxPacketSize = 256 * 1024
+
+	// maxPreBannerLines limita as linhas que o servidor pode enviar antes da identificação
+	maxPreBannerLines = 32
+)
+
+// ProbeResult guarda o que um servidor SSH oferece durante o handshake
+type ProbeResult struct {
+	Address           string
+	Banner            string
+	Software          string
+	KexAlgorithms     []string
+	HostKeyAlgorithms []string
+	Ciphers           []string
+	MACs              []string
+	Compression       []string
+}
+
+// Probe conecta em um servidor SSH e realiza a troca de identificação e de KEXINIT, sem autenticar
+func Probe(address string, timeout time.Duration) (*ProbeResult, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar em %s: %w", address, err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	result, err := ProbeConn(conn)
+	if err != nil {
+		return nil, fmt.Errorf("erro no handshake com %s: %w", address, err)
+	}
+	result.Address = address
+
+	return result, nil
+}
+
+// ProbeConn realiza o handshake sobre uma conexão já aberta.
+// Permite testar o probe contra um servidor simulado.
+func ProbeConn(conn io.ReadWriter) (*ProbeResult, error) {
+	if _, err := io.WriteString(conn, probeBanner+"\r\n"); err != nil {
+		return nil, fmt.Errorf("erro ao enviar identificação: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+
+	// O servidor pode enviar outras linhas antes da identificação "SSH-"
+	var banner string
+	for i := 0; i < maxPreBannerLines && banner == ""; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler identificação do servidor: %w", err)
+		}
+		if line = strings.TrimRight(line, "\r\n"); strings.HasPrefix(line, "SSH-") {
+			banner = line
+		}
+	}
+	if banner == "" {
+		return nil, fmt.Errorf("o servidor não enviou uma identificação SSH")
+	}
+
+	result := &ProbeResult{Banner: banner}
+
+	// SSH-protoversion-softwareversion [comentários]; servidores defeituosos podem omitir a versão
+	if parts := strings.SplitN(banner, "-", 3); len(parts) == 3 {
+		if fields := strings.Fields(parts[2]); len(fields) > 0 {
+			result.Software = fields[0]
+		}
+	}
+
+	payload, err := readPacket(reader)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) == 0 || payload[0] != msgKexInit {
+		return nil, fmt.Errorf("mensagem inesperada do servidor (esperado KEXINIT)")
+	}
+
+	lists, err := parseKexInit(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	result.KexAlgorithms = lists[0]
+	result.HostKeyAlgorithms = lists[1]
+	result.Ciphers = mergeLists(lists[2], lists[3])
+	result.MACs = mergeLists(lists[4], lists[5])
+	result.Compression = mergeLists(lists[6], lists[7])
+
+	// Responde com o próprio KEXINIT para completar a troca antes de encerrar a conexão
+	if err := writePacket(conn, buildKexInit()); err != nil {
+		return nil, fmt.Errorf("erro ao enviar KEXINIT: %w", err)
+	}
+
+	return result, nil
+}
+
+// Issues avalia os algoritmos oferecidos pelo servidor com a mesma política da análise do sshd_config
+func (r *ProbeResult) Issues() []report.Issue {
+	var issues []report.Issue
+
+	offered := []struct {
+		Kind       string
+		Algorithms []string
+	}{
+		{algorithmKex, r.KexAlgorithms},
+		{algorithmHostKey, r.HostKeyAlgorithms},
+		{algorithmCipher, r.Ciphers},
+		{algorithmMAC, r.MACs},
+	}
+
+	for _, list := range offered {
+		weak, _, severity := weakAlgorithms(list.Kind, list.Algorithms)
+		if len(weak) == 0 {
+			continue
+		}
+
+		issues = append(issues, report.Issue{
+			Category:     "ssh",
+			Severity:     severity,
+			Description:  fmt.Sprintf("Servidor %s oferece algoritmos de %s fracos: %s", r.Address, algorithmLabels[list.Kind], strings.Join(weak, ", ")),
+			CurrentValue: strings.Join(list.Algorithms, ","),
+		})
+	}
+
+	return issues
+}
+
+// readPacket lê um pacote binário sem criptografia (RFC 4253, seção 6)
+func readPacket(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, fmt.Errorf("erro ao ler pacote SSH: %w", err)
+	}
+
+	length := binary.BigEndian.Uint32(header[:4])
+	padding := uint32(header[4])
+	if length < padding+1 || length > maxPacketSize {
+		return nil, fmt.Errorf("pacote SSH com tamanho inválido: %d", length)
+	}
+
+	body := make([]byte, length-1)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("erro ao ler pacote SSH: %w", err)
+	}
+
+	return body[:len(body)-int(padding)], nil
+}
+
+// writePacket grava um pacote binário sem criptografia, com padding alinhado a 8 bytes
+func writePacket(w io.Writer, payload []byte) error {
+	padding := 8 - (len(payload)+5)%8
+	if padding < 4 {
+		padding += 8
+	}
+
+	packet := make([]byte, 5+len(payload)+padding)
+	binary.BigEndian.PutUint32(packet, uint32(1+len(payload)+padding))
+	packet[4] = byte(padding)
+	copy(packet[5:], payload)
+
+	_, err := w.Write(packet)
+	return err
+}
+
+// parseKexInit extrai as dez listas de nomes de uma mensagem KEXINIT
+func parseKexInit(payload []byte) ([10][]string, error) {
+	var lists [10][]string
+
+	// Código da mensagem (1 byte) e cookie (16 bytes)
+	if len(payload) < 17 {
+		return lists, fmt.Errorf("KEXINIT truncado")
+	}
+	rest := payload[17:]
+
+	for i := range lists {
+		var field []byte
+		var ok bool
+		if field, rest, ok = readString(rest); !ok {
+			return lists, fmt.Errorf("KEXINIT truncado")
+		}
+		if len(field) > 0 {
+			lists[i] = strings.Split(string(field), ",")
+		}
+	}
+
+	return lists, nil
+}
+
+// buildKexInit monta a mensagem KEXINIT enviada pelo probe
+func buildKexInit() []byte {
+	lists := []string{
+		recommendedAlgorithms[algorithmKex],
+		recommendedAlgorithms[algorithmHostKey],
+		recommendedAlgorithms[algorithmCipher],
+		recommendedAlgorithms[algorithmCipher],
+		recommendedAlgorithms[algorithmMAC],
+		recommendedAlgorithms[algorithmMAC],
+		"none",
+		"none",
+		"",
+		"",
+	}
+
+	payload := make([]byte, 17, 1024)
+	payload[0] = msgKexInit
+	rand.Read(payload[1:17])
+
+	for _, list := range lists {
+		payload = binary.BigEndian.AppendUint32(payload, uint32(len(list)))
+		payload = append(payload, list...)
+	}
+
+	// first_kex_packet_follows (false) e campo reservado
+	return append(payload, 0, 0, 0, 0, 0)
+}
+
+// mergeLists une as listas dos dois sentidos da conexão, sem repetições
+func mergeLists(a, b []string) []string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, item := range append(append([]string{}, a...), b...) {
+		if !seen[item] {
+			seen[item] = true
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+// DefaultProbeAddress determina o endereço do sshd local a partir do sshd_config
+func DefaultProbeAddress(mountPoint string) string {
+	port := "22"
+	host := "127.0.0.1"
+
+	config, err := parseConfig(mountPoint, util.JoinMount(mountPoint, "/etc/ssh/sshd_config"))
+	if err != nil {
+		return net.JoinHostPort(host, port)
+	}
+
+	if value, ok := config.Get("Port"); ok {
+		port = value
+	}
+
+	// Usa o primeiro ListenAddress específico; endereços curinga são acessíveis pelo loopback
+	if value, ok := config.Get("ListenAddress"); ok {
+		listen := strings.Fields(value)[0]
+		if h, p, err := net.SplitHostPort(listen); err == nil {
+			listen, port = h, p
+		}
+		if listen != "0.0.0.0" && listen != "::" && listen != "*" {
+			host = strings.Trim(listen, "[]")
+		}
+	}
+
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,145 @@
+package ssh
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+// weakKexLists são os algoritmos anunciados pelo servidor simulado, incluindo alguns fracos
+var weakKexLists = []string{
+	"curve25519-sha256,diffie-hellman-group1-sha1",
+	"ssh-ed25519,ssh-rsa",
+	"aes256-gcm@openssh.com,3des-cbc",
+	"aes256-gcm@openssh.com,aes128-ctr",
+	"hmac-sha2-256-etm@openssh.com,hmac-md5",
+	"hmac-sha2-256-etm@openssh.com",
+	"none,zlib@openssh.com",
+	"none",
+	"",
+	"",
+}
+
+// kexInitPayload monta uma mensagem KEXINIT com as listas informadas
+func kexInitPayload(lists []string) []byte {
+	payload := make([]byte, 17)
+	payload[0] = msgKexInit
+	for _, list := range lists {
+		payload = binary.BigEndian.AppendUint32(payload, uint32(len(list)))
+		payload = append(payload, list...)
+	}
+	return append(payload, 0, 0, 0, 0, 0)
+}
+
+// probeStub executa ProbeConn contra um servidor simulado que envia as linhas de
+// identificação e o pacote informados, lendo tudo o que o probe enviar
+func probeStub(t *testing.T, lines []string, payload []byte) (*ProbeResult, error) {
+	t.Helper()
+
+	client, server := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reader := bufio.NewReader(server)
+		if line, err := reader.ReadString('\n'); err != nil || line != probeBanner+"\r\n" {
+			t.Errorf("identificação do probe inesperada: %q (%v)", line, err)
+			server.Close()
+			return
+		}
+
+		go io.Copy(io.Discard, reader)
+		for _, line := range lines {
+			if _, err := io.WriteString(server, line+"\r\n"); err != nil {
+				return
+			}
+		}
+		if payload == nil {
+			// Sem pacote, o servidor encerra a conexão
+			server.Close()
+			return
+		}
+		writePacket(server, payload)
+	}()
+
+	result, err := ProbeConn(client)
+	client.Close()
+	<-done
+	server.Close()
+	return result, err
+}
+
+func TestProbeConn(t *testing.T) {
+	result, err := probeStub(t,
+		[]string{"Bem-vindo", "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13"},
+		kexInitPayload(weakKexLists))
+	if err != nil {
+		t.Fatalf("ProbeConn: %v", err)
+	}
+
+	if result.Banner != "SSH-2.0-OpenSSH_9.6p1 Ubuntu-3ubuntu13" {
+		t.Errorf("Banner = %q", result.Banner)
+	}
+	if result.Software != "OpenSSH_9.6p1" {
+		t.Errorf("Software = %q", result.Software)
+	}
+	if want := []string{"curve25519-sha256", "diffie-hellman-group1-sha1"}; !reflect.DeepEqual(result.KexAlgorithms, want) {
+		t.Errorf("KexAlgorithms = %v, esperado %v", result.KexAlgorithms, want)
+	}
+	if want := []string{"aes256-gcm@openssh.com", "3des-cbc", "aes128-ctr"}; !reflect.DeepEqual(result.Ciphers, want) {
+		t.Errorf("Ciphers = %v, esperado %v", result.Ciphers, want)
+	}
+	if want := []string{"none", "zlib@openssh.com"}; !reflect.DeepEqual(result.Compression, want) {
+		t.Errorf("Compression = %v, esperado %v", result.Compression, want)
+	}
+
+	issues := result.Issues()
+	if len(issues) != 4 {
+		t.Fatalf("Issues() retornou %d questões, esperado 4: %+v", len(issues), issues)
+	}
+	for _, issue := range issues {
+		if issue.Severity == report.SeverityInfo || issue.Severity == report.SeveritySkip {
+			t.Errorf("severidade inesperada para %q: %s", issue.Description, issue.Severity)
+		}
+	}
+	if !strings.Contains(issues[0].Description, "diffie-hellman-group1-sha1") {
+		t.Errorf("questão de troca de chaves sem o algoritmo fraco: %q", issues[0].Description)
+	}
+}
+
+func TestProbeConnMalformedBanner(t *testing.T) {
+	for _, banner := range []string{"SSH-2.0-", "SSH-2.0-   ", "SSH-2.0"} {
+		result, err := probeStub(t, []string{banner}, kexInitPayload(weakKexLists))
+		if err != nil {
+			t.Fatalf("ProbeConn(%q): %v", banner, err)
+		}
+		if result.Software != "" {
+			t.Errorf("ProbeConn(%q): Software = %q, esperado vazio", banner, result.Software)
+		}
+	}
+}
+
+func TestProbeConnErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		payload []byte
+	}{
+		{"sem identificação", nil, nil},
+		{"mensagem diferente de KEXINIT", []string{"SSH-2.0-OpenSSH_9.6"}, []byte{21, 0, 0, 0}},
+		{"KEXINIT truncado", []string{"SSH-2.0-OpenSSH_9.6"}, kexInitPayload(weakKexLists)[:30]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := probeStub(t, tt.lines, tt.payload); err == nil {
+				t.Error("ProbeConn deveria falhar")
+			}
+		})
+	}
+}
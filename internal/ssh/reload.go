@@ -0,0 +1,152 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/atomicfile"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// installConfig valida os arquivos candidatos com sshd -t, substitui os arquivos atuais e
+// recarrega o sshd. Se a validação ou o reload falharem, a configuração anterior é restaurada.
+func (a *Analyzer) installConfig(main, dropIn *atomicfile.Change) error {
+	changes := []*atomicfile.Change{main}
+	if dropIn != nil {
+		changes = append(changes, dropIn)
+	}
+
+	// Grava os candidatos ao lado dos arquivos originais
+	if err := atomicfile.Write(changes); err != nil {
+		return err
+	}
+
+	// Em um mountPoint o sshd do host não é o do sistema analisado, então não há validação nem reload
+	if a.mountPoint == "" {
+		if err := validateCandidate(main, dropIn); err != nil {
+			atomicfile.Remove(changes)
+			return err
+		}
+	} else {
+		fmt.Println("Validação com sshd -t ignorada em ponto de montagem")
+	}
+
+	if err := atomicfile.Install(changes, false); err != nil {
+		return err
+	}
+
+	if a.mountPoint != "" {
+		return nil
+	}
+
+	if err := reloadSSHD(); err != nil {
+		atomicfile.Restore(changes)
+		fmt.Println("Configuração SSH anterior restaurada")
+		if reloadErr := reloadSSHD(); reloadErr != nil {
+			return fmt.Errorf("erro ao recarregar o sshd (%v) e ao recarregar a configuração restaurada: %w", err, reloadErr)
+		}
+		return fmt.Errorf("erro ao recarregar o sshd, configuração anterior restaurada: %w", err)
+	}
+
+	return nil
+}
+
+// validateCandidate executa sshd -t sobre a configuração candidata
+func validateCandidate(main, dropIn *atomicfile.Change) error {
+	sshd := sshdBinary()
+	if sshd == "" {
+		return fmt.Errorf("sshd não encontrado, não é possível validar a configuração antes de aplicá-la")
+	}
+
+	config := main.Candidate()
+
+	// No modo drop-in o candidato principal ainda inclui o drop-in atual; por isso a validação
+	// usa um arquivo temporário que inclui primeiro o drop-in candidato, que tem prioridade
+	if dropIn != nil {
+		validation, err := os.CreateTemp("", "sshd_config-hardshell-*")
+		if err != nil {
+			return fmt.Errorf("erro ao criar arquivo temporário de validação: %w", err)
+		}
+		defer os.Remove(validation.Name())
+
+		content := fmt.Sprintf("Include %s\n%s", dropIn.Candidate(), main.New)
+		if _, err := validation.WriteString(content); err != nil {
+			validation.Close()
+			return fmt.Errorf("erro ao gravar arquivo temporário de validação: %w", err)
+		}
+		validation.Close()
+		config = validation.Name()
+	}
+
+	output, err := exec.Command(sshd, "-t", "-f", config).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("configuração SSH inválida, nenhuma alteração foi aplicada: %s", strings.TrimSpace(string(output)))
+	}
+
+	fmt.Println("Configuração validada com sshd -t")
+	return nil
+}
+
+// reloadSSHD recarrega o sshd sem derrubar as conexões existentes
+func reloadSSHD() error {
+	if util.HasCommand("systemctl") {
+		unit := sshdUnit()
+		if unit == "" {
+			fmt.Println("Serviço SSH não está ativo; a nova configuração será usada na próxima inicialização")
+			return nil
+		}
+		return runReload("systemctl", "reload", unit)
+	}
+
+	if util.HasCommand("rc-service") {
+		return runReload("rc-service", "sshd", "reload")
+	}
+
+	if util.HasCommand("service") {
+		// Debian e derivados usam "ssh"; as demais distribuições usam "sshd"
+		name := "sshd"
+		if _, err := os.Stat("/etc/init.d/ssh"); err == nil {
+			name = "ssh"
+		}
+		return runReload("service", name, "reload")
+	}
+
+	return fmt.Errorf("não foi possível encontrar um método para recarregar o sshd")
+}
+
+// sshdUnit retorna a unit systemd ativa do sshd ("ssh.service" no Debian/Ubuntu,
+// "sshd.service" nas demais distribuições). Com ativação por socket (ssh.socket),
+// cada conexão inicia um novo sshd que já lê a nova configuração, e nada precisa ser recarregado.
+func sshdUnit() string {
+	for _, unit := range []string{"ssh.service", "sshd.service"} {
+		if exec.Command("systemctl", "is-active", "--quiet", unit).Run() == nil {
+			return unit
+		}
+	}
+	return ""
+}
+
+// runReload executa o comando de reload e inclui sua saída no erro
+func runReload(name string, args ...string) error {
+	fmt.Printf("Recarregando o sshd: %s %s\n", name, strings.Join(args, " "))
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %s", name, strings.Join(args, " "), strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// sshdBinary procura o executável do sshd
+func sshdBinary() string {
+	if path, err := exec.LookPath("sshd"); err == nil {
+		return path
+	}
+	for _, path := range []string{"/usr/sbin/sshd", "/usr/local/sbin/sshd"} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
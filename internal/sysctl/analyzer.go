@@ -1,21 +1,18 @@
 package sysctl
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
-	"strings"
 
+	"github.com/mairinkdev/Hardshell/internal/atomicfile"
 	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/rules"
 )
 
 // Analyzer é o analisador de configurações sysctl
 type Analyzer struct {
 	mountPoint string
-	configPath string
 	rules      []SysctlRule
+	container  bool
 }
 
 // SysctlRule representa uma regra para verificação de configuração sysctl
@@ -24,239 +21,283 @@ type SysctlRule struct {
 	RecommendedValue  string
 	Severity          report.Severity
 	Description       string
-	ComparisonFunc    func(string, string) bool
+
+	// Expect define os valores aceitos, comparados com o valor atual do parâmetro
+	Expect rules.Expectation
 }
 
 // NewAnalyzer cria um novo analisador sysctl
 func NewAnalyzer(mountPoint string) *Analyzer {
-	configPath := "/etc/sysctl.conf"
-	if mountPoint != "" {
-		configPath = filepath.Join(mountPoint, configPath)
-	}
-
 	return &Analyzer{
 		mountPoint: mountPoint,
-		configPath: configPath,
 		rules:      getDefaultRules(),
+		container:  detectContainer(mountPoint),
+	}
+}
+
+// InContainer informa se o sistema analisado foi identificado como um contêiner.
+// Nesse caso apenas os parâmetros dos namespaces de rede e IPC são verificados.
+func (a *Analyzer) InContainer() bool {
+	return a.container
+}
+
+// SetRules aplica as regras do arquivo de regras. Regras com a mesma chave de uma regra padrão
+// substituem os campos preenchidos; as demais são adicionadas e, sem expect, exigem o valor recomendado.
+func (a *Analyzer) SetRules(custom []rules.Rule) {
+	for _, rule := range custom {
+		idx := -1
+		for i := range a.rules {
+			if a.rules[i].Key == rule.Key {
+				idx = i
+				break
+			}
+		}
+
+		if idx < 0 {
+			a.rules = append(a.rules, SysctlRule{
+				Key:      rule.Key,
+				Severity: report.SeverityWarning,
+				Expect:   rules.Equal(),
+			})
+			idx = len(a.rules) - 1
+		}
+
+		if rule.RecommendedValue != "" {
+			a.rules[idx].RecommendedValue = rule.RecommendedValue
+		}
+		if rule.Severity != "" {
+			a.rules[idx].Severity = report.Severity(rule.Severity)
+		}
+		if rule.Description != "" {
+			a.rules[idx].Description = rule.Description
+		}
+		if rule.Expect != nil {
+			a.rules[idx].Expect = *rule.Expect
+		}
+		if a.rules[idx].Description == "" {
+			a.rules[idx].Description = fmt.Sprintf("%s deve ser %s", rule.Key, a.rules[idx].Expect.Describe(a.rules[idx].RecommendedValue))
+		}
 	}
 }
 
 // Analyze analisa as configurações sysctl relacionadas à segurança
 func (a *Analyzer) Analyze() ([]report.Issue, error) {
-	// Verifica se o arquivo de configuração existe
-	if _, err := os.Stat(a.configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("arquivo de configuração sysctl não encontrado: %s", a.configPath)
-	}
+	issues, _, err := a.evaluate()
+	return issues, err
+}
 
-	// Lê o arquivo de configuração
-	configFile, err := os.Open(a.configPath)
+// evaluate avalia as regras e retorna, junto com os problemas, as correções necessárias
+func (a *Analyzer) evaluate() ([]report.Issue, []remediation, error) {
+	// Lê a configuração persistente na mesma ordem do systemd-sysctl
+	config, err := readPersisted(a.mountPoint)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao abrir arquivo de configuração sysctl: %w", err)
+		return nil, nil, err
 	}
-	defer configFile.Close()
 
-	// Analisa o arquivo de configuração
-	config := make(map[string]string)
-	scanner := bufio.NewScanner(configFile)
+	// Verifica as regras
+	var issues []report.Issue
+	var fixes []remediation
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	check := func(rule SysctlRule) {
+		if issue, fix, found := a.checkRule(rule, config); found {
+			issues = append(issues, issue)
+			if fix.Persist || fix.Runtime {
+				fixes = append(fixes, fix)
+			}
+		}
+	}
 
-		// Ignora comentários e linhas em branco
-		if strings.HasPrefix(line, "#") || line == "" {
+	for _, rule := range a.rules {
+		// Um contêiner não pode alterar os parâmetros globais do kernel, que dependem do host
+		if a.container && keyScope(rule.Key) == scopeHost {
+			issues = append(issues, hostOnlyIssue(rule))
 			continue
 		}
 
-		// Separa a chave e o valor
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) != 2 {
+		// Regras com curingas são avaliadas para cada chave correspondente (ex: cada interface)
+		if isPattern(rule.Key) {
+			expanded := a.expandRule(rule, config)
+			if len(expanded) == 0 && a.mountPoint == "" && missingFromKernel(rule.Key) {
+				issues = append(issues, skipIssue(rule))
+			}
+			for _, concrete := range expanded {
+				check(concrete)
+			}
 			continue
 		}
+		check(rule)
+	}
 
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+	return issues, fixes, nil
+}
 
-		config[key] = value
+// checkRule avalia uma regra contra a configuração persistida e, em análises do sistema
+// em execução, também contra o valor atual em /proc/sys
+func (a *Analyzer) checkRule(rule SysctlRule, config *persistedConfig) (report.Issue, remediation, bool) {
+	current, exists := config.Get(rule.Key)
+	value := current.Value
+	persistedOK := exists && rule.Expect.Match(value, rule.RecommendedValue)
+
+	issue := report.Issue{
+		Category:         "sysctl",
+		Key:              rule.Key,
+		Severity:         rule.Severity,
+		Description:      rule.Description,
+		CurrentValue:     value,
+		RecommendedValue: rule.RecommendedValue,
+		Expected:         rule.Expect.Describe(rule.RecommendedValue),
 	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao ler arquivo de configuração sysctl: %w", err)
+	if exists {
+		issue.Source = current.Source()
 	}
+	fix := remediation{Key: rule.Key, Value: rule.RecommendedValue}
 
-	// Verifica arquivos adicionais em sysctl.d se não estiver em um mountPoint
+	// Em um mountPoint o kernel em execução não é o do sistema analisado
+	runtime, live := "", false
 	if a.mountPoint == "" {
-		err = a.readSysctlD(config)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		// Usa o mountPoint para verificar o diretório sysctl.d
-		sysctlDPath := filepath.Join(a.mountPoint, "/etc/sysctl.d")
-		err = a.readSysctlDFromPath(sysctlDPath, config)
-		if err != nil {
-			return nil, err
+		if missingFromKernel(rule.Key) {
+			return skipIssue(rule), remediation{}, true
 		}
+		runtime, live = readRuntime(rule.Key)
 	}
 
-	// Verifica as regras
-	var issues []report.Issue
-
-	for _, rule := range a.rules {
-		value, exists := config[rule.Key]
-
-		// Se a configuração não existe, considere como uma violação
-		if !exists {
-			issues = append(issues, report.Issue{
-				Category:         "sysctl",
-				Severity:         rule.Severity,
-				Description:      rule.Description,
-				RecommendedValue: rule.RecommendedValue,
-				FixCommand:       fmt.Sprintf("echo '%s = %s' >> /etc/sysctl.conf && sysctl -p", rule.Key, rule.RecommendedValue),
-			})
-			continue
+	// Chaves de interfaces efêmeras não são gravadas no drop-in, onde se acumulariam a cada
+	// contêiner; o valor é corrigido apenas em execução e as novas interfaces seguem o default
+	if ephemeralInterface(rule.Key) {
+		if !live || rule.Expect.Match(runtime, rule.RecommendedValue) {
+			return issue, fix, false
 		}
+		issue.Description = fmt.Sprintf("%s (interface efêmera, corrigida apenas em execução)", rule.Description)
+		issue.CurrentValue = runtime
+		issue.FixCommand = runtimeFix(fix)
+		fix.Runtime = true
+		return issue, fix, true
+	}
 
-		// Verifica se o valor atual atende à regra
-		if !rule.ComparisonFunc(value, rule.RecommendedValue) {
-			issues = append(issues, report.Issue{
-				Category:         "sysctl",
-				Severity:         rule.Severity,
-				Description:      rule.Description,
-				CurrentValue:     value,
-				RecommendedValue: rule.RecommendedValue,
-				FixCommand:       fmt.Sprintf("sed -i 's/^%s.*/%s = %s/' /etc/sysctl.conf && sysctl -p", rule.Key, rule.Key, rule.RecommendedValue),
-			})
+	if !live {
+		if persistedOK {
+			return issue, fix, false
 		}
+		fix.Persist = true
+		issue.FixCommand = dropInCommand(rule.Key, rule.RecommendedValue, current, exists)
+		return issue, fix, true
 	}
 
-	return issues, nil
-}
+	runtimeOK := rule.Expect.Match(runtime, rule.RecommendedValue)
+	drift := exists && value != runtime
+
+	switch {
+	case persistedOK && runtimeOK:
+		return issue, fix, false
+	case persistedOK:
+		// A configuração está correta, mas não foi aplicada (ou foi alterada em execução)
+		issue.Description = fmt.Sprintf("%s (valor em execução difere do persistido: %s)", rule.Description, runtime)
+		issue.CurrentValue = runtime
+
+		// O valor persistido é aceito pela regra e é ele que deve voltar a valer
+		fix.Value = value
+		fix.Runtime = true
+		issue.FixCommand = runtimeFix(fix)
+	case runtimeOK:
+		// O valor foi aplicado em execução, mas não sobrevive a uma reinicialização
+		if drift {
+			issue.Description = fmt.Sprintf("%s (valor em execução está correto, mas o persistido é %s)", rule.Description, value)
+		} else {
+			issue.Description = fmt.Sprintf("%s (valor em execução está correto, mas não é persistido)", rule.Description)
+		}
+		issue.FixCommand = dropInCommand(rule.Key, rule.RecommendedValue, current, exists)
+		fix.Persist = true
+	default:
+		if drift {
+			issue.Description = fmt.Sprintf("%s (em execução: %s, persistido: %s)", rule.Description, runtime, value)
+		}
+		issue.CurrentValue = runtime
+		issue.FixCommand = dropInCommand(rule.Key, rule.RecommendedValue, current, exists) + " && " + runtimeFix(fix)
+		fix.Persist = true
+		fix.Runtime = true
+	}
 
-// readSysctlD lê os arquivos .conf em /etc/sysctl.d/
-func (a *Analyzer) readSysctlD(config map[string]string) error {
-	sysctlDPath := "/etc/sysctl.d"
-	return a.readSysctlDFromPath(sysctlDPath, config)
+	return issue, fix, true
 }
 
-// readSysctlDFromPath lê os arquivos .conf em um diretório específico
-func (a *Analyzer) readSysctlDFromPath(dirPath string, config map[string]string) error {
-	// Verifica se o diretório existe
-	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
-		return nil // Não é um erro, apenas não existem arquivos adicionais
+// skipIssue indica que a regra não se aplica porque o parâmetro não existe no kernel em execução
+func skipIssue(rule SysctlRule) report.Issue {
+	return report.Issue{
+		Category:    "sysctl",
+		Severity:    report.SeveritySkip,
+		Description: fmt.Sprintf("%s (%s não existe no kernel em execução)", rule.Description, rule.Key),
 	}
+}
 
-	// Lê o diretório
-	files, err := os.ReadDir(dirPath)
-	if err != nil {
-		return fmt.Errorf("erro ao ler diretório sysctl.d: %w", err)
-	}
-
-	// Analisa cada arquivo .conf
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".conf") {
-			filePath := filepath.Join(dirPath, file.Name())
-
-			// Abre e lê o arquivo
-			configFile, err := os.Open(filePath)
-			if err != nil {
-				return fmt.Errorf("erro ao abrir arquivo de configuração sysctl: %w", err)
-			}
-
-			scanner := bufio.NewScanner(configFile)
-			for scanner.Scan() {
-				line := strings.TrimSpace(scanner.Text())
-
-				// Ignora comentários e linhas em branco
-				if strings.HasPrefix(line, "#") || line == "" {
-					continue
-				}
-
-				// Separa a chave e o valor
-				parts := strings.SplitN(line, "=", 2)
-				if len(parts) != 2 {
-					continue
-				}
-
-				key := strings.TrimSpace(parts[0])
-				value := strings.TrimSpace(parts[1])
-
-				// Apenas sobrescreve se não existir (arquivos em /etc/sysctl.conf têm precedência)
-				if _, exists := config[key]; !exists {
-					config[key] = value
-				}
-			}
-
-			configFile.Close()
-
-			if err := scanner.Err(); err != nil {
-				return fmt.Errorf("erro ao ler arquivo de configuração sysctl: %w", err)
-			}
-		}
+// hostOnlyIssue indica que a regra cabe ao host, pois o parâmetro não pertence a um namespace do contêiner
+func hostOnlyIssue(rule SysctlRule) report.Issue {
+	return report.Issue{
+		Category:    "sysctl",
+		Severity:    report.SeveritySkip,
+		Description: fmt.Sprintf("%s (%s é global do kernel e deve ser configurado no host)", rule.Description, rule.Key),
 	}
+}
 
-	return nil
+// runtimeFix gera o comando que aplica o valor da correção ao kernel em execução
+func runtimeFix(fix remediation) string {
+	return fmt.Sprintf("sysctl -w %s=%s", fix.Key, fix.Value)
 }
 
-// Fix gera um script para corrigir os problemas encontrados
+// Fix grava os valores corrigidos em /etc/sysctl.d/99-hardshell.conf e, no sistema em execução,
+// aplica-os em /proc/sys. Se os arquivos não puderem ser gravados, os valores em execução são revertidos.
 func (a *Analyzer) Fix() error {
 	// Analisa os problemas
-	issues, err := a.Analyze()
+	_, fixes, err := a.evaluate()
 	if err != nil {
 		return err
 	}
 
-	if len(issues) == 0 {
+	if len(fixes) == 0 {
 		fmt.Println("Nenhum problema encontrado nas configurações sysctl.")
 		return nil
 	}
 
-	// Cria um backup do arquivo de configuração
-	backupPath := a.configPath + ".bak"
-	err = copyFile(a.configPath, backupPath)
-	if err != nil {
-		return fmt.Errorf("erro ao criar backup do arquivo de configuração: %w", err)
+	values := make(map[string]string)
+	var runtimeFixes []remediation
+	for _, fix := range fixes {
+		if fix.Persist {
+			values[fix.Key] = fix.Value
+		}
+		if fix.Runtime {
+			runtimeFixes = append(runtimeFixes, fix)
+		}
 	}
 
-	fmt.Printf("Backup criado em %s\n", backupPath)
-
-	// Aplica as correções
-	for _, issue := range issues {
-		cmd := issue.FixCommand
-
-		// Adapta o comando para o mountPoint, se necessário
-		if a.mountPoint != "" {
-			cmd = strings.Replace(cmd, "/etc/sysctl.conf", a.configPath, -1)
-			// Remove o comando sysctl -p se estiver em um mountPoint
-			cmd = strings.Replace(cmd, "&& sysctl -p", "", -1)
+	var changes []*atomicfile.Change
+	if len(values) > 0 {
+		changes, err = a.planFiles(values)
+		if err != nil {
+			return err
 		}
-
-		// Executa o comando
-		fmt.Printf("Aplicando correção: %s\n", cmd)
-
-		// Aqui você pode implementar a execução do comando
-		// Por enquanto, apenas simula a execução
-		fmt.Printf("  [Simulando] %s\n", cmd)
 	}
 
-	return nil
-}
-
-// copyFile copia um arquivo de origem para destino
-func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
+	// Em um mountPoint o kernel em execução não é o do sistema analisado
+	var applied []runtimeChange
+	if a.mountPoint == "" && len(runtimeFixes) > 0 {
+		applied, err = applyRuntime(runtimeFixes)
+		if err != nil {
+			return fmt.Errorf("%w; valores em execução anteriores restaurados", err)
+		}
 	}
-	defer sourceFile.Close()
 
-	destFile, err := os.Create(dst)
-	if err != nil {
-		return err
+	if len(changes) > 0 {
+		if err := installFiles(changes); err != nil {
+			rollbackRuntime(applied)
+			return err
+		}
+		for _, fix := range fixes {
+			if fix.Persist {
+				fmt.Printf("Aplicada correção: %s = %s (%s)\n", fix.Key, fix.Value, dropInPath)
+			}
+		}
 	}
-	defer destFile.Close()
 
-	_, err = destFile.ReadFrom(sourceFile)
-	return err
+	return nil
 }
 
 // getDefaultRules retorna as regras padrão para verificação sysctl
@@ -267,112 +308,210 @@ func getDefaultRules() []SysctlRule {
 			RecommendedValue: "1",
 			Severity:         report.SeverityCritical,
 			Description:      "SYN flood protection deve estar habilitada",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "net.ipv4.conf.all.accept_redirects",
 			RecommendedValue: "0",
 			Severity:         report.SeverityWarning,
 			Description:      "ICMP redirects não devem ser aceitos",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "net.ipv4.conf.all.send_redirects",
 			RecommendedValue: "0",
 			Severity:         report.SeverityWarning,
 			Description:      "ICMP redirects não devem ser enviados",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "net.ipv4.conf.all.accept_source_route",
 			RecommendedValue: "0",
 			Severity:         report.SeverityCritical,
 			Description:      "Source routing deve estar desabilitado",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "net.ipv4.conf.*.accept_redirects",
+			RecommendedValue: "0",
+			Severity:         report.SeverityWarning,
+			Description:      "ICMP redirects não devem ser aceitos",
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "net.ipv4.conf.*.send_redirects",
+			RecommendedValue: "0",
+			Severity:         report.SeverityWarning,
+			Description:      "ICMP redirects não devem ser enviados",
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "net.ipv4.conf.*.accept_source_route",
+			RecommendedValue: "0",
+			Severity:         report.SeverityCritical,
+			Description:      "Source routing deve estar desabilitado",
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "net.ipv4.conf.*.rp_filter",
+			RecommendedValue: "1",
+			Severity:         report.SeverityWarning,
+			Description:      "Validação do endereço de origem (reverse path filter) deve estar habilitada",
+			Expect:           rules.OneOf("1", "2"),
+		},
+		{
+			Key:              "net.ipv6.conf.*.accept_ra",
+			RecommendedValue: "0",
+			Severity:         report.SeverityWarning,
+			Description:      "Router advertisements IPv6 não devem ser aceitos",
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "net.ipv6.conf.*.accept_redirects",
+			RecommendedValue: "0",
+			Severity:         report.SeverityWarning,
+			Description:      "ICMPv6 redirects não devem ser aceitos",
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "net.ipv6.conf.*.accept_source_route",
+			RecommendedValue: "0",
+			Severity:         report.SeverityCritical,
+			Description:      "Source routing IPv6 deve estar desabilitado",
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "net.ipv4.conf.all.log_martians",
 			RecommendedValue: "1",
 			Severity:         report.SeverityWarning,
 			Description:      "Pacotes com endereços impossíveis devem ser registrados",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "net.ipv4.icmp_echo_ignore_broadcasts",
 			RecommendedValue: "1",
 			Severity:         report.SeverityWarning,
 			Description:      "ICMP broadcasts devem ser ignorados",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "net.ipv4.icmp_ignore_bogus_error_responses",
 			RecommendedValue: "1",
 			Severity:         report.SeverityWarning,
 			Description:      "Mensagens de erro ICMP malformadas devem ser ignoradas",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "net.ipv4.tcp_rfc1337",
 			RecommendedValue: "1",
 			Severity:         report.SeverityWarning,
 			Description:      "Proteção contra TIME-WAIT assassination deve estar habilitada",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "kernel.randomize_va_space",
 			RecommendedValue: "2",
 			Severity:         report.SeverityCritical,
 			Description:      "ASLR deve estar totalmente habilitado",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "fs.protected_hardlinks",
 			RecommendedValue: "1",
 			Severity:         report.SeverityCritical,
 			Description:      "Hard links devem ser protegidos",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "fs.protected_symlinks",
 			RecommendedValue: "1",
 			Severity:         report.SeverityCritical,
 			Description:      "Symbolic links devem ser protegidos",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "kernel.kptr_restrict",
 			RecommendedValue: "1",
 			Severity:         report.SeverityWarning,
 			Description:      "Restrição de exibição de ponteiros do kernel deve estar habilitada",
-			ComparisonFunc:   func(actual, recommended string) bool {
-				// Verifica se o valor atual é pelo menos 1
-				actualInt, err := strconv.Atoi(actual)
-				if err != nil {
-					return false
-				}
-				return actualInt >= 1
-			},
+			Expect:           rules.AtLeast(""),
 		},
 		{
 			Key:              "kernel.dmesg_restrict",
 			RecommendedValue: "1",
 			Severity:         report.SeverityWarning,
 			Description:      "Acesso ao dmesg deve ser restrito",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "kernel.sysrq",
 			RecommendedValue: "0",
 			Severity:         report.SeverityWarning,
 			Description:      "SysRq deve estar desabilitado em ambientes de produção",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "kernel.yama.ptrace_scope",
+			RecommendedValue: "1",
+			Severity:         report.SeverityWarning,
+			Description:      "ptrace deve ser restrito a processos descendentes",
+			Expect:           rules.AtLeast(""),
+		},
+		{
+			Key:              "kernel.unprivileged_bpf_disabled",
+			RecommendedValue: "1",
+			Severity:         report.SeverityWarning,
+			Description:      "Programas BPF não devem ser carregados por usuários sem privilégio",
+			Expect:           rules.OneOf("1", "2"),
+		},
+		{
+			Key:              "net.core.bpf_jit_harden",
+			RecommendedValue: "2",
+			Severity:         report.SeverityWarning,
+			Description:      "O endurecimento do compilador JIT de BPF deve estar habilitado para todos os usuários",
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "kernel.perf_event_paranoid",
+			RecommendedValue: "2",
+			Severity:         report.SeverityWarning,
+			Description:      "Eventos de desempenho do kernel devem ser restritos a usuários privilegiados",
+			Expect:           rules.AtLeast(""),
+		},
+		{
+			Key:              "fs.suid_dumpable",
+			RecommendedValue: "0",
+			Severity:         report.SeverityWarning,
+			Description:      "Core dumps de programas setuid devem estar desabilitados",
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "fs.protected_fifos",
+			RecommendedValue: "2",
+			Severity:         report.SeverityWarning,
+			Description:      "FIFOs em diretórios com sticky bit devem ser protegidos",
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "fs.protected_regular",
+			RecommendedValue: "2",
+			Severity:         report.SeverityWarning,
+			Description:      "Arquivos regulares em diretórios com sticky bit devem ser protegidos",
+			Expect:           rules.Equal(),
+		},
+		{
+			Key:              "kernel.kexec_load_disabled",
+			RecommendedValue: "1",
+			Severity:         report.SeverityInfo,
+			Description:      "O carregamento de um novo kernel com kexec deve estar desabilitado",
+			Expect:           rules.Equal(),
 		},
 		{
 			Key:              "kernel.core_uses_pid",
 			RecommendedValue: "1",
 			Severity:         report.SeverityInfo,
 			Description:      "Core dumps devem incluir o PID no nome do arquivo",
-			ComparisonFunc:   func(actual, recommended string) bool { return actual == recommended },
+			Expect:           rules.Equal(),
 		},
 	}
 }
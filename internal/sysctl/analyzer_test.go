@@ -0,0 +1,44 @@
+package sysctl
+
+import (
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+	"github.com/mairinkdev/Hardshell/internal/rules"
+)
+
+func TestCheckRuleRuntimeDrift(t *testing.T) {
+	// kernel.ostype existe em qualquer kernel Linux e vale "Linux", fora dos valores aceitos
+	runtime, live := readRuntime("kernel.ostype")
+	if !live {
+		t.Skip("/proc/sys indisponível")
+	}
+
+	rule := SysctlRule{
+		Key:              "kernel.ostype",
+		RecommendedValue: "Hardened",
+		Severity:         report.SeverityWarning,
+		Description:      "teste",
+		Expect:           rules.OneOf("Hardened", "Custom"),
+	}
+	config := &persistedConfig{
+		settings: map[string]setting{
+			"kernel.ostype": {Key: "kernel.ostype", Value: "Custom", File: "/etc/sysctl.d/10-test.conf", Line: 1},
+		},
+		excluded: make(map[string]bool),
+	}
+
+	a := &Analyzer{}
+	issue, fix, found := a.checkRule(rule, config)
+	if !found {
+		t.Fatalf("o valor em execução %q deveria ser reportado", runtime)
+	}
+
+	// O valor persistido é aceito e é ele que volta a valer, no comando e na correção aplicada
+	if fix.Value != "Custom" || !fix.Runtime || fix.Persist {
+		t.Errorf("correção = %+v, esperado Custom apenas em execução", fix)
+	}
+	if issue.FixCommand != "sysctl -w kernel.ostype=Custom" {
+		t.Errorf("FixCommand = %q, esperado o valor persistido", issue.FixCommand)
+	}
+}
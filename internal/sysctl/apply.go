@@ -0,0 +1,229 @@
+package sysctl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/atomicfile"
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// dropInPath é o arquivo gerenciado pelo Hardshell com os valores corrigidos
+const dropInPath = "/etc/sysctl.d/99-hardshell.conf"
+
+// remediation é a correção de um parâmetro: gravar o valor no drop-in e/ou aplicá-lo em /proc/sys
+type remediation struct {
+	Key     string
+	Value   string
+	Persist bool
+	Runtime bool
+}
+
+// runtimeChange é um valor gravado em /proc/sys, guardado para ser revertido
+type runtimeChange struct {
+	Key string
+	Old string
+}
+
+// appliedAfterDropIn verifica se um arquivo é lido depois do drop-in e, portanto, prevalece sobre ele
+func appliedAfterDropIn(path string) bool {
+	return path == sysctlConfPath || filepath.Base(path) > filepath.Base(dropInPath)
+}
+
+// dropInCommand gera o comando equivalente à gravação de um valor no drop-in.
+// Uma definição em /etc lida depois do drop-in é comentada, pois prevaleceria sobre ele.
+func dropInCommand(key, value string, current setting, exists bool) string {
+	cmd := fmt.Sprintf("mkdir -p %s && touch %s && sed -i '\\|^%s[[:space:]]*=|d' %s && echo '%s = %s' >> %s",
+		filepath.Dir(dropInPath), dropInPath, formatKey(key), dropInPath, formatKey(key), value, dropInPath)
+
+	if exists && current.Key == key && current.File != dropInPath && strings.HasPrefix(current.File, "/etc/") && appliedAfterDropIn(current.File) {
+		cmd = fmt.Sprintf("sed -i --follow-symlinks '%ds/^/#/' %s && %s", current.Line, current.File, cmd)
+	}
+	return cmd
+}
+
+// planFiles calcula o novo conteúdo do drop-in e dos arquivos de /etc que o sobrescreveriam
+func (a *Analyzer) planFiles(values map[string]string) ([]*atomicfile.Change, error) {
+	dropIn, err := atomicfile.Load(util.JoinMount(a.mountPoint, dropInPath))
+	if err != nil {
+		return nil, err
+	}
+
+	// Mantém os valores já gerenciados e atualiza os corrigidos agora
+	managed := make(map[string]string)
+	if dropIn.Existed {
+		existing := &persistedConfig{settings: make(map[string]setting), excluded: make(map[string]bool)}
+		if err := readConfigFile(a.mountPoint, dropInPath, existing); err != nil {
+			return nil, err
+		}
+		for key, current := range existing.settings {
+			managed[key] = current.Value
+		}
+	}
+	for key, value := range values {
+		managed[key] = value
+	}
+	dropIn.New = renderDropIn(managed)
+
+	changes := []*atomicfile.Change{dropIn}
+
+	for _, path := range configFiles(a.mountPoint) {
+		if path == dropInPath || !appliedAfterDropIn(path) {
+			continue
+		}
+
+		if !strings.HasPrefix(path, "/etc/") {
+			// Arquivos de pacotes não são editados; o drop-in não consegue sobrescrevê-los
+			if keys := definedKeys(a.mountPoint, path, values); len(keys) > 0 {
+				fmt.Printf("Atenção: %s é lido depois de %s e redefine %s\n", path, dropInPath, strings.Join(keys, ", "))
+			}
+			continue
+		}
+
+		// Links (ex: 99-sysctl.conf -> ../sysctl.conf) são preservados, e o arquivo de destino é alterado
+		change, err := atomicfile.Load(resolve(a.mountPoint, path))
+		if err != nil {
+			return nil, err
+		}
+		content, count := commentOutKeys(change.Old, values)
+		if count == 0 {
+			continue
+		}
+		change.New = content
+		changes = append(changes, change)
+		fmt.Printf("Comentadas %d definições em %s, lido depois de %s\n", count, path, dropInPath)
+	}
+
+	return changes, nil
+}
+
+// renderDropIn gera o conteúdo do drop-in com as chaves ordenadas, para que o arquivo
+// seja o mesmo a cada execução com os mesmos valores
+func renderDropIn(values map[string]string) []byte {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(atomicfile.Header)
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf("%s = %s\n", formatKey(key), values[key]))
+	}
+	return []byte(sb.String())
+}
+
+// formatKey retorna a chave na forma gravada nos arquivos. Chaves com pontos nos nomes
+// (ex: net.ipv4.conf.eth0/100.rp_filter) são gravadas na forma com barras.
+func formatKey(key string) string {
+	if strings.Contains(key, "/") {
+		return keyToPath(key)
+	}
+	return key
+}
+
+// commentOutKeys comenta as definições explícitas das chaves informadas
+func commentOutKeys(data []byte, values map[string]string) ([]byte, int) {
+	lines := strings.Split(string(data), "\n")
+	count := 0
+
+	for i, line := range lines {
+		if key, ok := lineKey(line); ok {
+			if _, fixed := values[key]; fixed {
+				lines[i] = "#" + line
+				count++
+			}
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n")), count
+}
+
+// definedKeys retorna as chaves informadas que são definidas explicitamente em um arquivo
+func definedKeys(mountPoint, path string, values map[string]string) []string {
+	data, err := os.ReadFile(util.JoinMount(mountPoint, path))
+	if err != nil {
+		return nil
+	}
+
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, ok := lineKey(line); ok {
+			if _, fixed := values[key]; fixed {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// lineKey retorna a chave definida em uma linha de configuração, ignorando comentários
+func lineKey(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+		return "", false
+	}
+
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return "", false
+	}
+	return normalizeKey(strings.TrimPrefix(strings.TrimSpace(parts[0]), "-")), true
+}
+
+// applyRuntime grava os valores em /proc/sys e confere o valor lido de volta.
+// Em caso de falha, os valores já alterados são revertidos.
+func applyRuntime(fixes []remediation) ([]runtimeChange, error) {
+	var applied []runtimeChange
+
+	for _, fix := range fixes {
+		old, ok := readRuntime(fix.Key)
+		if !ok {
+			rollbackRuntime(applied)
+			return nil, fmt.Errorf("erro ao ler %s em %s", fix.Key, procSysPath)
+		}
+		if old == normalizeValue(fix.Value) {
+			continue
+		}
+
+		if err := writeRuntime(fix.Key, fix.Value); err != nil {
+			rollbackRuntime(applied)
+			return nil, fmt.Errorf("erro ao aplicar %s=%s: %w", fix.Key, fix.Value, err)
+		}
+		applied = append(applied, runtimeChange{Key: fix.Key, Old: old})
+
+		// O kernel pode aceitar a escrita e manter outro valor (ex: parâmetros que só podem aumentar)
+		if current, _ := readRuntime(fix.Key); current != normalizeValue(fix.Value) {
+			rollbackRuntime(applied)
+			return nil, fmt.Errorf("o kernel não aceitou %s=%s (valor lido: %s)", fix.Key, fix.Value, current)
+		}
+		fmt.Printf("Aplicado em execução: %s = %s\n", fix.Key, fix.Value)
+	}
+
+	return applied, nil
+}
+
+// rollbackRuntime devolve os valores anteriores em /proc/sys, na ordem inversa
+func rollbackRuntime(applied []runtimeChange) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		change := applied[i]
+		if err := writeRuntime(change.Key, change.Old); err != nil {
+			fmt.Printf("Erro ao restaurar %s=%s: %v\n", change.Key, change.Old, err)
+			continue
+		}
+		fmt.Printf("Restaurado em execução: %s = %s\n", change.Key, change.Old)
+	}
+}
+
+// installFiles grava os arquivos candidatos e os substitui, com backup dos arquivos
+// existentes. Em caso de falha, os arquivos já substituídos voltam ao conteúdo anterior.
+func installFiles(changes []*atomicfile.Change) error {
+	if err := atomicfile.Write(changes); err != nil {
+		return err
+	}
+	return atomicfile.Install(changes, true)
+}
@@ -0,0 +1,70 @@
+package sysctl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEphemeralInterface(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"net.ipv4.conf.veth1a2b3c.rp_filter", true},
+		{"net.ipv4.conf.docker0.accept_redirects", true},
+		{"net.ipv6.conf.br-5f2c9e.accept_ra", true},
+		{"net.ipv4.neigh.vethab12.gc_stale_time", true},
+		{"net.ipv4.conf.eth0.rp_filter", false},
+		{"net.ipv4.conf.all.rp_filter", false},
+		{"net.ipv4.conf.default.rp_filter", false},
+		{"net.ipv4.tcp_syncookies", false},
+	}
+
+	for _, tt := range tests {
+		if got := ephemeralInterface(tt.key); got != tt.want {
+			t.Errorf("ephemeralInterface(%q) = %v, esperado %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestFixSkipsEphemeralInterfaces(t *testing.T) {
+	root := t.TempDir()
+	conf := filepath.Join(root, "etc/sysctl.d/10-network.conf")
+	if err := os.MkdirAll(filepath.Dir(conf), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "net.ipv4.conf.veth1a2b3c.rp_filter = 0\nnet.ipv4.conf.eth0.rp_filter = 0\n"
+	if err := os.WriteFile(conf, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewAnalyzer(root)
+	issues, err := a.Analyze()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, issue := range issues {
+		if strings.Contains(issue.Key, "veth") {
+			t.Errorf("interface efêmera reportada: %+v", issue)
+		}
+	}
+
+	if err := a.Fix(); err != nil {
+		t.Fatalf("Fix: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, dropInPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "net.ipv4.conf.eth0.rp_filter") {
+		t.Errorf("o drop-in não corrige eth0:\n%s", data)
+	}
+	if strings.Contains(string(data), "veth") {
+		t.Errorf("o drop-in contém chaves de interfaces efêmeras:\n%s", data)
+	}
+	if candidates, _ := filepath.Glob(filepath.Join(root, "etc/sysctl.d/*.hardshell-new")); len(candidates) > 0 {
+		t.Errorf("cópias candidatas restantes: %v", candidates)
+	}
+}
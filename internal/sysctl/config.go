@@ -0,0 +1,232 @@
+package sysctl
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// sysctlDirs são os diretórios lidos pelo systemd-sysctl, do mais para o menos prioritário.
+// Um arquivo com o mesmo nome em um diretório mais prioritário substitui os demais.
+var sysctlDirs = []string{
+	"/etc/sysctl.d",
+	"/run/sysctl.d",
+	"/usr/local/lib/sysctl.d",
+	"/usr/lib/sysctl.d",
+	"/lib/sysctl.d",
+}
+
+// sysctlConfPath é o arquivo tradicional, lido depois de todos os arquivos de sysctl.d
+const sysctlConfPath = "/etc/sysctl.conf"
+
+// setting é o valor de um parâmetro e o local em que foi definido
+type setting struct {
+	// Key é a chave como escrita na configuração, que pode ser um padrão (ex: net.ipv4.conf.*.rp_filter)
+	Key   string
+	Value string
+	File  string
+	Line  int
+}
+
+// Source retorna o arquivo e a linha que definem o valor (ex: /etc/sysctl.d/10-net.conf:3)
+func (s setting) Source() string {
+	return fmt.Sprintf("%s:%d", s.File, s.Line)
+}
+
+// configFiles retorna os arquivos de configuração na ordem em que são aplicados.
+// Os arquivos de sysctl.d são ordenados pelo nome, independente do diretório; arquivos
+// mascarados (link para /dev/null ou vazios em um diretório prioritário) são ignorados.
+func configFiles(mountPoint string) []string {
+	chosen := make(map[string]string)
+	var names []string
+
+	for _, dir := range sysctlDirs {
+		entries, err := os.ReadDir(util.JoinMount(mountPoint, dir))
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasSuffix(name, ".conf") || entry.IsDir() {
+				continue
+			}
+			if _, exists := chosen[name]; exists {
+				continue
+			}
+			chosen[name] = filepath.Join(dir, name)
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	var files []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		path := chosen[name]
+		if isMasked(mountPoint, path) {
+			continue
+		}
+		files = append(files, path)
+		seen[resolve(mountPoint, path)] = true
+	}
+
+	// Muitas distribuições já incluem o sysctl.conf por um link (ex: /etc/sysctl.d/99-sysctl.conf)
+	if _, err := os.Stat(util.JoinMount(mountPoint, sysctlConfPath)); err == nil && !seen[resolve(mountPoint, sysctlConfPath)] {
+		files = append(files, sysctlConfPath)
+	}
+
+	return files
+}
+
+// isMasked verifica se um arquivo de sysctl.d foi desabilitado com um link para /dev/null
+func isMasked(mountPoint, path string) bool {
+	target, err := os.Readlink(util.JoinMount(mountPoint, path))
+	return err == nil && target == "/dev/null"
+}
+
+// resolve retorna o caminho real de um arquivo, seguindo links dentro do mountPoint
+func resolve(mountPoint, path string) string {
+	resolved, err := filepath.EvalSymlinks(util.JoinMount(mountPoint, path))
+	if err != nil {
+		return util.JoinMount(mountPoint, path)
+	}
+	return resolved
+}
+
+// persistedConfig guarda a configuração persistente lida dos arquivos
+type persistedConfig struct {
+	// settings são os valores definidos para chaves explícitas
+	settings map[string]setting
+
+	// globs são os valores definidos com padrões, na ordem de leitura
+	globs []setting
+
+	// excluded são as chaves excluídas dos padrões com uma linha "-chave" sem valor
+	excluded map[string]bool
+}
+
+// Get retorna o valor persistido de uma chave. Como no systemd-sysctl, uma definição
+// explícita prevalece sobre os padrões, e entre os padrões vale o último lido.
+func (c *persistedConfig) Get(key string) (setting, bool) {
+	if current, ok := c.settings[key]; ok {
+		return current, true
+	}
+	if c.excluded[key] {
+		return setting{}, false
+	}
+	for i := len(c.globs) - 1; i >= 0; i-- {
+		if matchKey(c.globs[i].Key, key) {
+			return c.globs[i], true
+		}
+	}
+	return setting{}, false
+}
+
+// Keys retorna as chaves da configuração que correspondem a um padrão, incluindo
+// os padrões configurados que são casos particulares dele
+func (c *persistedConfig) Keys(pattern string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for key := range c.settings {
+		if matchKey(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	for _, glob := range c.globs {
+		if matchKey(pattern, glob.Key) && !seen[glob.Key] {
+			seen[glob.Key] = true
+			keys = append(keys, glob.Key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// readPersisted lê a configuração persistente na ordem do systemd-sysctl.
+// Como no systemd-sysctl, o último valor lido para cada parâmetro é o que vale.
+func readPersisted(mountPoint string) (*persistedConfig, error) {
+	config := &persistedConfig{
+		settings: make(map[string]setting),
+		excluded: make(map[string]bool),
+	}
+
+	for _, path := range configFiles(mountPoint) {
+		if err := readConfigFile(mountPoint, path, config); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// readConfigFile lê um arquivo no formato do sysctl.conf
+func readConfigFile(mountPoint, path string, config *persistedConfig) error {
+	configFile, err := os.Open(util.JoinMount(mountPoint, path))
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de configuração sysctl: %w", err)
+	}
+	defer configFile.Close()
+
+	scanner := bufio.NewScanner(configFile)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Ignora comentários e linhas em branco
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		// Separa a chave e o valor
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			// "-chave" sem valor exclui a chave dos padrões definidos nos arquivos
+			if strings.HasPrefix(line, "-") {
+				config.excluded[normalizeKey(strings.TrimSpace(line[1:]))] = true
+			}
+			continue
+		}
+
+		// O prefixo "-" apenas indica que erros ao aplicar o valor devem ser ignorados
+		key := normalizeKey(strings.TrimPrefix(strings.TrimSpace(parts[0]), "-"))
+		current := setting{Key: key, Value: normalizeValue(parts[1]), File: path, Line: lineNumber}
+
+		if isPattern(key) {
+			config.globs = append(config.globs, current)
+		} else {
+			config.settings[key] = current
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("erro ao ler arquivo de configuração sysctl: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeKey converte chaves separadas por "/" para a forma com pontos.
+// Nesse formato os pontos fazem parte dos nomes (ex: net/ipv4/conf/eth0.100/rp_filter
+// vira net.ipv4.conf.eth0/100.rp_filter), como no sysctl.
+func normalizeKey(key string) string {
+	if !strings.Contains(key, "/") {
+		return key
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/':
+			return '.'
+		case '.':
+			return '/'
+		}
+		return r
+	}, strings.Trim(key, "/"))
+}
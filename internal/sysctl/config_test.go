@@ -0,0 +1,123 @@
+package sysctl
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeTree grava os arquivos informados em um mountPoint temporário. Conteúdos iniciados
+// por "->" criam um link simbólico para o destino informado.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+	for path, content := range files {
+		file := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if len(content) > 2 && content[:2] == "->" {
+			if err := os.Symlink(content[2:], file); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestConfigFiles(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/usr/lib/sysctl.d/10-default.conf": "kernel.kptr_restrict = 1\n",
+		"/usr/lib/sysctl.d/50-vendor.conf":  "net.ipv4.ip_forward = 1\n",
+		"/etc/sysctl.d/50-vendor.conf":      "net.ipv4.ip_forward = 0\n",
+		"/lib/sysctl.d/60-masked.conf":      "kernel.sysrq = 1\n",
+		"/etc/sysctl.d/60-masked.conf":      "->/dev/null",
+		"/run/sysctl.d/30-runtime.conf":     "vm.swappiness = 10\n",
+		"/etc/sysctl.d/README":              "ignorado\n",
+		"/etc/sysctl.conf":                  "fs.suid_dumpable = 0\n",
+		"/etc/sysctl.d/99-sysctl.conf":      "->../sysctl.conf",
+	})
+
+	// O sysctl.conf já é lido pelo link 99-sysctl.conf e não é repetido no fim
+	want := []string{
+		"/usr/lib/sysctl.d/10-default.conf",
+		"/run/sysctl.d/30-runtime.conf",
+		"/etc/sysctl.d/50-vendor.conf",
+		"/etc/sysctl.d/99-sysctl.conf",
+	}
+	if got := configFiles(root); !reflect.DeepEqual(got, want) {
+		t.Errorf("configFiles() = %v, esperado %v", got, want)
+	}
+}
+
+func TestConfigFilesSysctlConfLast(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/sysctl.d/99-local.conf": "net.ipv4.ip_forward = 1\n",
+		"/etc/sysctl.conf":            "net.ipv4.ip_forward = 0\n",
+	})
+
+	want := []string{"/etc/sysctl.d/99-local.conf", sysctlConfPath}
+	if got := configFiles(root); !reflect.DeepEqual(got, want) {
+		t.Errorf("configFiles() = %v, esperado %v", got, want)
+	}
+}
+
+func TestPersistedGet(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/usr/lib/sysctl.d/40-rp.conf": "net.ipv4.conf.*.rp_filter = 2\n" +
+			"-net.ipv4.conf.lo.rp_filter\n",
+		"/usr/lib/sysctl.d/50-default.conf": "net.ipv4.ip_forward = 1\nkernel.kptr_restrict = 0\n",
+		"/etc/sysctl.d/50-default.conf":     "net.ipv4.ip_forward = 0\n",
+		"/etc/sysctl.d/60-rp.conf": "net.ipv4.conf.eth*.rp_filter = 0\n" +
+			"net.ipv4.conf.eth1.rp_filter = 1\n",
+		"/etc/sysctl.d/70-masked.conf":     "->/dev/null",
+		"/usr/lib/sysctl.d/70-masked.conf": "kernel.sysrq = 1\n",
+		"/etc/sysctl.d/80-slash.conf":      "net/ipv4/conf/eth0.100/accept_redirects = 0\n",
+		"/etc/sysctl.conf":                 "; comentário\n-net.ipv4.tcp_syncookies = 1\n",
+	})
+
+	config, err := readPersisted(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		source string
+		found  bool
+	}{
+		// O arquivo de /etc substitui o de mesmo nome em /usr/lib
+		{"net.ipv4.ip_forward", "0", "/etc/sysctl.d/50-default.conf:1", true},
+		{"kernel.kptr_restrict", "", "", false},
+		// Entre os padrões vale o último lido
+		{"net.ipv4.conf.eth0.rp_filter", "0", "/etc/sysctl.d/60-rp.conf:1", true},
+		{"net.ipv4.conf.all.rp_filter", "2", "/usr/lib/sysctl.d/40-rp.conf:1", true},
+		// A chave explícita prevalece sobre os padrões
+		{"net.ipv4.conf.eth1.rp_filter", "1", "/etc/sysctl.d/60-rp.conf:2", true},
+		// "-chave" exclui a chave dos padrões
+		{"net.ipv4.conf.lo.rp_filter", "", "", false},
+		// Arquivos mascarados não são lidos
+		{"kernel.sysrq", "", "", false},
+		{"net.ipv4.conf.eth0/100.accept_redirects", "0", "/etc/sysctl.d/80-slash.conf:1", true},
+		// O prefixo "-" com valor apenas ignora erros ao aplicar
+		{"net.ipv4.tcp_syncookies", "1", "/etc/sysctl.conf:2", true},
+	}
+
+	for _, tt := range tests {
+		current, found := config.Get(tt.key)
+		if found != tt.found {
+			t.Errorf("Get(%q) encontrado = %v, esperado %v", tt.key, found, tt.found)
+			continue
+		}
+		if found && (current.Value != tt.value || current.Source() != tt.source) {
+			t.Errorf("Get(%q) = %s em %s, esperado %s em %s", tt.key, current.Value, current.Source(), tt.value, tt.source)
+		}
+	}
+}
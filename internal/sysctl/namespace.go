@@ -0,0 +1,96 @@
+package sysctl
+
+import (
+	"os"
+	"strings"
+
+	"github.com/mairinkdev/Hardshell/internal/util"
+)
+
+// Escopos dos parâmetros sysctl em relação aos namespaces do kernel
+const (
+	// scopeNetwork indica um parâmetro do namespace de rede, que cada contêiner pode ter próprio
+	scopeNetwork = "net"
+
+	// scopeIPC indica um parâmetro do namespace IPC
+	scopeIPC = "ipc"
+
+	// scopeHost indica um parâmetro global do kernel, que só pode ser alterado no host
+	scopeHost = "host"
+)
+
+// hostNetworkPrefixes são parâmetros em net.* que não pertencem ao namespace de rede
+var hostNetworkPrefixes = []string{
+	"net.core.bpf_jit_",
+	"net.core.netdev_",
+	"net.core.rmem_",
+	"net.core.wmem_",
+	"net.core.optmem_max",
+	"net.core.message_",
+	"net.unix.",
+}
+
+// ipcPrefixes são os parâmetros do namespace IPC
+var ipcPrefixes = []string{
+	"kernel.shm",
+	"kernel.msg",
+	"kernel.sem",
+	"fs.mqueue.",
+}
+
+// keyScope classifica um parâmetro pelo namespace a que pertence
+func keyScope(key string) string {
+	for _, prefix := range hostNetworkPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return scopeHost
+		}
+	}
+	if strings.HasPrefix(key, "net.") {
+		return scopeNetwork
+	}
+	for _, prefix := range ipcPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return scopeIPC
+		}
+	}
+	return scopeHost
+}
+
+// containerMarkers são arquivos criados pelos motores de contêiner na raiz do sistema de arquivos
+var containerMarkers = []string{
+	"/.dockerenv",
+	"/run/.containerenv",
+}
+
+// cgroupMarkers identificam processos de contêiner em /proc/1/cgroup
+var cgroupMarkers = []string{"docker", "kubepods", "containerd", "lxc", "libpod"}
+
+// detectContainer verifica se o sistema analisado é um contêiner. Em um mountPoint apenas
+// os arquivos do sistema de arquivos são considerados; no sistema em execução, também o
+// ambiente e os cgroups do processo 1.
+func detectContainer(mountPoint string) bool {
+	for _, marker := range containerMarkers {
+		if _, err := os.Stat(util.JoinMount(mountPoint, marker)); err == nil {
+			return true
+		}
+	}
+
+	if mountPoint != "" {
+		return false
+	}
+
+	if os.Getenv("container") != "" {
+		return true
+	}
+
+	data, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return false
+	}
+	for _, marker := range cgroupMarkers {
+		if strings.Contains(string(data), marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package sysctl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+func TestKeyScope(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"net.ipv4.tcp_syncookies", scopeNetwork},
+		{"net.ipv4.conf.all.rp_filter", scopeNetwork},
+		{"net.ipv6.conf.default.accept_ra", scopeNetwork},
+		{"net.core.bpf_jit_harden", scopeHost},
+		{"net.core.rmem_max", scopeHost},
+		{"net.unix.max_dgram_qlen", scopeHost},
+		{"net.core.somaxconn", scopeNetwork},
+		{"kernel.shmmax", scopeIPC},
+		{"kernel.msgmnb", scopeIPC},
+		{"kernel.sem", scopeIPC},
+		{"fs.mqueue.msg_max", scopeIPC},
+		{"kernel.kptr_restrict", scopeHost},
+		{"fs.protected_symlinks", scopeHost},
+		{"vm.mmap_min_addr", scopeHost},
+	}
+
+	for _, tt := range tests {
+		if got := keyScope(tt.key); got != tt.want {
+			t.Errorf("keyScope(%q) = %q, esperado %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestDetectContainer(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{"docker", map[string]string{"/.dockerenv": ""}, true},
+		{"podman", map[string]string{"/run/.containerenv": ""}, true},
+		{"host", map[string]string{"/etc/hostname": "server\n"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := detectContainer(writeTree(t, tt.files)); got != tt.want {
+			t.Errorf("%s: detectContainer() = %v, esperado %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestContainerSkipsHostKeys(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/.dockerenv": "",
+		"/etc/sysctl.d/10-test.conf": "kernel.kptr_restrict = 0\n" +
+			"net.ipv4.tcp_syncookies = 0\n",
+	})
+
+	a := NewAnalyzer(root)
+	if !a.InContainer() {
+		t.Fatal("contêiner não detectado")
+	}
+	a.rules = []SysctlRule{
+		{Key: "kernel.kptr_restrict", RecommendedValue: "1", Severity: report.SeverityWarning, Description: "kptr"},
+		{Key: "net.ipv4.tcp_syncookies", RecommendedValue: "1", Severity: report.SeverityWarning, Description: "syncookies"},
+	}
+
+	issues, fixes, err := a.evaluate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("problemas = %+v, esperado 2", issues)
+	}
+	if issues[0].Severity != report.SeveritySkip || !strings.Contains(issues[0].Description, "host") {
+		t.Errorf("parâmetro global = %+v, esperado SKIP", issues[0])
+	}
+	if issues[1].Severity != report.SeverityWarning {
+		t.Errorf("parâmetro de rede = %+v, esperado WARNING", issues[1])
+	}
+
+	// Apenas o parâmetro do namespace de rede é corrigido
+	if len(fixes) != 1 || fixes[0].Key != "net.ipv4.tcp_syncookies" {
+		t.Errorf("correções = %+v, esperado apenas net.ipv4.tcp_syncookies", fixes)
+	}
+}
@@ -0,0 +1,53 @@
+package sysctl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// procSysPath é onde o kernel expõe os valores sysctl em execução
+const procSysPath = "/proc/sys"
+
+// readRuntime lê o valor em execução de um parâmetro em /proc/sys
+func readRuntime(key string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(procSysPath, keyToPath(key)))
+	if err != nil {
+		return "", false
+	}
+	return normalizeValue(string(data)), true
+}
+
+// writeRuntime grava um valor em /proc/sys, aplicando-o ao kernel em execução
+func writeRuntime(key, value string) error {
+	return os.WriteFile(filepath.Join(procSysPath, keyToPath(key)), []byte(value+"\n"), 0644)
+}
+
+// missingFromKernel verifica se um parâmetro não existe no kernel em execução
+// (ex: módulo não carregado ou recurso não compilado). Sem /proc/sys montado nada é afirmado.
+func missingFromKernel(key string) bool {
+	if _, err := os.Stat(filepath.Join(procSysPath, "kernel")); err != nil {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(procSysPath, keyToPath(key)))
+	return os.IsNotExist(err)
+}
+
+// keyToPath converte uma chave sysctl no caminho relativo a /proc/sys.
+// Barras na chave representam pontos no nome (ex: net.ipv4.conf.eth0/100.rp_filter).
+func keyToPath(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.':
+			return '/'
+		case '/':
+			return '.'
+		}
+		return r
+	}, key)
+}
+
+// normalizeValue padroniza os espaços de valores com vários campos (ex: "4\t4\t1\t7")
+func normalizeValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
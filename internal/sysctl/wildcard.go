@@ -0,0 +1,101 @@
+package sysctl
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ephemeralPrefixes são os prefixos de interfaces criadas e removidas em execução (pares veth
+// e bridges de contêineres, interfaces tap de máquinas virtuais)
+var ephemeralPrefixes = []string{"veth", "docker", "br-", "cni", "cali", "flannel", "vnet", "tap", "lxc"}
+
+// ephemeralInterface verifica se uma chave é de uma interface efêmera
+// (ex: net.ipv4.conf.veth1a2b3c.rp_filter). Essas interfaces recebem os valores de
+// net.*.conf.default ao serem criadas e podem não existir quando o systemd-sysctl é executado.
+func ephemeralInterface(key string) bool {
+	parts := strings.Split(key, ".")
+	if len(parts) < 5 || parts[0] != "net" || (parts[2] != "conf" && parts[2] != "neigh") {
+		return false
+	}
+	for _, prefix := range ephemeralPrefixes {
+		if strings.HasPrefix(parts[3], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPattern verifica se uma chave contém curingas (ex: net.ipv4.conf.*.rp_filter)
+func isPattern(key string) bool {
+	return strings.ContainsAny(key, "*?[")
+}
+
+// matchKey verifica se uma chave corresponde a um padrão.
+// Os curingas valem para um único componente da chave, como nos caminhos em /proc/sys.
+func matchKey(pattern, key string) bool {
+	matched, err := path.Match(keyToPath(pattern), keyToPath(key))
+	return err == nil && matched
+}
+
+// runtimeKeys retorna as chaves existentes em /proc/sys que correspondem a um padrão
+func runtimeKeys(pattern string) []string {
+	matches, err := filepath.Glob(filepath.Join(procSysPath, keyToPath(pattern)))
+	if err != nil {
+		return nil
+	}
+
+	var keys []string
+	for _, match := range matches {
+		if rel, err := filepath.Rel(procSysPath, match); err == nil {
+			keys = append(keys, keyToPath(rel))
+		}
+	}
+	return keys
+}
+
+// expandRule gera uma regra para cada chave que corresponde ao padrão da regra.
+// No sistema em execução as chaves vêm de /proc/sys; em um mountPoint, apenas as chaves
+// configuradas são conhecidas. Chaves com regra explícita própria não são repetidas.
+func (a *Analyzer) expandRule(rule SysctlRule, config *persistedConfig) []SysctlRule {
+	explicit := make(map[string]bool)
+	for _, other := range a.rules {
+		if !isPattern(other.Key) {
+			explicit[other.Key] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(candidates []string) {
+		for _, key := range candidates {
+			if !seen[key] && !explicit[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	if a.mountPoint == "" {
+		add(runtimeKeys(rule.Key))
+		for _, key := range config.Keys(rule.Key) {
+			if !isPattern(key) {
+				add([]string{key})
+			}
+		}
+	} else {
+		// Sem /proc/sys, um padrão configurado com valor inseguro é avaliado como uma chave
+		add(config.Keys(rule.Key))
+	}
+	sort.Strings(keys)
+
+	var expanded []SysctlRule
+	for _, key := range keys {
+		concrete := rule
+		concrete.Key = key
+		concrete.Description = rule.Description + " (" + key + ")"
+		expanded = append(expanded, concrete)
+	}
+	return expanded
+}
@@ -0,0 +1,71 @@
+package sysctl
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mairinkdev/Hardshell/internal/report"
+)
+
+func TestMatchKey(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"net.ipv4.conf.*.rp_filter", "net.ipv4.conf.eth0.rp_filter", true},
+		{"net.ipv4.conf.*.rp_filter", "net.ipv4.conf.eth0/100.rp_filter", true},
+		{"net.ipv4.conf.eth?.rp_filter", "net.ipv4.conf.eth1.rp_filter", true},
+		{"net.ipv4.conf.*.rp_filter", "net.ipv4.conf.eth0.accept_redirects", false},
+		// O curinga não atravessa componentes da chave
+		{"net.ipv4.*", "net.ipv4.conf.eth0.rp_filter", false},
+		{"net.ipv4.conf.*.rp_filter", "net.ipv4.conf.eth*.rp_filter", true},
+	}
+
+	for _, tt := range tests {
+		if got := matchKey(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("matchKey(%q, %q) = %v, esperado %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestExpandRule(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"/etc/sysctl.d/10-net.conf": "net.ipv4.conf.all.rp_filter = 1\n" +
+			"net.ipv4.conf.eth0.rp_filter = 0\n" +
+			"net.ipv4.conf.eth*.rp_filter = 0\n" +
+			"net.ipv4.conf.wlan0.rp_filter = 1\n" +
+			"net.ipv4.conf.eth0.accept_redirects = 1\n",
+	})
+	config, err := readPersisted(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pattern := SysctlRule{
+		Key:              "net.ipv4.conf.*.rp_filter",
+		RecommendedValue: "1",
+		Severity:         report.SeverityWarning,
+		Description:      "Filtro de caminho reverso",
+	}
+	a := &Analyzer{
+		mountPoint: root,
+		rules: []SysctlRule{
+			{Key: "net.ipv4.conf.all.rp_filter", RecommendedValue: "1"},
+			pattern,
+		},
+	}
+
+	// A chave com regra explícita própria não é repetida; o padrão configurado é avaliado como uma chave
+	var keys []string
+	for _, rule := range a.expandRule(pattern, config) {
+		keys = append(keys, rule.Key)
+		if rule.RecommendedValue != "1" || rule.Description != pattern.Description+" ("+rule.Key+")" {
+			t.Errorf("regra expandida = %+v", rule)
+		}
+	}
+	want := []string{"net.ipv4.conf.eth*.rp_filter", "net.ipv4.conf.eth0.rp_filter", "net.ipv4.conf.wlan0.rp_filter"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("chaves = %v, esperado %v", keys, want)
+	}
+}
@@ -0,0 +1,280 @@
+package systemd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	// busName é o nome do gerenciador do systemd no D-Bus
+	busName = "org.freedesktop.systemd1"
+
+	// managerPath é o objeto do gerenciador
+	managerPath = dbus.ObjectPath("/org/freedesktop/systemd1")
+
+	// managerInterface é a interface com os métodos de gerenciamento de units
+	managerInterface = "org.freedesktop.systemd1.Manager"
+
+	// unitInterface é a interface com as propriedades comuns a todas as units
+	unitInterface = "org.freedesktop.systemd1.Unit"
+
+	// socketInterface é a interface com as propriedades das units .socket
+	socketInterface = "org.freedesktop.systemd1.Socket"
+
+	// privateAddress é o socket privado do systemd, usado pelo systemctl quando o
+	// barramento do sistema não está disponível (ex: durante o boot); exige root
+	privateAddress = "unix:path=/run/systemd/private"
+
+	// AddressEnv permite apontar o cliente para outro endereço D-Bus, como um
+	// dbus-daemon local com um serviço que simula o gerenciador do systemd
+	AddressEnv = "HARDSHELL_SYSTEMD_BUS"
+)
+
+// Unit é o estado de uma unit carregada pelo gerenciador
+type Unit struct {
+	Name        string
+	Description string
+
+	// LoadState, ActiveState e SubState são os estados mostrados pelo systemctl list-units
+	// (ex: loaded, active, running)
+	LoadState   string
+	ActiveState string
+	SubState    string
+
+	// UnitFileState é o estado do arquivo da unit (enabled, disabled, static, masked, ...);
+	// vazio quando a unit não tem arquivo
+	UnitFileState string
+
+	Path dbus.ObjectPath
+}
+
+// Active verifica se a unit está ativa ou em transição para ativa
+func (u Unit) Active() bool {
+	switch u.ActiveState {
+	case "active", "activating", "reloading":
+		return true
+	}
+	return false
+}
+
+// Enabled verifica se o arquivo da unit está habilitado para iniciar no boot
+func (u Unit) Enabled() bool {
+	return strings.HasPrefix(u.UnitFileState, "enabled")
+}
+
+// State descreve o estado da unit como o systemctl (ex: loaded active running, enabled)
+func (u Unit) State() string {
+	state := fmt.Sprintf("%s %s %s", u.LoadState, u.ActiveState, u.SubState)
+	if u.UnitFileState != "" {
+		state += ", " + u.UnitFileState
+	}
+	return state
+}
+
+// Change é uma alteração feita pelo systemd nos arquivos de units (ex: symlink, unlink)
+type Change struct {
+	Type        string
+	Filename    string
+	Destination string
+}
+
+// String descreve a alteração como o systemctl
+func (c Change) String() string {
+	switch c.Type {
+	case "symlink":
+		return fmt.Sprintf("Criado link %s -> %s", c.Filename, c.Destination)
+	case "unlink":
+		return fmt.Sprintf("Removido %s", c.Filename)
+	}
+	return fmt.Sprintf("%s %s %s", c.Type, c.Filename, c.Destination)
+}
+
+// Client é um cliente do gerenciador do systemd pelo D-Bus
+type Client struct {
+	conn    *dbus.Conn
+	manager dbus.BusObject
+}
+
+// Connect conecta ao gerenciador do systemd. O endereço de HARDSHELL_SYSTEMD_BUS tem
+// prioridade; sem ele, é usado o barramento do sistema e, se indisponível, o socket privado.
+func Connect() (*Client, error) {
+	if address := os.Getenv(AddressEnv); address != "" {
+		return ConnectAddress(address)
+	}
+
+	conn, err := dbus.ConnectSystemBus()
+	if err == nil {
+		return newClient(conn), nil
+	}
+
+	// O socket privado é uma conexão direta com o systemd, sem barramento, e dispensa o Hello
+	private, privateErr := dbus.Dial(privateAddress)
+	if privateErr == nil {
+		if privateErr = private.Auth(nil); privateErr == nil {
+			return newClient(private), nil
+		}
+		private.Close()
+	}
+	return nil, fmt.Errorf("erro ao conectar ao systemd pelo D-Bus: %w", err)
+}
+
+// ConnectAddress conecta ao gerenciador do systemd por um barramento D-Bus no endereço
+// informado (ex: unix:path=/tmp/bus)
+func ConnectAddress(address string) (*Client, error) {
+	conn, err := dbus.Connect(address)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao D-Bus em %s: %w", address, err)
+	}
+	return newClient(conn), nil
+}
+
+// newClient cria o cliente sobre uma conexão já autenticada
+func newClient(conn *dbus.Conn) *Client {
+	return &Client{conn: conn, manager: conn.Object(busName, managerPath)}
+}
+
+// Close encerra a conexão
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// ListUnits lista as units carregadas com os estados de carga, ativação e do arquivo.
+// Com padrões (ex: *.service), apenas as units correspondentes são retornadas.
+func (c *Client) ListUnits(patterns ...string) ([]Unit, error) {
+	// a(ssssssouso): nome, descrição, carga, ativação, subestado, seguida, objeto, job
+	var raw []struct {
+		Name        string
+		Description string
+		LoadState   string
+		ActiveState string
+		SubState    string
+		Following   string
+		Path        dbus.ObjectPath
+		JobID       uint32
+		JobType     string
+		JobPath     dbus.ObjectPath
+	}
+
+	var err error
+	if len(patterns) > 0 {
+		err = c.manager.Call(managerInterface+".ListUnitsByPatterns", 0, []string{}, patterns).Store(&raw)
+	} else {
+		err = c.manager.Call(managerInterface+".ListUnits", 0).Store(&raw)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar units: %w", err)
+	}
+
+	files, err := c.ListUnitFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	units := make([]Unit, 0, len(raw))
+	for _, r := range raw {
+		units = append(units, Unit{
+			Name:          r.Name,
+			Description:   r.Description,
+			LoadState:     r.LoadState,
+			ActiveState:   r.ActiveState,
+			SubState:      r.SubState,
+			UnitFileState: files[r.Name],
+			Path:          r.Path,
+		})
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].Name < units[j].Name })
+	return units, nil
+}
+
+// ListUnitFiles retorna o estado de cada arquivo de unit instalado, pelo nome da unit
+func (c *Client) ListUnitFiles() (map[string]string, error) {
+	var raw []struct {
+		Path  string
+		State string
+	}
+	if err := c.manager.Call(managerInterface+".ListUnitFiles", 0).Store(&raw); err != nil {
+		return nil, fmt.Errorf("erro ao listar arquivos de units: %w", err)
+	}
+
+	files := make(map[string]string, len(raw))
+	for _, r := range raw {
+		name := r.Path[strings.LastIndex(r.Path, "/")+1:]
+		files[name] = r.State
+	}
+	return files, nil
+}
+
+// SocketListen retorna os endereços em que uma unit .socket escuta (ex: [::]:23)
+func (c *Client) SocketListen(unit Unit) ([]string, error) {
+	variant, err := c.conn.Object(busName, unit.Path).GetProperty(socketInterface + ".Listen")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar os endereços de %s: %w", unit.Name, err)
+	}
+
+	var raw []struct {
+		Type    string
+		Address string
+	}
+	if err := dbus.Store([]interface{}{variant.Value()}, &raw); err != nil {
+		return nil, fmt.Errorf("resposta inesperada para os endereços de %s: %w", unit.Name, err)
+	}
+
+	var listen []string
+	for _, r := range raw {
+		listen = append(listen, r.Address)
+	}
+	return listen, nil
+}
+
+// Triggers retorna as units ativadas pela unit (ex: o serviço ativado por um socket)
+func (c *Client) Triggers(unit Unit) ([]string, error) {
+	variant, err := c.conn.Object(busName, unit.Path).GetProperty(unitInterface + ".Triggers")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar as units ativadas por %s: %w", unit.Name, err)
+	}
+
+	var triggers []string
+	if err := dbus.Store([]interface{}{variant.Value()}, &triggers); err != nil {
+		return nil, fmt.Errorf("resposta inesperada para as units ativadas por %s: %w", unit.Name, err)
+	}
+	return triggers, nil
+}
+
+// StopUnit para uma unit, substituindo jobs pendentes, como o systemctl stop
+func (c *Client) StopUnit(name string) error {
+	var job dbus.ObjectPath
+	if err := c.manager.Call(managerInterface+".StopUnit", 0, name, "replace").Store(&job); err != nil {
+		return fmt.Errorf("erro ao parar %s: %w", name, err)
+	}
+	return nil
+}
+
+// DisableUnits desabilita as units de forma persistente, como o systemctl disable
+func (c *Client) DisableUnits(names ...string) ([]Change, error) {
+	var raw []Change
+	if err := c.manager.Call(managerInterface+".DisableUnitFiles", 0, names, false).Store(&raw); err != nil {
+		return nil, fmt.Errorf("erro ao desabilitar %s: %w", strings.Join(names, ", "), err)
+	}
+	return raw, c.Reload()
+}
+
+// MaskUnits mascara as units de forma persistente, como o systemctl mask
+func (c *Client) MaskUnits(names ...string) ([]Change, error) {
+	var raw []Change
+	if err := c.manager.Call(managerInterface+".MaskUnitFiles", 0, names, false, false).Store(&raw); err != nil {
+		return nil, fmt.Errorf("erro ao mascarar %s: %w", strings.Join(names, ", "), err)
+	}
+	return raw, c.Reload()
+}
+
+// Reload recarrega a configuração do gerenciador, como o systemctl daemon-reload
+func (c *Client) Reload() error {
+	if err := c.manager.Call(managerInterface+".Reload", 0).Err; err != nil {
+		return fmt.Errorf("erro ao recarregar o systemd: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package systemd
+
+import (
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// unitRow é uma linha da resposta de ListUnits (a(ssssssouso))
+type unitRow struct {
+	Name        string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+	Following   string
+	Path        dbus.ObjectPath
+	JobID       uint32
+	JobType     string
+	JobPath     dbus.ObjectPath
+}
+
+// fileRow é uma linha da resposta de ListUnitFiles (a(ss))
+type fileRow struct {
+	Path  string
+	State string
+}
+
+// fakeManager simula o org.freedesktop.systemd1.Manager com units e arquivos fixos
+type fakeManager struct {
+	mu       sync.Mutex
+	units    []unitRow
+	files    []fileRow
+	patterns []string
+	disabled []string
+	masked   []string
+	reloads  int
+}
+
+func (m *fakeManager) ListUnits() ([]unitRow, *dbus.Error) {
+	return m.units, nil
+}
+
+func (m *fakeManager) ListUnitsByPatterns(states, patterns []string) ([]unitRow, *dbus.Error) {
+	m.mu.Lock()
+	m.patterns = patterns
+	m.mu.Unlock()
+
+	var units []unitRow
+	for _, unit := range m.units {
+		for _, pattern := range patterns {
+			if strings.HasPrefix(pattern, "*") && strings.HasSuffix(unit.Name, pattern[1:]) || unit.Name == pattern {
+				units = append(units, unit)
+				break
+			}
+		}
+	}
+	return units, nil
+}
+
+func (m *fakeManager) ListUnitFiles() ([]fileRow, *dbus.Error) {
+	return m.files, nil
+}
+
+func (m *fakeManager) DisableUnitFiles(names []string, runtime bool) ([]Change, *dbus.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var changes []Change
+	for _, name := range names {
+		m.disabled = append(m.disabled, name)
+		changes = append(changes, Change{Type: "unlink", Filename: "/etc/systemd/system/multi-user.target.wants/" + name})
+	}
+	return changes, nil
+}
+
+func (m *fakeManager) MaskUnitFiles(names []string, runtime, force bool) ([]Change, *dbus.Error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var changes []Change
+	for _, name := range names {
+		if !m.known(name) {
+			return nil, dbus.NewError("org.freedesktop.systemd1.NoSuchUnit", []interface{}{"Unit " + name + " not found."})
+		}
+		m.masked = append(m.masked, name)
+		changes = append(changes, Change{Type: "symlink", Filename: "/etc/systemd/system/" + name, Destination: "/dev/null"})
+	}
+	return changes, nil
+}
+
+func (m *fakeManager) Reload() *dbus.Error {
+	m.mu.Lock()
+	m.reloads++
+	m.mu.Unlock()
+	return nil
+}
+
+// known verifica se a unit tem arquivo instalado
+func (m *fakeManager) known(name string) bool {
+	for _, file := range m.files {
+		if strings.HasSuffix(file.Path, "/"+name) {
+			return true
+		}
+	}
+	return false
+}
+
+// peerAuth responde à autenticação ANONYMOUS de uma conexão do godbus, como um
+// barramento. Os bytes são lidos um a um para não consumir mensagens após o BEGIN.
+func peerAuth(conn net.Conn) error {
+	readLine := func() (string, error) {
+		var line []byte
+		buf := make([]byte, 1)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				return "", err
+			}
+			line = append(line, buf[0])
+			if strings.HasSuffix(string(line), "\r\n") {
+				return strings.TrimSuffix(string(line), "\r\n"), nil
+			}
+		}
+	}
+
+	if _, err := io.ReadFull(conn, make([]byte, 1)); err != nil {
+		return err
+	}
+	for _, step := range []struct{ expect, reply string }{
+		{"AUTH", "REJECTED ANONYMOUS"},
+		{"AUTH ANONYMOUS", "OK 0123456789abcdef0123456789abcdef"},
+		{"BEGIN", ""},
+	} {
+		line, err := readLine()
+		if err != nil {
+			return err
+		}
+		if line != step.expect {
+			return io.ErrUnexpectedEOF
+		}
+		if step.reply != "" {
+			if _, err := io.WriteString(conn, step.reply+"\r\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dialPeer cria uma conexão do godbus autenticada por peerAuth na outra ponta do pipe
+func dialPeer(t *testing.T) (*dbus.Conn, net.Conn) {
+	t.Helper()
+
+	local, remote := net.Pipe()
+	authErr := make(chan error, 1)
+	go func() { authErr <- peerAuth(remote) }()
+
+	conn, err := dbus.NewConn(local)
+	if err != nil {
+		t.Fatalf("NewConn: %v", err)
+	}
+	if err := conn.Auth([]dbus.Auth{dbus.AuthAnonymous()}); err != nil {
+		t.Fatalf("Auth: %v", err)
+	}
+	if err := <-authErr; err != nil {
+		t.Fatalf("autenticação do par: %v", err)
+	}
+	return conn, remote
+}
+
+// fakeClient conecta um Client ao gerenciador simulado. As duas conexões do godbus são
+// autenticadas separadamente e depois ligadas entre si, sem um dbus-daemon.
+func fakeClient(t *testing.T, manager *fakeManager) *Client {
+	t.Helper()
+
+	serverConn, serverPeer := dialPeer(t)
+	if err := serverConn.Export(manager, managerPath, managerInterface); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	clientConn, clientPeer := dialPeer(t)
+
+	go io.Copy(serverPeer, clientPeer)
+	go io.Copy(clientPeer, serverPeer)
+
+	client := newClient(clientConn)
+	t.Cleanup(func() {
+		client.Close()
+		serverConn.Close()
+	})
+	return client
+}
+
+// loadedUnit cria a linha de uma unit carregada e sem job pendente
+func loadedUnit(name, description, active, sub string) unitRow {
+	return unitRow{
+		Name:        name,
+		Description: description,
+		LoadState:   "loaded",
+		ActiveState: active,
+		SubState:    sub,
+		Path:        dbus.ObjectPath("/org/freedesktop/systemd1/unit/" + strings.NewReplacer(".", "_2e", "-", "_2d").Replace(name)),
+		JobPath:     "/",
+	}
+}
+
+func newFakeManager() *fakeManager {
+	return &fakeManager{
+		units: []unitRow{
+			loadedUnit("telnet.socket", "Telnet Server Activation Socket", "active", "listening"),
+			loadedUnit("cups.service", "CUPS Scheduler", "active", "running"),
+			loadedUnit("avahi-daemon.service", "Avahi mDNS/DNS-SD Stack", "inactive", "dead"),
+		},
+		files: []fileRow{
+			{Path: "/lib/systemd/system/telnet.socket", State: "enabled"},
+			{Path: "/lib/systemd/system/cups.service", State: "enabled-runtime"},
+			{Path: "/lib/systemd/system/avahi-daemon.service", State: "disabled"},
+		},
+	}
+}
+
+func TestListUnits(t *testing.T) {
+	manager := newFakeManager()
+	client := fakeClient(t, manager)
+
+	units, err := client.ListUnits("*.service")
+	if err != nil {
+		t.Fatalf("ListUnits: %v", err)
+	}
+	if !reflect.DeepEqual(manager.patterns, []string{"*.service"}) {
+		t.Errorf("padrões recebidos pelo gerenciador = %v", manager.patterns)
+	}
+
+	var names []string
+	for _, unit := range units {
+		names = append(names, unit.Name)
+	}
+	if want := []string{"avahi-daemon.service", "cups.service"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("units = %v, esperado %v", names, want)
+	}
+
+	avahi, cups := units[0], units[1]
+	if avahi.Active() || avahi.Enabled() {
+		t.Errorf("avahi-daemon.service deveria estar inativo e desabilitado: %s", avahi.State())
+	}
+	if !cups.Active() || !cups.Enabled() {
+		t.Errorf("cups.service deveria estar ativo e habilitado: %s", cups.State())
+	}
+	if want := "loaded active running, enabled-runtime"; cups.State() != want {
+		t.Errorf("State() = %q, esperado %q", cups.State(), want)
+	}
+
+	all, err := client.ListUnits()
+	if err != nil {
+		t.Fatalf("ListUnits sem padrões: %v", err)
+	}
+	if len(all) != 3 || all[2].Name != "telnet.socket" || all[2].UnitFileState != "enabled" {
+		t.Errorf("ListUnits sem padrões = %+v", all)
+	}
+}
+
+func TestDisableAndMaskUnits(t *testing.T) {
+	manager := newFakeManager()
+	client := fakeClient(t, manager)
+
+	changes, err := client.DisableUnits("telnet.socket")
+	if err != nil {
+		t.Fatalf("DisableUnits: %v", err)
+	}
+	if len(changes) != 1 || changes[0].String() != "Removido /etc/systemd/system/multi-user.target.wants/telnet.socket" {
+		t.Errorf("alterações de DisableUnits = %v", changes)
+	}
+
+	changes, err = client.MaskUnits("cups.service", "avahi-daemon.service")
+	if err != nil {
+		t.Fatalf("MaskUnits: %v", err)
+	}
+	if len(changes) != 2 || changes[0].String() != "Criado link /etc/systemd/system/cups.service -> /dev/null" {
+		t.Errorf("alterações de MaskUnits = %v", changes)
+	}
+
+	if !reflect.DeepEqual(manager.disabled, []string{"telnet.socket"}) {
+		t.Errorf("units desabilitadas = %v", manager.disabled)
+	}
+	if !reflect.DeepEqual(manager.masked, []string{"cups.service", "avahi-daemon.service"}) {
+		t.Errorf("units mascaradas = %v", manager.masked)
+	}
+	if manager.reloads != 2 {
+		t.Errorf("o gerenciador foi recarregado %d vezes, esperado 2", manager.reloads)
+	}
+
+	if _, err := client.MaskUnits("rsh.socket"); err == nil || !strings.Contains(err.Error(), "rsh.socket") {
+		t.Errorf("MaskUnits de uma unit inexistente deveria falhar: %v", err)
+	}
+	if manager.reloads != 2 {
+		t.Error("o gerenciador não deveria ser recarregado após uma falha")
+	}
+}
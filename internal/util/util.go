@@ -0,0 +1,38 @@
+package util
+
+import (
+	"os/exec"
+	"path/filepath"
+)
+
+// JoinMount converte um caminho do sistema analisado para o caminho real, considerando o mountPoint
+func JoinMount(mountPoint, path string) string {
+	if mountPoint == "" {
+		return path
+	}
+	return filepath.Join(mountPoint, path)
+}
+
+// ContainsString verifica se a lista contém o valor
+func ContainsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// AppendUnique acrescenta um valor à lista se ele ainda não estiver nela
+func AppendUnique(list []string, value string) []string {
+	if ContainsString(list, value) {
+		return list
+	}
+	return append(list, value)
+}
+
+// HasCommand verifica se um comando está disponível no PATH
+func HasCommand(cmd string) bool {
+	_, err := exec.LookPath(cmd)
+	return err == nil
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJoinMount(t *testing.T) {
+	tests := []struct {
+		mountPoint string
+		path       string
+		want       string
+	}{
+		{"", "/etc/ssh/sshd_config", "/etc/ssh/sshd_config"},
+		{"/mnt/image", "/etc/ssh/sshd_config", "/mnt/image/etc/ssh/sshd_config"},
+		{"/mnt/image/", "/etc/sysctl.conf", "/mnt/image/etc/sysctl.conf"},
+		{"/mnt/image", "etc/passwd", "/mnt/image/etc/passwd"},
+	}
+
+	for _, tt := range tests {
+		if got := JoinMount(tt.mountPoint, tt.path); got != tt.want {
+			t.Errorf("JoinMount(%q, %q) = %q, esperado %q", tt.mountPoint, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAppendUnique(t *testing.T) {
+	tests := []struct {
+		list  []string
+		value string
+		want  []string
+	}{
+		{nil, "ssh.service", []string{"ssh.service"}},
+		{[]string{"ssh.service"}, "ssh.service", []string{"ssh.service"}},
+		{[]string{"ssh.service"}, "sshd.service", []string{"ssh.service", "sshd.service"}},
+	}
+
+	for _, tt := range tests {
+		if got := AppendUnique(tt.list, tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("AppendUnique(%v, %q) = %v, esperado %v", tt.list, tt.value, got, tt.want)
+		}
+		if got := ContainsString(tt.want, tt.value); !got {
+			t.Errorf("ContainsString(%v, %q) = false", tt.want, tt.value)
+		}
+	}
+}